@@ -0,0 +1,40 @@
+package prefcode
+
+// AbelianImage returns the image of t, which must lie in F or its n-ary
+// analogue (see IsOrderPreserving), in Z^2 under the map read off the
+// slopes at the ends of [0,1]: with slopes n^a at 0 and n^b at 1 it is
+// (-a, a+b).  For F this is the abelianization, the pair of exponent sums
+// of x0 and of the other generators x1, x2, ... in any word for t, since
+// each xi with i > 0 maps to x1 there.  For n > 2 it is a quotient of the
+// abelianization Z^n.  Equal images are a cheap first test for equality and
+// for conjugacy, both of which they are necessary for.  For pairs outside F
+// the result means nothing.
+func (t TreePair) AbelianImage() (int, int) {
+	domain := t.domain.sortedKeys()
+	first, last := domain[0], domain[len(domain)-1]
+	a := wordLen(first) - wordLen(t.rng.LeafAtLabel(t.domain.LabelAtLeaf(first)))
+	b := wordLen(last) - wordLen(t.rng.LeafAtLabel(t.domain.LabelAtLeaf(last)))
+	return -a, a + b
+}
+
+// AbelianImageV returns the image of t in the abelianization of V over its
+// alphabet, with whether it is defined.  For an odd number n of letters
+// that is Z/2, the parity of the permutation taking the domain leaves, in
+// dictionary order, to their range leaves: expanding a leaf moves n-1 more
+// leaves in blocks of n, which keeps the parity.  For even n, V is simple
+// and there is nothing to compute, and neither for T, whose abelianization
+// over one root is trivial for any n.
+func (t TreePair) AbelianImageV() (int, bool) {
+	if 0 == len(t.domain.alphabet)%2 {
+		return 0, false
+	}
+	position := make(map[int]int, t.Size())
+	for k, leaf := range t.rng.sortedKeys() {
+		position[t.rng.LabelAtLeaf(leaf)] = k
+	}
+	perm := make(Perm, t.Size())
+	for k, leaf := range t.domain.sortedKeys() {
+		perm[k] = position[t.domain.LabelAtLeaf(leaf)]
+	}
+	return perm.Parity(), true
+}
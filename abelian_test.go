@@ -0,0 +1,81 @@
+package prefcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAbelianImage(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	x0, x1 := generatorF(t, 0), generatorF(t, 1)
+
+	t.Run("Checking the images of generators of F.", func(t *testing.T) {
+		for _, c := range []struct {
+			t    TreePair
+			want string
+		}{
+			{x0, "1 0"},
+			{x1, "0 1"},
+			{generatorF(t, 4), "0 1"},
+			{x0.Inverse(), "-1 0"},
+			{mustCompose(t, x0, x1.Inverse()), "1 -1"},
+		} {
+			a, b := c.t.AbelianImage()
+			assertCorrectMessage(t, fmt.Sprint(a, b), c.want)
+		}
+	})
+
+	t.Run("Checking images are the exponent sums of normal forms.", func(t *testing.T) {
+		g, err := NewCayleyGraph(x0, x1)
+		if nil != err {
+			t.Fatalf("NewCayleyGraph: %v", err)
+		}
+		for u := range g.Ball(3) {
+			pos, neg, err := u.NormalFormF()
+			if nil != err {
+				t.Fatalf("NormalFormF(%v): %v", u, err)
+			}
+			sums := [2]int{}
+			for ii, e := range pos {
+				sums[min(ii, 1)] += e
+			}
+			for ii, e := range neg {
+				sums[min(ii, 1)] -= e
+			}
+			a, b := u.AbelianImage()
+			assertCorrectMessage(t, fmt.Sprint(a, b), fmt.Sprint(sums[0], sums[1]))
+			c, d := mustCompose(t, u, x0).AbelianImage()
+			assertCorrectMessage(t, fmt.Sprint(c, d), fmt.Sprint(a+1, b))
+		}
+	})
+
+	t.Run("Checking the parity in V over an odd alphabet.", func(t *testing.T) {
+		d, _ := NewPrefCodeAlphaString("abc")
+		d.ExpandAt("")
+		r, _ := NewPrefCodeAlphaString("abc")
+		r.ExpandAt("")
+		r.SwapPermAtKeys("a", "b")
+		swap, err := NewTreePair(d, r)
+		if nil != err {
+			t.Fatalf("NewTreePair: %v", err)
+		}
+		parity, ok := swap.AbelianImageV()
+		assertCorrectMessage(t, fmt.Sprint(parity, ok), "1 true")
+		// expanding a leaf keeps the parity.
+		expanded, _ := swap.Expand("c")
+		parity, ok = expanded.AbelianImageV()
+		assertCorrectMessage(t, fmt.Sprint(parity, ok), "1 true")
+		parity, ok = mustCompose(t, swap, expanded).AbelianImageV()
+		assertCorrectMessage(t, fmt.Sprint(parity, ok), "0 true")
+
+		parity, ok = x0.AbelianImageV()
+		assertCorrectMessage(t, fmt.Sprint(parity, ok), "0 false")
+	})
+}
@@ -0,0 +1,123 @@
+package prefcode
+
+import (
+	"errors"
+	"strconv"
+)
+
+// RelabelAlphabet returns the image of p under the letter substitution
+// mapping, which must be a bijection from the alphabet of p onto an alphabet
+// not containing 𝛆.  Each leaf keeps its label.  An ordered code (see
+// order.go) stays ordered, the images of its letters in the same order; a
+// code in rune order is in the rune order of the new letters, which may
+// differ from the old.
+func (p *prefixCode) RelabelAlphabet(mapping map[rune]rune) (PrefCode, error) {
+	return relabelAlphabet(p, p.IsOrdered(), mapping)
+}
+
+func (c *CompactPrefCode) RelabelAlphabet(mapping map[rune]rune) (PrefCode, error) {
+	return relabelAlphabet(c, false, mapping)
+}
+
+func relabelAlphabet(pc PrefCode, ordered bool, mapping map[rune]rune) (PrefCode, error) {
+	alpha := pc.Alphabet()
+	if len(mapping) != len(alpha) {
+		return nil, errors.New("Mapping has " + strconv.Itoa(len(mapping)) + " letters, the alphabet " + strconv.Itoa(len(alpha)))
+	}
+	image := make([]rune, len(alpha))
+	seen := make(map[rune]bool, len(alpha))
+	for ii, r := range alpha {
+		s, ok := mapping[r]
+		if !ok {
+			return nil, errors.New("Mapping does not send letter " + string(r))
+		}
+		if seen[s] {
+			return nil, errors.New("Mapping sends two letters to " + string(s))
+		}
+		seen[s] = true
+		image[ii] = s
+	}
+	var c *prefixCode
+	var err error
+	if ordered {
+		c, err = NewPrefCodeOrdered(image)
+	} else {
+		c, err = NewPrefCodeAlphaRunes(MakeAlphabet(string(image)))
+	}
+	if nil != err {
+		return nil, err
+	}
+	respell(c, pc, mapping)
+	return c, nil
+}
+
+// respell makes c, a code over the images of the letters of pc, the image of
+// pc under mapping, each leaf keeping its label.
+func respell(c *prefixCode, pc PrefCode, mapping map[rune]rune) {
+	c.code = make(map[string]int, pc.Size())
+	for leaf, ii := range pc.Code() {
+		if EmptyString == leaf {
+			c.code[leaf] = ii
+			continue
+		}
+		word := []rune(leaf)
+		for jj, r := range word {
+			word[jj] = mapping[r]
+		}
+		c.code[string(word)] = ii
+	}
+	c.reindex()
+}
+
+// EmbedInAlphabet returns p read over bigger, an alphabet containing that of
+// p, completed minimally: every caret of p gets a leaf child for each new
+// letter.  The leaves of p keep their labels and the new leaves are labelled
+// from Size() on, in dictionary order.  This is the embedding of the codes,
+// and with them of the Higman–Thompson groups, of a smaller alphabet in a
+// larger.  An ordered code gives a code ordered as bigger lists its letters.
+func (p *prefixCode) EmbedInAlphabet(bigger []rune) (PrefCode, error) {
+	return embedInAlphabet(p, p.IsOrdered(), bigger)
+}
+
+func (c *CompactPrefCode) EmbedInAlphabet(bigger []rune) (PrefCode, error) {
+	return embedInAlphabet(c, false, bigger)
+}
+
+func embedInAlphabet(pc PrefCode, ordered bool, bigger []rune) (PrefCode, error) {
+	var c *prefixCode
+	var err error
+	if ordered {
+		c, err = NewPrefCodeOrdered(bigger)
+	} else {
+		c, err = NewPrefCodeAlphaRunes(MakeAlphabet(string(bigger)))
+	}
+	if nil != err {
+		return nil, err
+	}
+	in := rankLetters(c.alphabet)
+	old := make(map[rune]bool, len(bigger))
+	for _, r := range pc.Alphabet() {
+		if _, ok := in[r]; !ok {
+			return nil, errors.New("Letter " + string(r) + " is not in the bigger alphabet")
+		}
+		old[r] = true
+	}
+	c.code = make(map[string]int, pc.Size())
+	for leaf, ii := range pc.Code() {
+		c.code[leaf] = ii
+	}
+	var added []string
+	for caret := range internalNodes(pc) {
+		for _, r := range c.alphabet {
+			if !old[r] {
+				added = append(added, caret+string(r))
+			}
+		}
+	}
+	c.sortWords(added)
+	for _, leaf := range added {
+		c.code[leaf] = len(c.code)
+	}
+	c.reindex()
+	return c, nil
+}
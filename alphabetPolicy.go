@@ -0,0 +1,108 @@
+package prefcode
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// AlphabetPolicy says how NewPrefCodeWithPolicy checks an alphabet.  The zero
+// policy is that of NewPrefCodeAlphaRunes with a MakeAlphabet alphabet:
+// repeats are dropped, control characters allowed and 𝛆 forbidden.
+//
+// Every code names the empty word EmptyString, so 𝛆 cannot be a letter of it:
+// the word of that one letter could not be told from the root.  EmptyWord
+// names the empty word otherwise for the code made, in String, ExpandAt,
+// ReduceAt, LabelAtLeaf and the like, and 𝛆 is then a letter as good as any.
+type AlphabetPolicy struct {
+	// RejectRepeats makes a repeated letter an error, rather than dropping
+	// the copies.
+	RejectRepeats bool
+	// DenyControl forbids the control characters (see unicode.IsControl)
+	// as letters, except those in AllowControl.
+	DenyControl  bool
+	AllowControl []rune
+	// Ordered keeps the letters in the order given, see NewPrefCodeOrdered.
+	Ordered bool
+	// EmptyWord, if not "", is the name of the empty word in place of
+	// EmptyString.  It must not be a word over the alphabet, so must use
+	// some other letter, say "∅".
+	EmptyWord string
+}
+
+// NewPrefCodeWithPolicy returns the trivial code over alpha, checked and
+// tidied as policy says.
+func NewPrefCodeWithPolicy(alpha []rune, policy AlphabetPolicy) (*prefixCode, error) {
+	letters, err := policy.Check(alpha)
+	if nil != err {
+		return nil, err
+	}
+	if 0 == len(letters) {
+		return nil, errors.New("Empty Alphabet forbidden")
+	}
+	p := &prefixCode{alphabet: letters}
+	if policy.Ordered {
+		p.rank = rankLetters(p.alphabet)
+	} else {
+		p.alphabet = MakeAlphabet(string(letters))
+	}
+	if EmptyString != policy.EmptyWord {
+		p.empty = policy.EmptyWord
+	}
+	p.code = map[string]int{p.emptyWord(): 0}
+	p.reindex()
+	return p, nil
+}
+
+// Check returns alpha with repeats dropped, keeping the order of first
+// appearance, or the first breach of policy.
+func (policy AlphabetPolicy) Check(alpha []rune) ([]rune, error) {
+	renamed := "" != policy.EmptyWord && EmptyString != policy.EmptyWord
+	allowed := make(map[rune]bool, len(policy.AllowControl))
+	for _, r := range policy.AllowControl {
+		allowed[r] = true
+	}
+	seen := make(map[rune]bool, len(alpha))
+	letters := make([]rune, 0, len(alpha))
+	for _, r := range alpha {
+		if EmptyString == string(r) && !renamed {
+			return nil, errors.New("Forbidden character `𝛆` in alphabet")
+		}
+		if seen[r] {
+			if policy.RejectRepeats {
+				return nil, errors.New("Repeated letter " + string(r) + " in alphabet")
+			}
+			continue
+		}
+		if policy.DenyControl && unicode.IsControl(r) && !allowed[r] {
+			return nil, fmt.Errorf("Control character %U in alphabet", r)
+		}
+		seen[r] = true
+		letters = append(letters, r)
+	}
+	if renamed {
+		over := true
+		for _, r := range policy.EmptyWord {
+			over = over && seen[r]
+		}
+		if over {
+			return nil, errors.New("Empty word " + strconv.Quote(policy.EmptyWord) + " is a word over the alphabet")
+		}
+	}
+	return letters, nil
+}
+
+// emptyWord returns the name of the empty word in p.
+func (p *prefixCode) emptyWord() string {
+	if "" == p.empty {
+		return EmptyString
+	}
+	return p.empty
+}
+
+// isEmptyWord reports whether word is the empty word, written "" or as p
+// names it.
+func (p *prefixCode) isEmptyWord(word string) bool {
+	return "" == word || p.emptyWord() == word
+}
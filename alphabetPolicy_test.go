@@ -0,0 +1,87 @@
+package prefcode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAlphabetPolicy(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking the zero policy.", func(t *testing.T) {
+		pc, err := NewPrefCodeWithPolicy([]rune("b\tab"), AlphabetPolicy{})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeWithPolicy() in test checking the zero policy.")
+		}
+		assertCorrectMessage(t, string(pc.Alphabet()), "\tab")
+		_, err = NewPrefCodeWithPolicy([]rune("a"+EmptyString), AlphabetPolicy{})
+		assertCorrectMessage(t, fmt.Sprint(err), "Forbidden character `𝛆` in alphabet")
+	})
+
+	t.Run("Checking repeats and control characters.", func(t *testing.T) {
+		_, err := NewPrefCodeWithPolicy([]rune("bab"), AlphabetPolicy{RejectRepeats: true})
+		assertCorrectMessage(t, fmt.Sprint(err), "Repeated letter b in alphabet")
+
+		policy := AlphabetPolicy{DenyControl: true, AllowControl: []rune("\n")}
+		_, err = NewPrefCodeWithPolicy([]rune("a\t"), policy)
+		assertCorrectMessage(t, fmt.Sprint(err), "Control character U+0009 in alphabet")
+		pc, _ := NewPrefCodeWithPolicy([]rune("a\n"), policy)
+		assertCorrectMessage(t, string(pc.Alphabet()), "\na")
+	})
+
+	t.Run("Checking order.", func(t *testing.T) {
+		pc, err := NewPrefCodeWithPolicy([]rune("zea"), AlphabetPolicy{Ordered: true})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeWithPolicy() in test checking order.")
+		}
+		assertCorrectMessage(t, string(pc.Alphabet()), "zea")
+		pc.ExpandAt("e")
+		assertCorrectMessage(t, pc.String(), "[z 0], [ez 1], [ee 2], [ea 3], [a 4]")
+
+		// 𝛆 stays forbidden while it names the empty word.
+		_, err = (AlphabetPolicy{Ordered: true, RejectRepeats: true}).Check([]rune("z" + EmptyString + "a"))
+		assertCorrectMessage(t, fmt.Sprint(err), "Forbidden character `𝛆` in alphabet")
+		_, err = (AlphabetPolicy{EmptyWord: EmptyString}).Check([]rune("z" + EmptyString + "a"))
+		assertCorrectMessage(t, fmt.Sprint(err), "Forbidden character `𝛆` in alphabet")
+	})
+
+	t.Run("Checking a code naming the empty word otherwise.", func(t *testing.T) {
+		policy := AlphabetPolicy{EmptyWord: "∅"}
+		pc, err := NewPrefCodeWithPolicy([]rune("0"+EmptyString), policy)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeWithPolicy() in test checking the empty word.")
+		}
+		assertCorrectMessage(t, string(pc.Alphabet()), "0"+EmptyString)
+		assertCorrectMessage(t, pc.String(), "[∅ 0]")
+		assertCorrectMessage(t, fmt.Sprint(pc.LabelAtLeaf("∅"), pc.LabelAtLeaf(EmptyString)), "0 -1")
+
+		// 𝛆 is now the word of one letter, not the root.
+		assertCorrectMessage(t, strconv.FormatBool(pc.ExpandAt(EmptyString)), "true")
+		assertCorrectMessage(t, pc.String(), "[0 0], [𝛆0 1], [𝛆𝛆 2]")
+		assertCorrectMessage(t, fmt.Sprint(pc.LabelAtLeaf("𝛆𝛆"), pc.LabelAtLeaf("∅")), "2 -1")
+		assertCorrectMessage(t, strings.Join(pc.ExposedCarets(), ","), EmptyString)
+		assertCorrectMessage(t, fmt.Sprint(pc.IsInternal(EmptyString), pc.IsInternal("∅"), pc.IsLeaf(EmptyString)), "true true false")
+		assertCorrectMessage(t, fmt.Sprint(pc.CheckInvariants()), "<nil>")
+
+		assertCorrectMessage(t, strconv.FormatBool(pc.ReduceAt(EmptyString)), "true")
+		assertCorrectMessage(t, pc.String(), "[0 0], [𝛆 1]")
+		assertCorrectMessage(t, strconv.Itoa(pc.LabelAtLeaf(EmptyString)), "1")
+		c := pc.clone()
+		assertCorrectMessage(t, strconv.FormatBool(c.ReduceAt("∅")), "true")
+		assertCorrectMessage(t, c.String(), "[∅ 0]")
+		assertCorrectMessage(t, fmt.Sprint(c.CheckInvariants()), "<nil>")
+		assertCorrectMessage(t, strconv.FormatBool(c.ExpandAt("∅")), "true")
+		assertCorrectMessage(t, c.String(), "[0 0], [𝛆 1]")
+
+		_, err = NewPrefCodeWithPolicy([]rune("0"+EmptyString), AlphabetPolicy{EmptyWord: "0" + EmptyString})
+		assertCorrectMessage(t, fmt.Sprint(err), `Empty word "0𝛆" is a word over the alphabet`)
+	})
+}
@@ -0,0 +1,71 @@
+package prefcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAlphabet(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking RelabelAlphabet.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		for _, code := range []PrefCode{pc, compact} {
+			code.ExpandAt("1")
+			image, err := code.RelabelAlphabet(map[rune]rune{'0': 'b', '1': 'a'})
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "RelabelAlphabet() in test checking RelabelAlphabet.")
+			}
+			assertCorrectMessage(t, string(image.Alphabet()), "ab")
+			assertCorrectMessage(t, image.String(), "[aa 2], [ab 1], [b 0]")
+
+			_, err = code.RelabelAlphabet(map[rune]rune{'0': 'a'})
+			assertCorrectMessage(t, fmt.Sprint(err), "Mapping has 1 letters, the alphabet 2")
+			_, err = code.RelabelAlphabet(map[rune]rune{'0': 'a', '2': 'b'})
+			assertCorrectMessage(t, fmt.Sprint(err), "Mapping does not send letter 1")
+			_, err = code.RelabelAlphabet(map[rune]rune{'0': 'a', '1': 'a'})
+			assertCorrectMessage(t, fmt.Sprint(err), "Mapping sends two letters to a")
+			_, err = code.RelabelAlphabet(map[rune]rune{'0': 'a', '1': []rune(EmptyString)[0]})
+			assertCorrectMessage(t, fmt.Sprint(err), "Forbidden character `𝛆` in alphabet")
+		}
+
+		ordered, _ := NewPrefCodeOrdered([]rune("01"))
+		ordered.ExpandAt("1")
+		image, _ := ordered.RelabelAlphabet(map[rune]rune{'0': 'b', '1': 'a'})
+		assertCorrectMessage(t, string(image.Alphabet()), "ba")
+		assertCorrectMessage(t, image.String(), "[b 0], [ab 1], [aa 2]")
+	})
+
+	t.Run("Checking EmbedInAlphabet.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		for _, code := range []PrefCode{pc, compact} {
+			wide, err := code.EmbedInAlphabet([]rune("012"))
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "EmbedInAlphabet() in test checking EmbedInAlphabet.")
+			}
+			assertCorrectMessage(t, wide.String(), "[𝛆 0]")
+
+			code.ExpandAt("1")
+			code.SwapPermAtKeys("0", "11")
+			wide, _ = code.EmbedInAlphabet([]rune("2013"))
+			assertCorrectMessage(t, string(wide.Alphabet()), "0123")
+			assertCorrectMessage(t, wide.String(), "[0 2], [10 1], [11 0], [12 3], [13 4], [2 5], [3 6]")
+
+			_, err = code.EmbedInAlphabet([]rune("02"))
+			assertCorrectMessage(t, fmt.Sprint(err), "Letter 1 is not in the bigger alphabet")
+		}
+
+		ordered, _ := NewPrefCodeOrdered([]rune("10"))
+		ordered.ExpandAt("")
+		wide, _ := ordered.EmbedInAlphabet([]rune("a10"))
+		assertCorrectMessage(t, wide.String(), "[a 2], [1 0], [0 1]")
+	})
+}
@@ -0,0 +1,244 @@
+package prefcode
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SwapLabels swaps the labels of each pair of leaves in turn.  Every leaf is
+// checked before anything is swapped, so on error p is unchanged.
+func (p *prefixCode) SwapLabels(pairs [][2]string) error {
+	return swapLabels(p, pairs)
+}
+
+// CycleLabels moves the label of each leaf to the next, and the label of the
+// last leaf to the first.  The leaves must be distinct; every leaf is checked
+// before anything moves, so on error p is unchanged.
+func (p *prefixCode) CycleLabels(leaves []string) error {
+	return cycleLabels(p, leaves)
+}
+
+func (c *CompactPrefCode) SwapLabels(pairs [][2]string) error {
+	return swapLabels(c, pairs)
+}
+
+func (c *CompactPrefCode) CycleLabels(leaves []string) error {
+	return cycleLabels(c, leaves)
+}
+
+// swapLabels and cycleLabels do the work of SwapLabels and CycleLabels for
+// any PrefCode, by way of SwapPermAtKeys.
+func swapLabels(pc PrefCode, pairs [][2]string) error {
+	for ii, pair := range pairs {
+		for _, leaf := range pair {
+			if FAILURE == pc.LabelAtLeaf(leaf) {
+				return errors.New("Pair " + strconv.Itoa(ii) + " names " + strconv.Quote(leaf) + ", which is not a leaf")
+			}
+		}
+	}
+	for _, pair := range pairs {
+		if pair[0] != pair[1] {
+			pc.SwapPermAtKeys(pair[0], pair[1])
+		}
+	}
+	return nil
+}
+
+func cycleLabels(pc PrefCode, leaves []string) error {
+	seen := make(map[string]bool, len(leaves))
+	for _, leaf := range leaves {
+		if FAILURE == pc.LabelAtLeaf(leaf) {
+			return errors.New(strconv.Quote(leaf) + " is not a leaf")
+		}
+		if seen[leaf] {
+			return errors.New(strconv.Quote(leaf) + " appears twice in the cycle")
+		}
+		seen[leaf] = true
+	}
+	// swapping the first leaf with each of the others in turn hands each
+	// label on to the next leaf.
+	for ii := 1; ii < len(leaves); ii++ {
+		pc.SwapPermAtKeys(leaves[0], leaves[ii])
+	}
+	return nil
+}
+
+// ExpandAtAll makes every word a caret, as ExpandAt does one at a time, giving
+// the least code in which they all are.  Every word is checked first (it
+// must not be a caret already, as ExpandAt would ignore it), so on error p is
+// unchanged.  The labels come out as if the words were expanded in turn: the
+// leaves replacing a leaf take its place, in dictionary order.  They are
+// worked out in one pass rather than one shift per expansion, unless leaves
+// carry tags or weights, or labels are sparse, when ExpandAt does the work.
+func (p *prefixCode) ExpandAtAll(words []string) error {
+	if err := checkExpandable(p, words); nil != err {
+		return err
+	}
+	if nil != p.sparse || 0 < len(p.meta) || nil != p.weights || p.hooked() {
+		expandInTurn(p, words)
+		return nil
+	}
+	p.syncLabels()
+
+	// the new carets, each with the old leaf it lies at or below.
+	carets := make(map[string]string)
+	for _, w := range words {
+		w = p.path(w)
+		leaf, _ := p.prefixLeaf(w)
+		base := p.path(leaf)
+		for ii := range w {
+			if ii >= len(base) {
+				carets[w[:ii]] = leaf
+			}
+		}
+		carets[w] = leaf
+	}
+	if 0 == len(carets) {
+		return nil
+	}
+	below := make(map[string][]string)
+	for caret, leaf := range carets {
+		for _, r := range p.alphabet {
+			if _, ok := carets[caret+string(r)]; !ok {
+				below[leaf] = append(below[leaf], caret+string(r))
+			}
+		}
+	}
+
+	code := make(map[string]int, len(p.code)+len(carets)*(len(p.alphabet)-1))
+	for _, leaf := range p.leaves {
+		leaves, ok := below[leaf]
+		if !ok {
+			code[leaf] = len(code)
+			continue
+		}
+		p.sortWords(leaves)
+		for _, l := range leaves {
+			code[l] = len(code)
+		}
+	}
+	p.code = code
+	p.reindex()
+	return nil
+}
+
+// ReduceAtAll collapses the subtree at every word, as ReduceAt does one at a
+// time.  Every word is checked first (it must be a leaf or a caret, not below
+// the code), so on error p is unchanged.  Words below others in the list are
+// swallowed by them.  The labels come out as if the words were reduced in
+// turn, each new leaf taking the least label below it and the others closing
+// ranks, worked out in one pass unless leaves carry tags or weights, or
+// labels are sparse.
+func (p *prefixCode) ReduceAtAll(words []string) error {
+	if err := checkReducible(p, words); nil != err {
+		return err
+	}
+	tops := cylinderRoots(words)
+	if 0 < len(tops) && "" == tops[0] {
+		p.ReduceAt("")
+		return nil
+	}
+	if nil != p.sparse || 0 < len(p.meta) || nil != p.weights || p.hooked() {
+		for _, w := range tops {
+			p.ReduceAt(w)
+		}
+		return nil
+	}
+	p.syncLabels()
+
+	// each leaf left is keyed by the least old label it stands for.
+	key := make(map[string]int, len(p.code))
+	for leaf, label := range p.code {
+		key[leaf] = label
+	}
+	for _, w := range tops {
+		least := len(p.code)
+		for _, leaf := range p.leavesBelow(p.findNode(w), w) {
+			least = min(least, key[leaf])
+			delete(key, leaf)
+		}
+		key[w] = least
+	}
+	leaves := make([]string, 0, len(key))
+	for leaf := range key {
+		leaves = append(leaves, leaf)
+	}
+	sort.Slice(leaves, func(i, j int) bool { return key[leaves[i]] < key[leaves[j]] })
+	p.code = make(map[string]int, len(leaves))
+	for k, leaf := range leaves {
+		p.code[leaf] = k
+	}
+	p.reindex()
+	return nil
+}
+
+func (c *CompactPrefCode) ExpandAtAll(words []string) error {
+	if err := checkExpandable(c, words); nil != err {
+		return err
+	}
+	expandInTurn(c, words)
+	return nil
+}
+
+func (c *CompactPrefCode) ReduceAtAll(words []string) error {
+	if err := checkReducible(c, words); nil != err {
+		return err
+	}
+	for _, w := range cylinderRoots(words) {
+		c.ReduceAt(w)
+	}
+	return nil
+}
+
+// checkExpandable returns an error unless every word is over the alphabet of
+// pc and at or below a leaf.
+func checkExpandable(pc PrefCode, words []string) error {
+	for _, w := range words {
+		if err := checkLetters(pc, w); nil != err {
+			return err
+		}
+		if pc.IsInternal(w) {
+			return errors.New("Word " + strconv.Quote(w) + " is a caret of the code, too shallow to expand")
+		}
+	}
+	return nil
+}
+
+// checkReducible returns an error unless every word is over the alphabet of
+// pc and a leaf or caret of it.
+func checkReducible(pc PrefCode, words []string) error {
+	for _, w := range words {
+		if err := checkLetters(pc, w); nil != err {
+			return err
+		}
+		if !pc.IsLeaf(w) && !pc.IsInternal(w) {
+			return errors.New("Word " + strconv.Quote(w) + " lies below the code, too deep to reduce")
+		}
+	}
+	return nil
+}
+
+func checkLetters(pc PrefCode, w string) error {
+	if EmptyString == w {
+		return nil
+	}
+	letters := string(pc.Alphabet())
+	for _, r := range w {
+		if !strings.ContainsRune(letters, r) {
+			return errors.New("Word " + strconv.Quote(w) + " uses letter " + strconv.QuoteRune(r) + " outside the alphabet")
+		}
+	}
+	return nil
+}
+
+// expandInTurn expands at the words, shortest first, so none is made a caret
+// before its turn by a longer one.
+func expandInTurn(pc PrefCode, words []string) {
+	sorted := append([]string(nil), words...)
+	sort.SliceStable(sorted, func(i, j int) bool { return wordLen(sorted[i]) < wordLen(sorted[j]) })
+	for _, w := range sorted {
+		pc.ExpandAt(w)
+	}
+}
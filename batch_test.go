@@ -0,0 +1,141 @@
+package prefcode
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking SwapLabels.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking SwapLabels.")
+		}
+		baseCode.ExpandAt("10")
+		err = baseCode.SwapLabels([][2]string{{"0", "11"}, {"100", "101"}, {"0", "0"}})
+		assertCorrectMessage(t, strconv.FormatBool(nil == err), "true")
+		assertCorrectMessage(t, baseCode.String(), "[0 3], [100 2], [101 1], [11 0]")
+
+		err = baseCode.SwapLabels([][2]string{{"0", "11"}, {"100", "10"}})
+		assertCorrectMessage(t, strconv.FormatBool(nil == err), "false")
+		assertCorrectMessage(t, baseCode.String(), "[0 3], [100 2], [101 1], [11 0]")
+	})
+
+	t.Run("Checking CycleLabels.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking CycleLabels.")
+		}
+		baseCode.ExpandAt("10")
+		err = baseCode.CycleLabels([]string{"0", "101", "11"})
+		assertCorrectMessage(t, strconv.FormatBool(nil == err), "true")
+		assertCorrectMessage(t, baseCode.String(), "[0 3], [100 1], [101 0], [11 2]")
+
+		for _, bad := range [][]string{{"0", "1"}, {"0", "11", "0"}} {
+			err = baseCode.CycleLabels(bad)
+			assertCorrectMessage(t, strconv.FormatBool(nil == err), "false")
+		}
+		assertCorrectMessage(t, baseCode.String(), "[0 3], [100 1], [101 0], [11 2]")
+
+		cpc := NewCompactFrom(baseCode)
+		cpc.CycleLabels([]string{"11", "100"})
+		baseCode.CycleLabels([]string{"11", "100"})
+		assertCorrectMessage(t, cpc.String(), baseCode.String())
+	})
+}
+
+func TestBulk(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking ExpandAtAll.", func(t *testing.T) {
+		pc, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking ExpandAtAll.")
+		}
+		assertCorrectMessage(t, fmt.Sprint(pc.ExpandAtAll([]string{"10", EmptyString, "0"})), "<nil>")
+		assertCorrectMessage(t, pc.String(), "[00 0], [01 1], [100 2], [101 3], [11 4]")
+		pc.SwapLabels([][2]string{{"00", "11"}})
+		assertCorrectMessage(t, fmt.Sprint(pc.ExpandAtAll([]string{"111", "0011"})), "<nil>")
+		assertCorrectMessage(t, pc.String(), "[000 6], [0010 7], [00110 8], [00111 9], [01 3], [100 4], [101 5], [110 0], [1110 1], [1111 2]")
+
+		err = pc.ExpandAtAll([]string{"0101", "10"})
+		assertCorrectMessage(t, fmt.Sprint(err), `Word "10" is a caret of the code, too shallow to expand`)
+		err = pc.ExpandAtAll([]string{"0101", "12"})
+		assertCorrectMessage(t, fmt.Sprint(err), `Word "12" uses letter '2' outside the alphabet`)
+		assertCorrectMessage(t, strconv.Itoa(pc.Size()), "10")
+	})
+
+	t.Run("Checking ReduceAtAll.", func(t *testing.T) {
+		pc, _ := NewUniformCode([]rune("01"), 3)
+		pc.ApplyPerm(Perm{0: 7, 1: 6, 2: 5, 3: 4, 4: 3, 5: 2, 6: 1, 7: 0})
+		assertCorrectMessage(t, fmt.Sprint(pc.ReduceAtAll([]string{"01", "1", "11", "000"})), "<nil>")
+		assertCorrectMessage(t, pc.String(), "[000 3], [001 2], [01 1], [1 0]")
+		err := pc.ReduceAtAll([]string{"0", "11"})
+		assertCorrectMessage(t, fmt.Sprint(err), `Word "11" lies below the code, too deep to reduce`)
+		assertCorrectMessage(t, fmt.Sprint(pc.ReduceAtAll([]string{"0", ""})), "<nil>")
+		assertCorrectMessage(t, pc.String(), "[𝛆 0]")
+	})
+
+	t.Run("Checking bulk edits agree with edits in turn.", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(3))
+		for trial := 0; trial < 200; trial++ {
+			alpha := []rune([]string{"01", "abc"}[trial%2])
+			bulk, _ := NewPrefCodeAlphaRunes(alpha)
+			for ii := 0; ii < 4; ii++ {
+				keys := bulk.sortedKeys()
+				bulk.ExpandAt(keys[rnd.Intn(len(keys))])
+			}
+			bulk.ApplyPerm(Perm(func() map[int]int {
+				m := make(map[int]int)
+				for ii, jj := range rnd.Perm(bulk.Size()) {
+					m[ii] = jj
+				}
+				return m
+			}()))
+			turn := bulk.clone()
+			compact := NewCompactFrom(bulk)
+
+			var words []string
+			for _, leaf := range bulk.sortedKeys() {
+				if 0 == rnd.Intn(3) {
+					words = append(words, leaf+string(alpha[rnd.Intn(len(alpha))]))
+				}
+			}
+			bulk.ExpandAtAll(words)
+			compact.ExpandAtAll(words)
+			expandInTurn(turn, words)
+			assertCorrectMessage(t, bulk.String(), turn.String())
+			assertCorrectMessage(t, compact.String(), turn.String())
+
+			words = words[:0]
+			for _, caret := range bulk.InternalNodes() {
+				if 0 == rnd.Intn(3) && "" != caret {
+					words = append(words, caret)
+				}
+			}
+			bulk.ReduceAtAll(words)
+			compact.ReduceAtAll(words)
+			for _, w := range cylinderRoots(words) {
+				turn.ReduceAt(w)
+			}
+			assertCorrectMessage(t, bulk.String(), turn.String())
+			assertCorrectMessage(t, compact.String(), turn.String())
+		}
+	})
+}
@@ -0,0 +1,247 @@
+package prefcode
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// bitVector is a packed sequence of bits with a rank directory, giving
+// constant time rank and logarithmic time select.  Bit i lives in
+// words[i/64] at position i%64.
+type bitVector struct {
+	words []uint64
+	n     int
+	ranks []uint32 // ranks[w] is the number of ones before words[w]
+
+	// the excess index, kept when up > 0, see closeAt.
+	up        int     // the weight of a one; a zero weighs -1
+	mins      []int32 // mins[w] is the least running excess within words[w]
+	blockMins []int32 // blockMins[k] is the least within words[64k:64k+64]
+}
+
+func (b *bitVector) get(i int) bool {
+	return 0 != b.words[i>>6]&(1<<(uint(i)&63))
+}
+
+// push appends the k low bits of x, least significant first.
+func (b *bitVector) push(x uint64, k int) {
+	for k > 0 {
+		off := b.n & 63
+		if 0 == off {
+			b.words = append(b.words, 0)
+		}
+		take := 64 - off
+		if take > k {
+			take = k
+		}
+		chunk := x
+		if take < 64 {
+			chunk &= (1 << uint(take)) - 1
+		}
+		b.words[len(b.words)-1] |= chunk << uint(off)
+		b.n += take
+		k -= take
+		if take < 64 {
+			x >>= uint(take)
+		}
+	}
+}
+
+// pushRepeat appends k copies of bit.
+func (b *bitVector) pushRepeat(bit bool, k int) {
+	var x uint64
+	if bit {
+		x = ^uint64(0)
+	}
+	for ; k > 64; k -= 64 {
+		b.push(x, 64)
+	}
+	b.push(x, k)
+}
+
+// chunk returns the k <= 64 bits starting at i.
+func (b *bitVector) chunk(i, k int) uint64 {
+	w, off := i>>6, uint(i)&63
+	x := b.words[w] >> off
+	if off > 0 && w+1 < len(b.words) {
+		x |= b.words[w+1] << (64 - off)
+	}
+	if k < 64 {
+		x &= (1 << uint(k)) - 1
+	}
+	return x
+}
+
+// pushRange appends the bits [from, to) of src.
+func (b *bitVector) pushRange(src *bitVector, from, to int) {
+	for ; from+64 <= to; from += 64 {
+		b.push(src.chunk(from, 64), 64)
+	}
+	if from < to {
+		b.push(src.chunk(from, to-from), to-from)
+	}
+}
+
+// splice replaces the bits [from, to) by the bits of ins.
+func (b *bitVector) splice(from, to int, ins *bitVector) {
+	var out bitVector
+	out.words = make([]uint64, 0, (b.n-(to-from)+ins.n)/64+1)
+	out.pushRange(b, 0, from)
+	out.pushRange(ins, 0, ins.n)
+	out.pushRange(b, to, b.n)
+	out.up = b.up
+	out.buildRanks()
+	*b = out
+}
+
+func (b *bitVector) buildRanks() {
+	b.ranks = make([]uint32, len(b.words))
+	var total uint32
+	for w, x := range b.words {
+		b.ranks[w] = total
+		total += uint32(bits.OnesCount64(x))
+	}
+	if b.up > 0 {
+		b.buildExcess()
+	}
+}
+
+// The excess of a run of bits is up for each one less one for each zero.  In
+// the DFS sequence of a tree with n letters, up = n-1, a subtree is a run of
+// excess -1 whose every proper prefix has excess at least 0, so its end is
+// found by skipping words, and blocks of 64 words, whose least running excess
+// shows it does not end there.
+
+// buildExcess rebuilds the excess index, a byte at a time.
+func (b *bitVector) buildExcess() {
+	var sums, lows [256]int32
+	for x := range sums {
+		var sum int32
+		low := int32(8 * b.up)
+		for k := 0; k < 8; k++ {
+			if 0 != x&(1<<uint(k)) {
+				sum += int32(b.up)
+			} else {
+				sum--
+			}
+			low = min(low, sum)
+		}
+		sums[x], lows[x] = sum, low
+	}
+	b.mins = make([]int32, len(b.words))
+	b.blockMins = make([]int32, (len(b.words)+63)/64)
+	var blockSum int32
+	for w, x := range b.words {
+		var sum int32
+		low := int32(64 * b.up)
+		for k := 0; k < 64; k += 8 {
+			octet := x >> uint(k) & 0xff
+			low = min(low, sum+lows[octet])
+			sum += sums[octet]
+		}
+		b.mins[w] = low
+		if 0 == w&63 {
+			blockSum = 0
+			b.blockMins[w>>6] = blockSum + low
+		}
+		b.blockMins[w>>6] = min(b.blockMins[w>>6], blockSum+low)
+		blockSum += sum
+	}
+}
+
+// excess returns the excess of the words [from, to).
+func (b *bitVector) excess(from, to int) int {
+	ones := b.rank1(to<<6) - b.rank1(from<<6)
+	return b.up*ones - (64*(to-from) - ones)
+}
+
+// closeAt returns the position just past the first bit, from i on, at which
+// tally plus the running excess reaches 0, or n if there is none.  With tally
+// 1 at a node of a DFS sequence this is the end of its subtree.  Without the
+// excess index it goes a bit at a time.
+func (b *bitVector) closeAt(i, tally int) int {
+	step := func(i int) bool {
+		if b.get(i) {
+			tally += b.up
+		} else {
+			tally--
+		}
+		return 0 == tally
+	}
+	for ; i < b.n && (nil == b.mins || 0 != i&63); i++ {
+		if step(i) {
+			return i + 1
+		}
+	}
+	if i >= b.n {
+		return b.n
+	}
+	for w := i >> 6; w < len(b.words); {
+		if 0 == w&63 && tally+int(b.blockMins[w>>6]) > 0 {
+			next := min(w+64, len(b.words))
+			tally += b.excess(w, next)
+			w = next
+			continue
+		}
+		if tally+int(b.mins[w]) > 0 {
+			tally += b.excess(w, w+1)
+			w++
+			continue
+		}
+		// the end is in this word.
+		for i = w << 6; i < b.n; i++ {
+			if step(i) {
+				return i + 1
+			}
+		}
+		break
+	}
+	return b.n
+}
+
+// rank1 returns the number of ones before position i.
+func (b *bitVector) rank1(i int) int {
+	w, off := i>>6, uint(i)&63
+	if 0 == len(b.words) {
+		return 0
+	}
+	if w == len(b.words) {
+		return int(b.ranks[w-1]) + bits.OnesCount64(b.words[w-1])
+	}
+	return int(b.ranks[w]) + bits.OnesCount64(b.words[w]&((1<<off)-1))
+}
+
+// rank0 returns the number of zeros before position i.
+func (b *bitVector) rank0(i int) int {
+	return i - b.rank1(i)
+}
+
+// select0 returns the position of the zero of rank k (counting from 0), or
+// FAILURE if there are not that many zeros.
+func (b *bitVector) select0(k int) int {
+	// the last word with fewer than k+1 zeros before it holds our zero.
+	w := sort.Search(len(b.words), func(w int) bool {
+		return w*64-int(b.ranks[w]) > k
+	}) - 1
+	if w < 0 {
+		return FAILURE
+	}
+	k -= w*64 - int(b.ranks[w])
+	for i := w * 64; i < b.n && i < (w+1)*64; i++ {
+		if !b.get(i) {
+			if 0 == k {
+				return i
+			}
+			k--
+		}
+	}
+	return FAILURE
+}
+
+// chunkOnes returns the number of ones among the k bits from i on.
+func (b *bitVector) chunkOnes(i, k int) int {
+	if i+k > b.n {
+		k = b.n - i
+	}
+	return b.rank1(i+k) - b.rank1(i)
+}
@@ -0,0 +1,105 @@
+package prefcode
+
+import "errors"
+
+// Builder plans a prefix code as a set of expansions and builds it in one
+// pass, so importing a large code does not churn the maps and the rope the
+// way calling ExpandAt in a loop does.  The labels of the result are those
+// the same expansions would give on a trivial code: dictionary order.
+type Builder struct {
+	alphabet []rune
+	letters  map[rune]bool
+	carets   map[string]bool
+}
+
+// NewBuilder returns a Builder for codes over alpha, planning the trivial code.
+func NewBuilder(alpha []rune) (*Builder, error) {
+	if _, err := NewPrefCodeAlphaRunes(alpha); nil != err {
+		return nil, err
+	}
+	b := &Builder{
+		alphabet: make([]rune, len(alpha)),
+		letters:  make(map[rune]bool, len(alpha)),
+		carets:   make(map[string]bool),
+	}
+	copy(b.alphabet, alpha)
+	for _, r := range alpha {
+		b.letters[r] = true
+	}
+	return b, nil
+}
+
+// ExpandAt plans an expansion making s (and so every prefix of s) a caret.
+func (b *Builder) ExpandAt(s string) error {
+	if EmptyString == s {
+		s = ""
+	}
+	for _, r := range s {
+		if !b.letters[r] {
+			return errors.New("Word has a letter outside the alphabet")
+		}
+	}
+	// the prefixes of a planned caret are planned already.
+	for word := s; !b.carets[word]; word = trimLastChar(word) {
+		b.carets[word] = true
+		if "" == word {
+			break
+		}
+	}
+	return nil
+}
+
+// Size returns the number of leaves of the code planned so far.
+func (b *Builder) Size() int {
+	return len(b.carets)*(len(b.alphabet)-1) + 1
+}
+
+// Build returns the planned code.  The Builder may carry on planning.
+func (b *Builder) Build() PrefCode {
+	size := b.Size()
+	pc := &prefixCode{
+		alphabet: make([]rune, len(b.alphabet)),
+		code:     make(map[string]int, size),
+		trie:     &trieNode{},
+		leaves:   make([]string, 0, size),
+		exposed:  make(map[string]struct{}),
+	}
+	copy(pc.alphabet, b.alphabet)
+	if 0 == len(b.carets) {
+		pc.code[EmptyString] = 0
+		pc.leaves = append(pc.leaves, EmptyString)
+		pc.trie.leaf = true
+	} else {
+		b.grow(pc, pc.trie, "", MakeAlphabet(string(b.alphabet)))
+	}
+	pc.ropeFrom(pc.leaves)
+	return pc
+}
+
+// grow fills in the trie below node, at word, notes the exposed carets and
+// appends the leaves to the code in dictionary order, which is label order.
+// It keeps its own stack rather than recursing, as codes may be very deep.
+func (b *Builder) grow(pc *prefixCode, node *trieNode, word string, letters []rune) {
+	stack := []trieEntry{{node, word}}
+	for 0 < len(stack) {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !b.carets[e.word] {
+			e.t.leaf = true
+			pc.code[e.word] = len(pc.leaves)
+			pc.leaves = append(pc.leaves, e.word)
+			continue
+		}
+		e.t.children = make(map[rune]*trieNode, len(letters))
+		exposed := true
+		for ii := len(letters) - 1; ii >= 0; ii-- {
+			child := &trieNode{}
+			e.t.children[letters[ii]] = child
+			stack = append(stack, trieEntry{child, e.word + string(letters[ii])})
+			exposed = exposed && !b.carets[e.word+string(letters[ii])]
+		}
+		if exposed {
+			pc.exposed[e.word] = struct{}{}
+		}
+	}
+}
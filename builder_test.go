@@ -0,0 +1,85 @@
+package prefcode
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking a small build.", func(t *testing.T) {
+		b, err := NewBuilder([]rune("01"))
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewBuilder() in test checking a small build.")
+		}
+		assertCorrectMessage(t, b.Build().String(), "[𝛆 0]")
+		b.ExpandAt("10")
+		b.ExpandAt(EmptyString)
+		assertCorrectMessage(t, strconv.Itoa(b.Size()), "4")
+		assertCorrectMessage(t, b.Build().String(), "[0 0], [100 1], [101 2], [11 3]")
+
+		err = b.ExpandAt("102")
+		assertCorrectMessage(t, strconv.FormatBool(nil == err), "false")
+		assertCorrectMessage(t, strconv.Itoa(b.Size()), "4")
+	})
+
+	// a build must give the code the expansions give one at a time.
+	t.Run("Checking Builder against ExpandAt.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(13))
+		for _, alpha := range []string{"01", "abc"} {
+			b, _ := NewBuilder([]rune(alpha))
+			pc, _ := NewPrefCodeAlphaString(alpha)
+			for ii := 0; ii < 300; ii++ {
+				var word strings.Builder
+				for jj := rng.Intn(12); jj > 0; jj-- {
+					word.WriteByte(alpha[rng.Intn(len(alpha))])
+				}
+				b.ExpandAt(word.String())
+				pc.ExpandAt(word.String())
+			}
+			built := b.Build()
+			assertCorrectMessage(t, built.String(), pc.String())
+			assertCorrectMessage(t, strings.Join(built.ExposedCarets(), " "), strings.Join(pc.ExposedCarets(), " "))
+			assertCorrectMessage(t, built.LeafAtLabel(7), pc.LeafAtLabel(7))
+			assertCorrectMessage(t, strconv.Itoa(b.Size()), strconv.Itoa(pc.Size()))
+		}
+	})
+}
+
+// BenchmarkBuilder imports a code of a few thousand random carets.
+func BenchmarkBuilder(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	words := make([]string, 3000)
+	for ii := range words {
+		for jj := rng.Intn(20); jj > 0; jj-- {
+			words[ii] += strconv.Itoa(rng.Intn(2))
+		}
+	}
+	b.Run("ExpandAt", func(b *testing.B) {
+		for ii := 0; ii < b.N; ii++ {
+			pc, _ := NewPrefCode()
+			for _, w := range words {
+				pc.ExpandAt(w)
+			}
+			pc.Code()
+		}
+	})
+	b.Run("Builder", func(b *testing.B) {
+		for ii := 0; ii < b.N; ii++ {
+			bld, _ := NewBuilder([]rune("01"))
+			for _, w := range words {
+				bld.ExpandAt(w)
+			}
+			bld.Build().Code()
+		}
+	})
+}
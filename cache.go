@@ -0,0 +1,62 @@
+package prefcode
+
+// derived holds data worked out from the code, which read-heavy callers
+// would otherwise have recomputed (and re-sorted) on every call.  Mutators
+// drop it: changes to the tree drop everything, changes to labels only what
+// depends on labels.
+type derived struct {
+	keys   []string // the leaves in dictionary order
+	labels []int    // the labels of keys, in the same order
+	carets []string // the exposed carets in dictionary order
+	str    string   // String, valid if hasStr
+	hasStr bool
+}
+
+// treeChanged drops all derived data.
+func (p *prefixCode) treeChanged() {
+	p.cache = derived{}
+}
+
+// labelsChanged drops the derived data which depends on labels.
+func (p *prefixCode) labelsChanged() {
+	p.cache.labels = nil
+	p.cache.str, p.cache.hasStr = "", false
+}
+
+// sortedKeys returns the leaves of p in dictionary order.  The slice is
+// shared with the cache, so it must not be changed.
+func (p *prefixCode) sortedKeys() []string {
+	if nil == p.cache.keys {
+		keys := make([]string, 0, len(p.code))
+		for k := range p.code {
+			keys = append(keys, k)
+		}
+		p.sortWords(keys)
+		p.cache.keys = keys
+	}
+	return p.cache.keys
+}
+
+// sortedLabels returns the labels of the leaves in dictionary order.  The
+// slice is shared with the cache, so it must not be changed.
+func (p *prefixCode) sortedLabels() []int {
+	if nil == p.cache.labels {
+		p.syncLabels()
+		keys := p.sortedKeys()
+		labels := make([]int, len(keys))
+		for ii, k := range keys {
+			labels[ii] = p.code[k]
+		}
+		p.cache.labels = labels
+	}
+	return p.cache.labels
+}
+
+// warm works out all the derived data, and syncs the labels, so that reads
+// until the next change only read and may run side by side.
+func (p *prefixCode) warm() {
+	p.syncLabels()
+	p.sortedLabels()
+	p.ExposedCarets()
+	_ = p.String()
+}
@@ -0,0 +1,77 @@
+package prefcode
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	// every mutator must drop what it makes stale.
+	t.Run("Checking cached data after mutations.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking cached data.")
+		}
+		baseCode.ExpandAt("10")
+		assertCorrectMessage(t, baseCode.String(), "[0 0], [100 1], [101 2], [11 3]")
+		assertCorrectMessage(t, PermToString(baseCode.Permutation()), "[0 0], [1 1], [2 2], [3 3]")
+		assertCorrectMessage(t, strings.Join(baseCode.ExposedCarets(), " "), "10")
+
+		baseCode.SwapPermAtKeys("0", "11")
+		assertCorrectMessage(t, baseCode.String(), "[0 3], [100 1], [101 2], [11 0]")
+		assertCorrectMessage(t, PermToString(baseCode.Permutation()), "[0 3], [1 1], [2 2], [3 0]")
+
+		baseCode.ApplyPerm(map[int]int{0: 1, 1: 0, 2: 2, 3: 3})
+		assertCorrectMessage(t, baseCode.String(), "[0 3], [100 0], [101 2], [11 1]")
+
+		baseCode.ExpandAt("0")
+		assertCorrectMessage(t, baseCode.String(), "[00 3], [01 4], [100 0], [101 2], [11 1]")
+		assertCorrectMessage(t, strings.Join(baseCode.ExposedCarets(), " "), "0 10")
+
+		baseCode.ReduceAt("1")
+		assertCorrectMessage(t, baseCode.String(), "[00 1], [01 2], [1 0]")
+		assertCorrectMessage(t, strings.Join(baseCode.ExposedCarets(), " "), "0")
+
+		baseCode.Code()["1"] = 7
+		assertCorrectMessage(t, baseCode.String(), "[00 1], [01 2], [1 0]")
+
+		baseCode.SetCode(map[string]int{"0": 1, "1": 0})
+		assertCorrectMessage(t, baseCode.String(), "[0 1], [1 0]")
+		assertCorrectMessage(t, strconv.Itoa(len(baseCode.Permutation())), "2")
+	})
+
+	t.Run("Checking callers cannot change the cache.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking cache copies.")
+		}
+		baseCode.ExpandAt("1")
+		baseCode.ExposedCarets()[0] = "x"
+		baseCode.Permutation()[0] = 5
+		assertCorrectMessage(t, strings.Join(baseCode.ExposedCarets(), " "), "1")
+		assertCorrectMessage(t, PermToString(baseCode.Permutation()), "[0 0], [1 1], [2 2]")
+	})
+}
+
+// BenchmarkReadHeavy reads a code of a few thousand leaves many times over
+// between rare mutations.
+func BenchmarkReadHeavy(b *testing.B) {
+	pc, _ := NewUniformCode([]rune("01"), 12)
+	for ii := 0; ii < b.N; ii++ {
+		for jj := 0; jj < 20; jj++ {
+			_ = pc.String()
+			pc.Permutation()
+			pc.ExposedCarets()
+		}
+		pc.SwapPermAtKeys("000000000000", "111111111111")
+	}
+}
@@ -0,0 +1,93 @@
+package prefcode
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+)
+
+// NewCodeFromLengths returns the canonical complete prefix code over alpha in
+// which symbol ii has a codeword of length lengths[ii] and carries label ii.
+// Canonical means, as in DEFLATE, that codewords are handed out in order of
+// (length, symbol index) as consecutive n-ary numbers, with the letters of
+// alpha taken as digits in natural rune order.  The lengths must satisfy the
+// Kraft equality, so that the code is complete.
+func NewCodeFromLengths(alpha []rune, lengths []int) (PrefCode, error) {
+	prefc, err := NewPrefCodeAlphaRunes(alpha)
+	if err != nil {
+		return prefc, err
+	}
+	if 0 == len(lengths) {
+		return prefc, errors.New("No codeword lengths given")
+	}
+	if err := checkKraftEquality(len(alpha), lengths); err != nil {
+		return prefc, err
+	}
+	if 1 == len(lengths) && 0 == lengths[0] {
+		return prefc, nil
+	}
+
+	digits := make([]rune, len(alpha))
+	copy(digits, alpha)
+	sort.Slice(digits, func(i, j int) bool { return digits[i] < digits[j] })
+
+	order := make([]int, len(lengths))
+	for ii := range order {
+		order[ii] = ii
+	}
+	sort.SliceStable(order, func(i, j int) bool { return lengths[order[i]] < lengths[order[j]] })
+
+	prefc.code = make(map[string]int, len(lengths))
+	var word []int
+	for _, symbol := range order {
+		for len(word) < lengths[symbol] {
+			word = append(word, 0)
+		}
+		codeword := make([]rune, len(word))
+		for ii, d := range word {
+			codeword[ii] = digits[d]
+		}
+		prefc.code[string(codeword)] = symbol
+
+		// next codeword: add one in base n, carrying leftwards.
+		for ii := len(word) - 1; ii >= 0; ii-- {
+			word[ii]++
+			if word[ii] < len(digits) {
+				break
+			}
+			word[ii] = 0
+		}
+	}
+	prefc.reindex()
+	return prefc, nil
+}
+
+// checkKraftEquality verifies that codeword lengths over an alphabet of size n
+// satisfy  sum n^-l = 1, computed exactly.
+func checkKraftEquality(n int, lengths []int) error {
+	maxLen := 0
+	for ii, l := range lengths {
+		if l < 0 {
+			return errors.New("Negative codeword length at index " + strconv.Itoa(ii))
+		}
+		if 0 == l && len(lengths) > 1 {
+			return errors.New("Zero codeword length at index " + strconv.Itoa(ii) + " among several symbols")
+		}
+		if l > maxLen {
+			maxLen = l
+		}
+	}
+
+	// compare  sum n^(maxLen-l)  with  n^maxLen.
+	base := big.NewInt(int64(n))
+	sum := new(big.Int)
+	term := new(big.Int)
+	for _, l := range lengths {
+		sum.Add(sum, term.Exp(base, big.NewInt(int64(maxLen-l)), nil))
+	}
+	if 0 != sum.Cmp(term.Exp(base, big.NewInt(int64(maxLen)), nil)) {
+		return errors.New("Codeword lengths do not satisfy the Kraft equality")
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package prefcode
+
+import (
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	// the DEFLATE RFC example: lengths (3, 3, 3, 3, 3, 2, 4, 4).
+	t.Run("Checking NewCodeFromLengths RFC1951 example.", func(t *testing.T) {
+		cc, err := NewCodeFromLengths([]rune("01"), []int{3, 3, 3, 3, 3, 2, 4, 4})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewCodeFromLengths in test checking RFC example.")
+		}
+		assertCorrectMessage(t, cc.String(), "[00 5], [010 0], [011 1], [100 2], [101 3], [110 4], [1110 6], [1111 7]")
+	})
+
+	t.Run("Checking NewCodeFromLengths ternary.", func(t *testing.T) {
+		cc, err := NewCodeFromLengths([]rune("cab"), []int{2, 1, 2, 2, 1})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewCodeFromLengths in test checking ternary.")
+		}
+		assertCorrectMessage(t, cc.String(), "[a 1], [b 4], [ca 0], [cb 2], [cc 3]")
+	})
+
+	t.Run("Checking NewCodeFromLengths bad lengths.", func(t *testing.T) {
+		if _, err := NewCodeFromLengths([]rune("01"), []int{1, 2}); nil == err {
+			assertCorrectMessage(t, "No error for ", "incomplete lengths.")
+		}
+		if _, err := NewCodeFromLengths([]rune("01"), []int{1, 1, 1}); nil == err {
+			assertCorrectMessage(t, "No error for ", "oversubscribed lengths.")
+		}
+		if _, err := NewCodeFromLengths([]rune("01"), []int{-1}); nil == err {
+			assertCorrectMessage(t, "No error for ", "negative length.")
+		}
+		single, err := NewCodeFromLengths([]rune("01"), []int{0})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewCodeFromLengths for one symbol.")
+		}
+		assertCorrectMessage(t, single.String(), "[𝛆 0]")
+	})
+}
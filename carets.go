@@ -0,0 +1,81 @@
+package prefcode
+
+// The exposed carets of a prefixCode (the carets all of whose children are
+// leaves) are kept in a set, updated by ExpandAt and ReduceAt as they go
+// rather than recounted from the whole code.  The root is written "".
+
+// NumExposedCarets returns the number of exposed carets of p.
+func (p *prefixCode) NumExposedCarets() int {
+	return len(p.exposed)
+}
+
+// ForEachExposedCaret calls fn on each exposed caret of p, in no particular
+// order, until fn returns false.  It allocates nothing; p must not be
+// changed by fn.
+func (p *prefixCode) ForEachExposedCaret(fn func(caret string) bool) {
+	for caret := range p.exposed {
+		if !fn(caret) {
+			return
+		}
+	}
+}
+
+// indexCarets rebuilds the set of exposed carets from the trie.
+func (p *prefixCode) indexCarets() {
+	p.exposed = make(map[string]struct{})
+	p.trie.exposedCarets("", p.exposed)
+}
+
+// exposedCarets adds to set the exposed carets at or below t, where t sits at
+// word.
+func (t *trieNode) exposedCarets(word string, set map[string]struct{}) {
+	stack := []trieEntry{{t, word}}
+	for 0 < len(stack) {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if e.t.leaf {
+			continue
+		}
+		if e.t.isExposed() {
+			set[e.word] = struct{}{}
+			continue
+		}
+		for r, child := range e.t.children {
+			stack = append(stack, trieEntry{child, e.word + string(r)})
+		}
+	}
+}
+
+// isExposed reports whether t is a caret whose children are all leaves.
+func (t *trieNode) isExposed() bool {
+	if t.leaf || 0 == len(t.children) {
+		return false
+	}
+	for _, child := range t.children {
+		if !child.leaf {
+			return false
+		}
+	}
+	return true
+}
+
+// caretExpanded updates the exposed carets after the leaf was replaced by the
+// tree with caret as its only exposed caret.
+func (p *prefixCode) caretExpanded(leaf, caret string) {
+	if !p.isEmptyWord(leaf) {
+		delete(p.exposed, trimLastChar(leaf))
+	}
+	p.exposed[caret] = struct{}{}
+}
+
+// caretReduced updates the exposed carets after the tree at word, with the
+// given leaves, collapsed to the leaf word.
+func (p *prefixCode) caretReduced(word string, below []string) {
+	for _, leaf := range below {
+		delete(p.exposed, trimLastChar(leaf))
+	}
+	parent := trimLastChar(word)
+	if node := p.trie.find(parent); nil != node && node.isExposed() {
+		p.exposed[parent] = struct{}{}
+	}
+}
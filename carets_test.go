@@ -0,0 +1,91 @@
+package prefcode
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCarets(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	// with more than ten leaves some labels have two digits.
+	t.Run("Checking ExposedCarets with multi-digit labels.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking multi-digit labels.")
+		}
+		for ii := 0; ii < 12; ii++ {
+			baseCode.ExpandAt(strings.Repeat("1", ii))
+		}
+		assertCorrectMessage(t, strings.Join(baseCode.ExposedCarets(), " "), "11111111111")
+		assertCorrectMessage(t, strconv.Itoa(baseCode.NumExposedCarets()), "1")
+
+		baseCode.ExpandAt("0")
+		baseCode.ExpandAt("1110")
+		assertCorrectMessage(t, strings.Join(baseCode.ExposedCarets(), " "), "0 1110 11111111111")
+		baseCode.ReduceAt("111")
+		assertCorrectMessage(t, strings.Join(baseCode.ExposedCarets(), " "), "0 11")
+		baseCode.ReduceAt("")
+		assertCorrectMessage(t, strconv.Itoa(baseCode.NumExposedCarets()), "0")
+	})
+
+	t.Run("Checking ForEachExposedCaret.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking ForEachExposedCaret.")
+		}
+		baseCode.ExpandAt("00")
+		baseCode.ExpandAt("11")
+		var seen []string
+		baseCode.ForEachExposedCaret(func(caret string) bool {
+			seen = append(seen, caret)
+			return true
+		})
+		sort.Strings(seen)
+		assertCorrectMessage(t, strings.Join(seen, " "), "00 11")
+
+		calls := 0
+		baseCode.ForEachExposedCaret(func(string) bool {
+			calls++
+			return false
+		})
+		assertCorrectMessage(t, strconv.Itoa(calls), "1")
+
+		allocs := testing.AllocsPerRun(10, func() {
+			baseCode.ForEachExposedCaret(func(string) bool { return true })
+		})
+		assertCorrectMessage(t, strconv.Itoa(int(allocs)), "0")
+	})
+
+	// the incremental set must match one rebuilt from the tree.
+	t.Run("Checking incremental carets against a rebuild.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(3))
+		baseCode, err := NewPrefCodeAlphaString("abc")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString() in test checking incremental carets.")
+		}
+		for step := 0; step < 400; step++ {
+			leaves := baseCode.SortedLeaves()
+			if leaf := leaves[rng.Intn(len(leaves))]; 0 < rng.Intn(3) {
+				if EmptyString == leaf {
+					leaf = ""
+				}
+				baseCode.ExpandAt(leaf + string("abc"[rng.Intn(3)]))
+			} else {
+				baseCode.ReduceAt(baseCode.ParentOf(leaf))
+			}
+			got := strings.Join(baseCode.ExposedCarets(), " ")
+			baseCode.indexCarets()
+			assertCorrectMessage(t, got, strings.Join(baseCode.ExposedCarets(), " "))
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package prefcode
+
+import (
+	"errors"
+	"iter"
+)
+
+// CayleyGraph is the Cayley graph of the subgroup of V generated by a set of
+// tree pairs, edges being right multiplication by a generator or its
+// inverse.  Elements are compared by reduced form.
+type CayleyGraph struct {
+	alphabet []rune
+	steps    []TreePair // the generators and their inverses, reduced
+}
+
+// NewCayleyGraph returns the Cayley graph of the group generated by gens,
+// after checking there is at least one and they share an alphabet.
+func NewCayleyGraph(gens ...TreePair) (*CayleyGraph, error) {
+	if 0 == len(gens) {
+		return nil, errors.New("No generators for a Cayley graph")
+	}
+	g := &CayleyGraph{alphabet: gens[0].domain.alphabet}
+	for _, s := range gens {
+		if string(g.alphabet) != string(s.domain.alphabet) {
+			return nil, errors.New("Generators have different alphabets")
+		}
+		g.steps = append(g.steps, s.Reduce(), s.Inverse().Reduce())
+	}
+	return g, nil
+}
+
+// Ball yields, in reduced form, each element of word length at most radius
+// once, the identity first and then sphere by sphere, each sphere in the
+// order its elements are first reached.  A negative radius gives nothing.
+// The spheres are kept as the walk goes, so the ball is built lazily but
+// the elements seen so far stay in memory.
+func (g *CayleyGraph) Ball(radius int) iter.Seq[TreePair] {
+	return func(yield func(TreePair) bool) {
+		if radius < 0 {
+			return
+		}
+		id, _ := IdentityPair(g.alphabet)
+		seen := map[string]bool{id.String(): true}
+		if !yield(id) {
+			return
+		}
+		sphere := []TreePair{id}
+		for r := 0; r < radius && 0 < len(sphere); r++ {
+			var next []TreePair
+			for _, t := range sphere {
+				for _, s := range g.steps {
+					u, _ := t.Compose(s)
+					key := u.String()
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+					if !yield(u) {
+						return
+					}
+					next = append(next, u)
+				}
+			}
+			sphere = next
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package prefcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCayleyGraph(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	x0 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "11": 2}, map[string]int{"00": 0, "01": 1, "1": 2})
+	x1 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "110": 2, "111": 3}, map[string]int{"0": 0, "100": 1, "101": 2, "11": 3})
+
+	t.Run("Checking balls of Thompson's F grow as they should.", func(t *testing.T) {
+		g, err := NewCayleyGraph(x0, x1)
+		if nil != err {
+			t.Fatalf("NewCayleyGraph: %v", err)
+		}
+		// the spheres of F on x0, x1 have 1, 4, 12 and 36 elements.
+		var sizes []int
+		for radius := 0; radius <= 3; radius++ {
+			sizes = append(sizes, len(collectBall(g, radius)))
+		}
+		assertCorrectMessage(t, fmt.Sprint(sizes), "[1 5 17 53]")
+
+		var first []string
+		for u := range g.Ball(1) {
+			first = append(first, u.String())
+			if 2 == len(first) {
+				break
+			}
+		}
+		assertCorrectMessage(t, fmt.Sprint(first), "[[𝛆 0] -> [𝛆 0] "+x0.Reduce().String()+"]")
+	})
+
+	t.Run("Checking elements are found once.", func(t *testing.T) {
+		// the swap of 0 and 1 is its own inverse, and given twice.
+		swap := pairFromMaps(t, map[string]int{"0": 0, "1": 1}, map[string]int{"0": 1, "1": 0})
+		g, err := NewCayleyGraph(swap, swap)
+		if nil != err {
+			t.Fatalf("NewCayleyGraph: %v", err)
+		}
+		var all []string
+		for u := range g.Ball(5) {
+			all = append(all, u.String())
+		}
+		assertCorrectMessage(t, fmt.Sprint(all), "[[𝛆 0] -> [𝛆 0] [0 0], [1 1] -> [0 1], [1 0]]")
+		assertCorrectMessage(t, fmt.Sprint(len(collectBall(g, -1))), "0")
+	})
+
+	t.Run("Checking bad generating sets.", func(t *testing.T) {
+		_, err := NewCayleyGraph()
+		assertCorrectMessage(t, fmt.Sprint(err), "No generators for a Cayley graph")
+		d, _ := NewPrefCodeAlphaString("abc")
+		id, _ := NewTreePair(d, d)
+		_, err = NewCayleyGraph(x0, id)
+		assertCorrectMessage(t, fmt.Sprint(err), "Generators have different alphabets")
+	})
+}
+
+func collectBall(g *CayleyGraph, radius int) []TreePair {
+	var all []TreePair
+	for u := range g.Ball(radius) {
+		all = append(all, u)
+	}
+	return all
+}
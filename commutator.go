@@ -0,0 +1,29 @@
+package prefcode
+
+// Conjugate returns h^-1 g h in reduced form, read as Compose does: the
+// inverse of h, then g, then h.  So ConjugateInF(g, Conjugate(g, h)) finds
+// the two conjugate, and as maps Conjugate(g, h) is g moved along h.  g and
+// h must share an alphabet; Conjugate panics otherwise.
+func Conjugate(g, h TreePair) TreePair {
+	return mustPairs(h.Inverse(), g, h)
+}
+
+// Commutator returns g^-1 h^-1 g h in reduced form, read as Compose does, so
+// the identity exactly when g and h commute.  g and h must share an
+// alphabet; Commutator panics otherwise.
+func Commutator(g, h TreePair) TreePair {
+	return mustPairs(g.Inverse(), h.Inverse(), g, h)
+}
+
+// mustPairs returns the composite of pairs in order, panicking if their
+// alphabets differ.
+func mustPairs(pairs ...TreePair) TreePair {
+	c := pairs[0]
+	for _, q := range pairs[1:] {
+		var err error
+		if c, err = c.Compose(q); nil != err {
+			panic(err.Error())
+		}
+	}
+	return c
+}
@@ -0,0 +1,51 @@
+package prefcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCommutator(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	x0, x1, x2 := generatorF(t, 0), generatorF(t, 1), generatorF(t, 2)
+
+	t.Run("Checking conjugates.", func(t *testing.T) {
+		// x0^-1 x1 x0 = x2 as maps, right to left.
+		assertCorrectMessage(t, Conjugate(x1, x0.Inverse()).String(), x2.Reduce().String())
+		assertCorrectMessage(t, Conjugate(x0, x0).String(), x0.Reduce().String())
+		ok, _, err := ConjugateInF(x0, Conjugate(x0, x1))
+		if nil != err {
+			t.Fatalf("ConjugateInF: %v", err)
+		}
+		assertCorrectMessage(t, fmt.Sprint(ok), "true")
+	})
+
+	t.Run("Checking commutators.", func(t *testing.T) {
+		assertCorrectMessage(t, fmt.Sprint(Commutator(x0, x0).IsIdentity()), "true")
+		c := Commutator(x0, x1)
+		assertCorrectMessage(t, fmt.Sprint(c.IsIdentity()), "false")
+		assertCorrectMessage(t, c.Inverse().Reduce().String(), Commutator(x1, x0).String())
+		a, b := c.AbelianImage()
+		assertCorrectMessage(t, fmt.Sprint(a, b), "0 0")
+
+		// the relation [x0 x1^-1, x2] of F, x0 x1^-1 as maps being x1^-1 then x0.
+		y := mustCompose(t, x1.Inverse(), x0)
+		assertCorrectMessage(t, fmt.Sprint(Commutator(y, x2).IsIdentity()), "true")
+		assertCorrectMessage(t, fmt.Sprint(Commutator(y, generatorF(t, 3)).IsIdentity()), "true")
+	})
+
+	t.Run("Checking pairs over different alphabets panic.", func(t *testing.T) {
+		defer func() {
+			assertCorrectMessage(t, fmt.Sprint(recover()), "Tree pairs have different alphabets")
+		}()
+		id, _ := IdentityPair([]rune("abc"))
+		Commutator(x0, id)
+	})
+}
@@ -0,0 +1,501 @@
+package prefcode
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"iter"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CompactPrefCode is a PrefCode for very large codes.  Rather than a map from
+// words to labels it stores the tree as its DFS bit sequence (1 for a caret, 0
+// for a leaf, children in natural rune order, so leaves come in dictionary
+// order) with rank/select support, plus the labels as an array indexed by
+// leaf order.  That is about 4 bytes per leaf instead of a map entry and a
+// string per leaf.
+//
+// Structure and labels are handled natively: ExpandAt, ReduceAt, label
+// lookups and permutations, String, Code, ExposedCarets, the prefix lookups
+// and the queries on words, depths and the shape of the tree.  The subtrees
+// skipped on the way down a word are jumped over with the excess index of the
+// bit vector.  Only Join, Meet, Neighbors, Tokenize, SplitFunc, CodeToSlice
+// and WalkBFS work on a temporary map-backed copy.
+type CompactPrefCode struct {
+	alphabet []rune
+	letters  []rune       // the alphabet in natural rune order
+	index    map[rune]int // position of each letter in letters
+	dfs      bitVector
+	perm     []int32 // perm[ii] is the label of the ii-th leaf in dictionary order
+}
+
+var _ PrefCode = (*CompactPrefCode)(nil)
+
+// NewCompactPrefCode returns the trivial CompactPrefCode over alpha.
+func NewCompactPrefCode(alpha []rune) (*CompactPrefCode, error) {
+	pc, err := NewPrefCodeAlphaRunes(alpha)
+	if err != nil {
+		return nil, err
+	}
+	return NewCompactFrom(pc), nil
+}
+
+// NewCompactFrom returns a CompactPrefCode holding a copy of pc.
+func NewCompactFrom(pc PrefCode) *CompactPrefCode {
+	var c CompactPrefCode
+	c.SetAlphabet(pc.Alphabet())
+	c.SetCode(pc.Code())
+	return &c
+}
+
+func (c *CompactPrefCode) Alphabet() []rune {
+	alpha := make([]rune, len(c.alphabet))
+	copy(alpha, c.alphabet)
+	return alpha
+}
+
+func (c *CompactPrefCode) SetAlphabet(a []rune) {
+	c.alphabet = make([]rune, len(a))
+	copy(c.alphabet, a)
+	c.letters = MakeAlphabet(string(a))
+	c.index = make(map[rune]int, len(c.letters))
+	for ii, r := range c.letters {
+		c.index[r] = ii
+	}
+	if up := len(c.letters) - 1; up != c.dfs.up {
+		c.dfs.up = up
+		c.dfs.buildRanks()
+	}
+}
+
+// SetCode replaces the code by the (assumed complete) code m.
+//
+// Deprecated: as for prefixCode, use NewPrefCodeFromMap.
+func (c *CompactPrefCode) SetCode(m map[string]int) {
+	leaves := make([]string, 0, len(m))
+	for leaf := range m {
+		leaves = append(leaves, leaf)
+	}
+	sort.Strings(leaves)
+
+	c.dfs = bitVector{up: len(c.letters) - 1}
+	c.perm = make([]int32, len(leaves))
+	var prev []rune
+	for ii, leaf := range leaves {
+		c.perm[ii] = int32(m[leaf])
+		word := []rune(leaf)
+		if EmptyString == leaf {
+			word = nil
+		}
+		// open the carets between the last common ancestor and the leaf.
+		start := 0
+		if ii > 0 {
+			for start < len(prev) && start < len(word) && prev[start] == word[start] {
+				start++
+			}
+			start++
+		}
+		if len(word) > start {
+			c.dfs.pushRepeat(true, len(word)-start)
+		}
+		c.dfs.push(0, 1)
+		prev = word
+	}
+	c.dfs.buildRanks()
+}
+
+func (c *CompactPrefCode) Size() int {
+	return len(c.perm)
+}
+
+// DFS returns the DFS bit sequence of the tree as a string of 1s and 0s.
+func (c *CompactPrefCode) DFS() string {
+	var b strings.Builder
+	for ii := 0; ii < c.dfs.n; ii++ {
+		if c.dfs.get(ii) {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+	}
+	return b.String()
+}
+
+// subtreeEnd returns the position just past the subtree starting at pos.
+func (c *CompactPrefCode) subtreeEnd(pos int) int {
+	return c.dfs.closeAt(pos, 1)
+}
+
+// descend follows word down from the root as long as it stays on carets.  It
+// returns the position reached and how many letters of word were used; a leaf
+// is reached early when word lies below the code.  ok is false if a letter is
+// not in the alphabet.
+func (c *CompactPrefCode) descend(word []rune) (pos, used int, ok bool) {
+	for used < len(word) && c.dfs.get(pos) {
+		ci, found := c.index[word[used]]
+		if !found {
+			return pos, used, false
+		}
+		pos++
+		for jj := 0; jj < ci; jj++ {
+			pos = c.subtreeEnd(pos)
+		}
+		used++
+	}
+	return pos, used, true
+}
+
+// walk visits the nodes of the tree in DFS order, passing the word of each
+// node (reused between calls, so copy it to keep it), its position and
+// whether it is a leaf, until fn returns false.
+func (c *CompactPrefCode) walk(fn func(word []rune, pos int, leaf bool) bool) {
+	var word []rune
+	var next []int // next[d] is the index of the next child at depth d+1
+	for pos := 0; pos < c.dfs.n; pos++ {
+		leaf := !c.dfs.get(pos)
+		if !fn(word, pos, leaf) {
+			return
+		}
+		if !leaf {
+			word = append(word, c.letters[0])
+			next = append(next, 1)
+			continue
+		}
+		// climb until some caret still has children to visit.
+		for 0 < len(next) && next[len(next)-1] == len(c.letters) {
+			word = word[:len(word)-1]
+			next = next[:len(next)-1]
+		}
+		if 0 < len(next) {
+			word[len(word)-1] = c.letters[next[len(next)-1]]
+			next[len(next)-1]++
+		}
+	}
+}
+
+func wordString(word []rune) string {
+	if 0 == len(word) {
+		return EmptyString
+	}
+	return string(word)
+}
+
+func cleanWord(s string) []rune {
+	if EmptyString == s {
+		return nil
+	}
+	return []rune(s)
+}
+
+// leafIndex returns the index in dictionary order of the leaf s, if s is a
+// leaf.  As for the keys of Code, only EmptyString names the root leaf.
+func (c *CompactPrefCode) leafIndex(s string) (int, bool) {
+	if "" == s {
+		return FAILURE, false
+	}
+	word := cleanWord(s)
+	pos, used, ok := c.descend(word)
+	if !ok || used < len(word) || c.dfs.get(pos) {
+		return FAILURE, false
+	}
+	return c.dfs.rank0(pos), true
+}
+
+func (c *CompactPrefCode) LabelAtLeaf(leaf string) int {
+	ii, ok := c.leafIndex(leaf)
+	if !ok {
+		return FAILURE
+	}
+	return int(c.perm[ii])
+}
+
+func (c *CompactPrefCode) LeafAtLabel(label int) string {
+	target := FAILURE
+	for ii, v := range c.perm {
+		if int(v) == label {
+			target = ii
+			break
+		}
+	}
+	if FAILURE == target {
+		return ""
+	}
+	pos := c.dfs.select0(target)
+	var leaf string
+	c.walk(func(word []rune, at int, _ bool) bool {
+		if at == pos {
+			leaf = wordString(word)
+			return false
+		}
+		return true
+	})
+	return leaf
+}
+
+func (c *CompactPrefCode) LabelsToLeaves() []string {
+	leaves := make([]string, len(c.perm))
+	ii := 0
+	c.walk(func(word []rune, _ int, leaf bool) bool {
+		if leaf {
+			if label := int(c.perm[ii]); label >= 0 && label < len(leaves) {
+				leaves[label] = wordString(word)
+			}
+			ii++
+		}
+		return true
+	})
+	return leaves
+}
+
+func (c *CompactPrefCode) Code() map[string]int {
+	code := make(map[string]int, len(c.perm))
+	ii := 0
+	c.walk(func(word []rune, _ int, leaf bool) bool {
+		if leaf {
+			code[wordString(word)] = int(c.perm[ii])
+			ii++
+		}
+		return true
+	})
+	return code
+}
+
+func (c *CompactPrefCode) String() string {
+	var b strings.Builder
+	c.WriteTo(&b)
+	return b.String()
+}
+
+func (c *CompactPrefCode) WriteTo(w io.Writer) (int64, error) {
+	out := entryWriter{w: w}
+	ii := 0
+	c.walk(func(word []rune, _ int, leaf bool) bool {
+		if leaf {
+			out.entry(wordString(word), int(c.perm[ii]))
+			ii++
+		}
+		return nil == out.err
+	})
+	return out.flush()
+}
+
+func (c *CompactPrefCode) Equals(q PrefCode) bool {
+	return c.String() == q.String()
+}
+
+func (c *CompactPrefCode) Permutation() Perm {
+	perm := make(Perm, len(c.perm))
+	for ii, v := range c.perm {
+		perm[ii] = int(v)
+	}
+	return perm
+}
+
+func (c *CompactPrefCode) ApplyPerm(perm Perm) bool {
+	if len(c.perm) != len(perm) {
+		Logger().Warn("permutation of the wrong size", "op", "ApplyPerm", "perm", len(perm), "size", len(c.perm))
+		return false
+	}
+	for ii, v := range c.perm {
+		c.perm[ii] = int32(perm[int(v)])
+	}
+	return true
+}
+
+func (c *CompactPrefCode) ComposePerm(perm Perm) error {
+	if len(perm) != len(c.perm) {
+		return errors.New("Permutation has size " + strconv.Itoa(len(perm)) + " but the code has " + strconv.Itoa(len(c.perm)) + " leaves")
+	}
+	if err := perm.Validate(); nil != err {
+		return err
+	}
+	c.ApplyPerm(perm)
+	return nil
+}
+
+func (c *CompactPrefCode) RelabelLexicographic() {
+	for ii := range c.perm {
+		c.perm[ii] = int32(ii)
+	}
+}
+
+func (c *CompactPrefCode) RelabelBy(less func(a, b string) bool) {
+	var leaves []string
+	c.walk(func(word []rune, _ int, leaf bool) bool {
+		if leaf {
+			leaves = append(leaves, wordString(word))
+		}
+		return true
+	})
+	order := make([]int, len(leaves))
+	for ii := range order {
+		order[ii] = ii
+	}
+	sort.SliceStable(order, func(i, j int) bool { return less(leaves[order[i]], leaves[order[j]]) })
+	for label, ii := range order {
+		c.perm[ii] = int32(label)
+	}
+}
+
+func (c *CompactPrefCode) InvertLabels() {
+	inverse := make([]int32, len(c.perm))
+	for ii, v := range c.perm {
+		inverse[v] = int32(ii)
+	}
+	c.perm = inverse
+}
+
+func (c *CompactPrefCode) SwapPermAtKeys(a, b string) error {
+	ia, oka := c.leafIndex(a)
+	ib, okb := c.leafIndex(b)
+	if !oka || !okb {
+		return errors.New("Did not find leaf a or leaf b")
+	}
+	c.perm[ia], c.perm[ib] = c.perm[ib], c.perm[ia]
+	return nil
+}
+
+// ExpandAt behaves as for the map-backed code: the leaf on the way to s is
+// replaced by the minimal subtree having s as an exposed caret.
+func (c *CompactPrefCode) ExpandAt(s string) bool {
+	word := cleanWord(s)
+	pos, used, ok := c.descend(word)
+	if !ok || c.dfs.get(pos) {
+		return false // a foreign letter, or s is a caret already
+	}
+	spine := word[used:]
+	for _, r := range spine {
+		if _, found := c.index[r]; !found {
+			return false
+		}
+	}
+
+	// the subtree: down the spine, leaves left of it, then the exposed
+	// caret, then the leaves right of the spine on the way back up.
+	n := len(c.letters)
+	var sub bitVector
+	for _, r := range spine {
+		sub.push(1, 1)
+		sub.pushRepeat(false, c.index[r])
+	}
+	sub.push(1, 1)
+	sub.pushRepeat(false, n)
+	for jj := len(spine) - 1; jj >= 0; jj-- {
+		sub.pushRepeat(false, n-1-c.index[spine[jj]])
+	}
+
+	li := c.dfs.rank0(pos)
+	labelAtP := c.perm[li]
+	added := int32(len(spine)*(n-1) + n)
+	perm := make([]int32, 0, len(c.perm)+int(added)-1)
+	perm = append(perm, c.perm[:li]...)
+	for jj := int32(0); jj < added; jj++ {
+		perm = append(perm, labelAtP+jj)
+	}
+	perm = append(perm, c.perm[li+1:]...)
+	for ii := range perm {
+		if (ii < li || ii >= li+int(added)) && perm[ii] > labelAtP {
+			perm[ii] += added - 1
+		}
+	}
+	c.perm = perm
+	c.dfs.splice(pos, pos+1, &sub)
+	return true
+}
+
+// ReduceAt behaves as for the map-backed code: the subtree at s collapses to
+// the leaf s with the least of its labels, and the other labels close ranks.
+func (c *CompactPrefCode) ReduceAt(s string) bool {
+	word := cleanWord(s)
+	pos, used, ok := c.descend(word)
+	if !ok || used < len(word) {
+		return false
+	}
+	end := c.subtreeEnd(pos)
+	li, lj := c.dfs.rank0(pos), c.dfs.rank0(end)
+
+	removed := make([]int32, lj-li)
+	copy(removed, c.perm[li:lj])
+	sort.Slice(removed, func(i, j int) bool { return removed[i] < removed[j] })
+	least := removed[0]
+
+	perm := make([]int32, 0, len(c.perm)-len(removed)+1)
+	perm = append(perm, c.perm[:li]...)
+	perm = append(perm, least)
+	perm = append(perm, c.perm[lj:]...)
+	for ii, v := range perm {
+		if v > least {
+			below := sort.Search(len(removed), func(k int) bool { return removed[k] >= v })
+			perm[ii] = v - int32(below) + 1
+		}
+	}
+	c.perm = perm
+
+	var leaf bitVector
+	leaf.push(0, 1)
+	c.dfs.splice(pos, end, &leaf)
+	return true
+}
+
+func (c *CompactPrefCode) ExposedCarets() []string {
+	var carets []string
+	c.ForEachExposedCaret(func(caret string) bool {
+		carets = append(carets, caret)
+		return true
+	})
+	sort.Strings(carets)
+	return carets
+}
+
+// NumExposedCarets counts the carets followed in the DFS by as many leaves.
+func (c *CompactPrefCode) NumExposedCarets() int {
+	count := 0
+	for pos := 0; pos < c.dfs.n; pos++ {
+		if c.dfs.get(pos) && 0 == c.dfs.chunkOnes(pos+1, len(c.letters)) {
+			count++
+		}
+	}
+	return count
+}
+
+// ForEachExposedCaret calls fn on the exposed carets in dictionary order,
+// until fn returns false.
+func (c *CompactPrefCode) ForEachExposedCaret(fn func(caret string) bool) {
+	n := len(c.letters)
+	c.walk(func(word []rune, pos int, leaf bool) bool {
+		if !leaf && 0 == c.dfs.chunkOnes(pos+1, n) {
+			return fn(string(word))
+		}
+		return true
+	})
+}
+
+func (c *CompactPrefCode) GetPrefixOf(s string) string {
+	word := cleanWord(s)
+	pos, used, ok := c.descend(word)
+	if !ok || c.dfs.get(pos) {
+		return ""
+	}
+	return wordString(word[:used])
+}
+
+// materialize returns a map-backed copy of c, for the methods not handled
+// natively.
+func (c *CompactPrefCode) materialize() *prefixCode {
+	pc := &prefixCode{alphabet: c.Alphabet(), code: c.Code()}
+	pc.reindex()
+	return pc
+}
+
+func (c *CompactPrefCode) Join(q PrefCode) (*prefixCode, error) { return c.materialize().Join(q) }
+func (c *CompactPrefCode) Meet(q PrefCode) (*prefixCode, error) { return c.materialize().Meet(q) }
+
+// Deprecated: use SortedLeaves or Entries, as for prefixCode.
+func (c *CompactPrefCode) CodeToSlice() *[]string { return c.materialize().CodeToSlice() }
+func (c *CompactPrefCode) Neighbors(filter func(PrefCode) bool) iter.Seq[PrefCode] {
+	return c.materialize().Neighbors(filter)
+}
+func (c *CompactPrefCode) Tokenize(s string) ([]string, error) { return c.materialize().Tokenize(s) }
+func (c *CompactPrefCode) SplitFunc() bufio.SplitFunc          { return c.materialize().SplitFunc() }
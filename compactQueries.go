@@ -0,0 +1,261 @@
+package prefcode
+
+// The queries of depth.go, tree.go, shape.go and leaves.go for a
+// CompactPrefCode, answered from the DFS sequence: a word by following it
+// down with descend, the rest by one walk over the tree, without building
+// the map-backed copy.
+
+func (c *CompactPrefCode) GetLongestPrefixOf(s string) (string, bool) {
+	word := cleanWord(s)
+	pos, used, ok := c.descend(word)
+	if !ok || c.dfs.get(pos) {
+		return "", false
+	}
+	return wordString(word[:used]), true
+}
+
+// GetAllCodePrefixesOf returns the leaf which is a prefix of s, if any: the
+// tree is a complete code, so there is at most one.
+func (c *CompactPrefCode) GetAllCodePrefixesOf(s string) []string {
+	leaf, ok := c.GetLongestPrefixOf(s)
+	if !ok {
+		return nil
+	}
+	return []string{leaf}
+}
+
+// overAlphabet reports whether every letter of word is in the alphabet.
+func (c *CompactPrefCode) overAlphabet(word []rune) bool {
+	for _, r := range word {
+		if _, ok := c.index[r]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *CompactPrefCode) IsLeaf(word string) bool {
+	if "" == word {
+		word = EmptyString
+	}
+	_, ok := c.leafIndex(word)
+	return ok
+}
+
+func (c *CompactPrefCode) IsInternal(word string) bool {
+	w := cleanWord(word)
+	if !c.overAlphabet(w) {
+		return false
+	}
+	pos, used, _ := c.descend(w)
+	return used == len(w) && c.dfs.get(pos)
+}
+
+func (c *CompactPrefCode) IsBelowCode(word string) bool {
+	w := cleanWord(word)
+	if 0 == len(w) || !c.overAlphabet(w) {
+		return false
+	}
+	_, used, _ := c.descend(w)
+	return used < len(w)
+}
+
+// isLeafKey reports whether leaf is a leaf as a key of Code, so the root
+// leaf of the trivial code only as EmptyString.
+func (c *CompactPrefCode) isLeafKey(leaf string) bool {
+	_, ok := c.leafIndex(leaf)
+	return ok
+}
+
+func (c *CompactPrefCode) DepthOf(leaf string) int {
+	if !c.isLeafKey(leaf) {
+		return FAILURE
+	}
+	return wordLen(leaf)
+}
+
+// leafDepths calls fn with the depth of each leaf, in dictionary order.
+func (c *CompactPrefCode) leafDepths(fn func(depth int)) {
+	c.walk(func(word []rune, _ int, leaf bool) bool {
+		if leaf {
+			fn(len(word))
+		}
+		return true
+	})
+}
+
+func (c *CompactPrefCode) MaxDepth() int {
+	maxDepth := 0
+	c.leafDepths(func(d int) { maxDepth = max(maxDepth, d) })
+	return maxDepth
+}
+
+func (c *CompactPrefCode) MinDepth() int {
+	minDepth := FAILURE
+	c.leafDepths(func(d int) {
+		if FAILURE == minDepth || d < minDepth {
+			minDepth = d
+		}
+	})
+	return minDepth
+}
+
+func (c *CompactPrefCode) IsUniformDepth() bool {
+	return c.MinDepth() == c.MaxDepth()
+}
+
+func (c *CompactPrefCode) DepthHistogram() map[int]int {
+	hist := make(map[int]int)
+	c.leafDepths(func(d int) { hist[d]++ })
+	return hist
+}
+
+func (c *CompactPrefCode) MeanDepth() float64 {
+	total := 0
+	c.leafDepths(func(d int) { total += d })
+	return float64(total) / float64(c.Size())
+}
+
+func (c *CompactPrefCode) DepthVariance() float64 {
+	mean := c.MeanDepth()
+	variance := 0.0
+	c.leafDepths(func(d int) {
+		diff := float64(d) - mean
+		variance += diff * diff
+	})
+	return variance / float64(c.Size())
+}
+
+// carets calls fn with the word of each caret, in dictionary order, until fn
+// returns false.
+func (c *CompactPrefCode) carets(fn func(caret string) bool) {
+	c.walk(func(word []rune, _ int, leaf bool) bool {
+		return leaf || fn(string(word))
+	})
+}
+
+func (c *CompactPrefCode) InternalNodes() []string {
+	nodes := make([]string, 0, c.NumCarets())
+	c.carets(func(caret string) bool {
+		nodes = append(nodes, caret)
+		return true
+	})
+	return nodes
+}
+
+func (c *CompactPrefCode) NumCarets() int {
+	return c.dfs.rank1(c.dfs.n)
+}
+
+func (c *CompactPrefCode) ParentOf(word string) string {
+	if EmptyString == word {
+		return ""
+	}
+	return trimLastChar(word)
+}
+
+func (c *CompactPrefCode) ChildrenOf(word string) []string {
+	if !c.IsInternal(word) {
+		return nil
+	}
+	children := make([]string, len(c.alphabet))
+	for ii, r := range c.alphabet {
+		children[ii] = word + string(r)
+	}
+	return children
+}
+
+func (c *CompactPrefCode) Siblings(leaf string) []string {
+	if EmptyString == leaf || !c.isLeafKey(leaf) {
+		return nil
+	}
+	var siblings []string
+	for _, child := range c.ChildrenOf(c.ParentOf(leaf)) {
+		if child != leaf {
+			siblings = append(siblings, child)
+		}
+	}
+	return siblings
+}
+
+// LongestCommonPrefix is the root "" unless the code has a single leaf, as
+// every caret of a complete code has all its children.
+func (c *CompactPrefCode) LongestCommonPrefix() string {
+	if 1 != c.Size() || 1 == c.dfs.n {
+		return ""
+	}
+	return c.SortedLeaves()[0]
+}
+
+func (c *CompactPrefCode) SpineTo(leaf string) []string {
+	if !c.isLeafKey(leaf) {
+		return nil
+	}
+	spine := make([]string, 0, len(leaf))
+	if EmptyString == leaf {
+		return spine
+	}
+	for ii := range leaf {
+		spine = append(spine, leaf[:ii])
+	}
+	return spine
+}
+
+// LeavesInRange stops at the first leaf past hi, as the walk meets the leaves
+// in dictionary order.
+func (c *CompactPrefCode) LeavesInRange(lo, hi string) []string {
+	var leaves []string
+	c.walk(func(word []rune, _ int, leaf bool) bool {
+		if !leaf {
+			return true
+		}
+		w := string(word)
+		if w >= hi {
+			return false
+		}
+		if w >= lo {
+			leaves = append(leaves, wordString(word))
+		}
+		return true
+	})
+	return leaves
+}
+
+func (c *CompactPrefCode) CaretTypeCounts() CaretCounts {
+	var counts CaretCounts
+	first, last := c.letters[0], c.letters[len(c.letters)-1]
+	c.carets(func(caret string) bool {
+		switch {
+		case isPowerOf(caret, first):
+			counts.Left++
+		case isPowerOf(caret, last):
+			counts.Right++
+		default:
+			counts.Interior++
+		}
+		return true
+	})
+	return counts
+}
+
+func (c *CompactPrefCode) IsRightVine() bool {
+	return c.isVine(c.letters[len(c.letters)-1])
+}
+
+func (c *CompactPrefCode) IsLeftVine() bool {
+	return c.isVine(c.letters[0])
+}
+
+// isVine reports whether every caret is a power of r.
+func (c *CompactPrefCode) isVine(r rune) bool {
+	vine := true
+	c.carets(func(caret string) bool {
+		vine = isPowerOf(caret, r)
+		return vine
+	})
+	return vine
+}
+
+func (c *CompactPrefCode) IsFullTree() bool {
+	return c.IsUniformDepth()
+}
@@ -0,0 +1,210 @@
+package prefcode
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCompact(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking the DFS bits of a small code.", func(t *testing.T) {
+		cpc, err := NewCompactPrefCode([]rune("01"))
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewCompactPrefCode() in test checking DFS bits.")
+		}
+		assertCorrectMessage(t, cpc.DFS(), "0")
+		cpc.ExpandAt("10")
+		assertCorrectMessage(t, cpc.DFS(), "1011000")
+		assertCorrectMessage(t, cpc.String(), "[0 0], [100 1], [101 2], [11 3]")
+		assertCorrectMessage(t, cpc.LeafAtLabel(2), "101")
+		cpc.ReduceAt("1")
+		assertCorrectMessage(t, cpc.DFS(), "100")
+		assertCorrectMessage(t, cpc.String(), "[0 0], [1 1]")
+	})
+
+	// the compact and map-backed codes must agree under the same operations.
+	t.Run("Checking CompactPrefCode against prefixCode.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(7))
+		for _, alpha := range []string{"01", "abc", "0123"} {
+			pc, err := NewPrefCodeAlphaString(alpha)
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString() in test checking CompactPrefCode.")
+			}
+			cpc, err := NewCompactPrefCode([]rune(alpha))
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "NewCompactPrefCode() in test checking CompactPrefCode.")
+			}
+			for step := 0; step < 300; step++ {
+				leaves := pc.SortedLeaves()
+				switch rng.Intn(4) {
+				case 0, 1:
+					leaf := leaves[rng.Intn(len(leaves))]
+					if EmptyString == leaf {
+						leaf = ""
+					}
+					word := leaf + string(alpha[rng.Intn(len(alpha))])
+					assertCorrectMessage(t, strconv.FormatBool(cpc.ExpandAt(word)), strconv.FormatBool(pc.ExpandAt(word)))
+				case 2:
+					carets := pc.ExposedCarets()
+					assertCorrectMessage(t, strings.Join(cpc.ExposedCarets(), ","), strings.Join(carets, ","))
+					if 0 < len(carets) {
+						caret := carets[rng.Intn(len(carets))]
+						assertCorrectMessage(t, strconv.FormatBool(cpc.ReduceAt(caret)), strconv.FormatBool(pc.ReduceAt(caret)))
+					}
+				case 3:
+					a, b := leaves[rng.Intn(len(leaves))], leaves[rng.Intn(len(leaves))]
+					pc.SwapPermAtKeys(a, b)
+					cpc.SwapPermAtKeys(a, b)
+				}
+				assertCorrectMessage(t, cpc.String(), pc.String())
+			}
+			for label, leaf := range pc.LabelsToLeaves() {
+				assertCorrectMessage(t, cpc.LeafAtLabel(label), leaf)
+				assertCorrectMessage(t, strconv.Itoa(cpc.LabelAtLeaf(leaf)), strconv.Itoa(label))
+			}
+			assertCorrectMessage(t, strconv.Itoa(cpc.NumCarets()), strconv.Itoa(pc.NumCarets()))
+			assertCorrectMessage(t, NewCompactFrom(pc).DFS(), cpc.DFS())
+		}
+	})
+
+	t.Run("Checking rank and select on a long bit vector.", func(t *testing.T) {
+		var bv bitVector
+		var zeros []int
+		for ii := 0; ii < 1000; ii++ {
+			bit := 0 == ii%3 || 0 == ii%7
+			bv.pushRepeat(bit, 1)
+			if !bit {
+				zeros = append(zeros, ii)
+			}
+		}
+		bv.buildRanks()
+		assertCorrectMessage(t, strconv.Itoa(bv.rank0(bv.n)), strconv.Itoa(len(zeros)))
+		for k, pos := range zeros {
+			assertCorrectMessage(t, strconv.Itoa(bv.select0(k)), strconv.Itoa(pos))
+			assertCorrectMessage(t, strconv.Itoa(bv.rank0(pos)), strconv.Itoa(k))
+		}
+	})
+
+	t.Run("Checking a CompactPrefCode 100000 letters deep.", func(t *testing.T) {
+		const depth = 100000
+		deep := strings.Repeat("1", depth)
+		cpc, err := NewCompactPrefCode([]rune("01"))
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewCompactPrefCode() in test checking deep codes.")
+		}
+		assertCorrectMessage(t, strconv.FormatBool(cpc.ExpandAt(deep)), "true")
+		assertCorrectMessage(t, strconv.Itoa(cpc.Size()), strconv.Itoa(depth+2))
+		assertCorrectMessage(t, strconv.Itoa(cpc.NumCarets()), strconv.Itoa(depth+1))
+		assertCorrectMessage(t, strconv.Itoa(cpc.LabelAtLeaf(deep+"1")), strconv.Itoa(depth+1))
+		assertCorrectMessage(t, strconv.Itoa(len(cpc.ExposedCarets()[0])), strconv.Itoa(depth))
+		assertCorrectMessage(t, strconv.FormatBool(cpc.ReduceAt("1")), "true")
+		assertCorrectMessage(t, cpc.String(), "[0 0], [1 1]")
+	})
+
+	t.Run("Checking subtree ends skip through the excess index.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(11))
+		for _, n := range []int{2, 3, 5} {
+			dfs := RandomDFS(rng, n, 3000)
+			bv := bitVector{up: n - 1}
+			for _, r := range dfs {
+				bv.pushRepeat('1' == r, 1)
+			}
+			bv.buildRanks()
+			for pos := 0; pos < bv.n; pos += 1 + rng.Intn(5) {
+				end, tally := pos, 1
+				for ; tally > 0; end++ {
+					if '1' == dfs[end] {
+						tally += n - 1
+					} else {
+						tally--
+					}
+				}
+				assertCorrectMessage(t, strconv.Itoa(bv.closeAt(pos, 1)), strconv.Itoa(end))
+			}
+		}
+	})
+
+	// the queries answered from the DFS sequence must agree with the map.
+	t.Run("Checking the native queries of CompactPrefCode against prefixCode.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(5))
+		// sums of floats are compared rounded, as the map sums in any order.
+		format := func(a ...any) string {
+			for ii, x := range a {
+				if f, ok := x.(float64); ok {
+					a[ii] = math.Round(f*1e9)/1e9 + 0 // + 0 turns -0 into 0
+				}
+			}
+			return fmt.Sprint(a...)
+		}
+		for _, alpha := range []string{"01", "abc", "0123"} {
+			dfsSeqs := []string{"0"}
+			if "01" == alpha {
+				dfsSeqs = append(dfsSeqs, "10100", "11000")
+			}
+			for ii := 0; ii < 20; ii++ {
+				dfsSeqs = append(dfsSeqs, RandomDFS(rng, len(alpha), 1+rng.Intn(30)))
+			}
+			for _, dfs := range dfsSeqs {
+				pc, err := DFSFormat{}.Parse([]rune(alpha), dfs)
+				if nil != err {
+					assertCorrectMessage(t, "Faied to ", "Parse() in test checking native queries.")
+					continue
+				}
+				shuffle := make(Perm, pc.Size())
+				for from, to := range rng.Perm(pc.Size()) {
+					shuffle[from] = to
+				}
+				pc.ApplyPerm(shuffle)
+				cpc := NewCompactFrom(pc)
+				probs := make([]float64, pc.Size())
+				for jj := range probs {
+					probs[jj] = 1 / float64(len(probs))
+				}
+
+				assertCorrectMessage(t, format(cpc.MaxDepth(), cpc.MinDepth(), cpc.IsUniformDepth(), cpc.IsFullTree(), cpc.DepthHistogram()),
+					format(pc.MaxDepth(), pc.MinDepth(), pc.IsUniformDepth(), pc.IsFullTree(), pc.DepthHistogram()))
+				assertCorrectMessage(t, format(cpc.MeanDepth(), cpc.DepthVariance(), cpc.NumCarets(), cpc.InternalNodes()),
+					format(pc.MeanDepth(), pc.DepthVariance(), pc.NumCarets(), pc.InternalNodes()))
+				assertCorrectMessage(t, format(cpc.LongestCommonPrefix(), cpc.CaretTypeCounts(), cpc.IsLeftVine(), cpc.IsRightVine()),
+					format(pc.LongestCommonPrefix(), pc.CaretTypeCounts(), pc.IsLeftVine(), pc.IsRightVine()))
+				assertCorrectMessage(t, format(cpc.AverageLength(probs), cpc.Entropy(probs), cpc.OptimalityGap(probs)),
+					format(pc.AverageLength(probs), pc.Entropy(probs), pc.OptimalityGap(probs)))
+
+				words := append(pc.SortedLeaves(), pc.InternalNodes()...)
+				words = append(words, "", EmptyString, "x", alpha[:1]+"x")
+				for jj := 0; jj < 10; jj++ {
+					word := make([]byte, rng.Intn(8))
+					for kk := range word {
+						word[kk] = alpha[rng.Intn(len(alpha))]
+					}
+					words = append(words, string(word))
+				}
+				for _, w := range words {
+					assertCorrectMessage(t, format(cpc.IsLeaf(w), cpc.IsInternal(w), cpc.IsBelowCode(w), cpc.DepthOf(w), cpc.LabelAtLeaf(w)),
+						format(pc.IsLeaf(w), pc.IsInternal(w), pc.IsBelowCode(w), pc.DepthOf(w), pc.LabelAtLeaf(w)))
+					assertCorrectMessage(t, format(cpc.ParentOf(w), cpc.ChildrenOf(w), cpc.Siblings(w), cpc.SpineTo(w)),
+						format(pc.ParentOf(w), pc.ChildrenOf(w), pc.Siblings(w), pc.SpineTo(w)))
+					leaf, ok := cpc.GetLongestPrefixOf(w)
+					pleaf, pok := pc.GetLongestPrefixOf(w)
+					assertCorrectMessage(t, format(leaf, ok, cpc.GetPrefixOf(w)), format(pleaf, pok, pc.GetPrefixOf(w)))
+					if EmptyString != w {
+						assertCorrectMessage(t, format(cpc.GetAllCodePrefixesOf(w)), format(pc.GetAllCodePrefixesOf(w)))
+					}
+					lo := words[rng.Intn(len(words))]
+					assertCorrectMessage(t, format(cpc.LeavesInRange(lo, w)), format(pc.LeavesInRange(lo, w)))
+				}
+			}
+		}
+	})
+}
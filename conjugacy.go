@@ -0,0 +1,403 @@
+package prefcode
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// ConjugateInF reports whether the elements a and b of Thompson's F, over a
+// binary alphabet, are conjugate in F, and if so returns a conjugator c:
+// b is c^-1 a c, read as Compose does, that is c.Inverse() followed by a
+// followed by c.  As maps of [0,1], c carries the dynamics of a onto that of
+// b, so the test follows them (Kassabov and Matucci):
+//
+//   - c maps the fixed set of a onto that of b, so the two must have the same
+//     pattern of fixed points and fixed intervals, with the open intervals
+//     between them, the bumps, moved the same way.  A fixed point and its
+//     image must both be dyadic or both not, as c keeps dyadic rationals.
+//   - Near the ends of a bump a and b are linear, and c conjugates those
+//     germs, so the slopes of a and b at the ends of matched bumps agree.
+//   - On a bump, c is fixed by its slope 2^k at the lower end: pulling that
+//     linear germ along the orbits of a gives the only map conjugating a to
+//     b there.  It is in F exactly when it is linear again near the upper
+//     end, which shows once a fundamental domain of a and its image are
+//     inside the last linear pieces of a and b, and k matters only modulo
+//     the exponent of the slope of a at the lower end.
+//   - At a fixed point which is not dyadic, c cannot break, so its slope on
+//     the next bump is the one it ended the last with; such bumps are tried
+//     together.  Fixed intervals map to fixed intervals by any element of F.
+//
+// The elements of V have no such test here yet: their dynamics also permute
+// and cycle the pieces of the circle and the Cantor set, and a test for V
+// (as by Belk and Matucci's strand diagrams) is left for later.
+func ConjugateInF(a, b TreePair) (bool, TreePair, error) {
+	if 2 != len(a.domain.alphabet) || string(a.domain.alphabet) != string(b.domain.alphabet) {
+		return false, TreePair{}, errors.New("Conjugacy in F needs two tree pairs over one binary alphabet")
+	}
+	if !a.IsOrderPreserving() || !b.IsOrderPreserving() {
+		return false, TreePair{}, errors.New("Tree pair is not order preserving")
+	}
+	fa, fb := plOf(a), plOf(b)
+	partsA, partsB := fa.fixedParts(), fb.fixedParts()
+	if len(partsA) != len(partsB) {
+		return false, TreePair{}, nil
+	}
+	for ii, pa := range partsA {
+		pb := partsB[ii]
+		if (0 == pa.lo.Cmp(pa.hi)) != (0 == pb.lo.Cmp(pb.hi)) || isDyadic(pa.lo) != isDyadic(pb.lo) {
+			return false, TreePair{}, nil
+		}
+	}
+
+	bumps := make([]bump, len(partsA)-1)
+	for ii := range bumps {
+		bp := bump{p: partsA[ii].hi, q: partsA[ii+1].lo, pb: partsB[ii].hi, qb: partsB[ii+1].lo, A: fa, B: fb}
+		up := fa.at(midpoint(bp.p, bp.q)).Cmp(midpoint(bp.p, bp.q)) > 0
+		if up != (fb.at(midpoint(bp.pb, bp.qb)).Cmp(midpoint(bp.pb, bp.qb)) > 0) ||
+			0 != fa.slopeRight(bp.p).Cmp(fb.slopeRight(bp.pb)) || 0 != fa.slopeLeft(bp.q).Cmp(fb.slopeLeft(bp.qb)) {
+			return false, TreePair{}, nil
+		}
+		if up {
+			// follow the inverses, so that points move down to p.
+			bp.A, bp.B = fa.inverse(), fb.inverse()
+		}
+		bp.linked = 0 < ii && !isDyadic(bp.p)
+		bumps[ii] = bp
+	}
+
+	// solve each chain of bumps joined at fixed points which are not dyadic.
+	pieces := make([]plMap, len(bumps))
+	for start := 0; start < len(bumps); {
+		end, period := start+1, bumps[start].period()
+		for ; end < len(bumps) && bumps[end].linked; end++ {
+			period *= bumps[end].period()
+		}
+		found := false
+		for k0 := 0; k0 < period && !found; k0++ {
+			found = true
+			for ii, k := start, k0; ii < end && found; ii++ {
+				pieces[ii], k, found = bumps[ii].conjugator(k)
+			}
+		}
+		if !found {
+			return false, TreePair{}, nil
+		}
+		start = end
+	}
+
+	var h plMap
+	for ii, pa := range partsA {
+		pb := partsB[ii]
+		if 0 == pa.lo.Cmp(pa.hi) {
+			h.add(pa.lo, pb.lo)
+		} else {
+			dyadicMap(&h, pa.lo, pa.hi, pb.lo, pb.hi)
+		}
+		if ii < len(pieces) {
+			for k := range pieces[ii].xs {
+				h.add(pieces[ii].xs[k], pieces[ii].ys[k])
+			}
+		}
+	}
+	c, err := FromPLMap(a.domain.alphabet, h.breakpoints())
+	if nil != err {
+		return false, TreePair{}, err
+	}
+	return true, c, nil
+}
+
+// plMap is an increasing, continuous, piecewise-linear map of the interval
+// from xs[0] to its last entry, affine between the breakpoints xs, which go
+// to ys.
+type plMap struct {
+	xs, ys []*big.Rat
+}
+
+// plOf returns the map of [0,1] induced by the element t of F.
+func plOf(t TreePair) plMap {
+	var f plMap
+	for _, b := range t.ToPLMap() {
+		f.add(b.X, b.Y)
+	}
+	return f
+}
+
+// add appends the breakpoint x going to y, unless it is the last already.
+func (f *plMap) add(x, y *big.Rat) {
+	if last := len(f.xs) - 1; 0 <= last && 0 == f.xs[last].Cmp(x) {
+		return
+	}
+	f.xs = append(f.xs, x)
+	f.ys = append(f.ys, y)
+}
+
+// piece returns the index of the piece holding x, the last piece for the
+// right end.
+func (f plMap) piece(x *big.Rat) int {
+	ii := sort.Search(len(f.xs), func(i int) bool { return f.xs[i].Cmp(x) > 0 }) - 1
+	return max(0, min(ii, len(f.xs)-2))
+}
+
+func (f plMap) slope(ii int) *big.Rat {
+	rise := new(big.Rat).Sub(f.ys[ii+1], f.ys[ii])
+	return rise.Quo(rise, new(big.Rat).Sub(f.xs[ii+1], f.xs[ii]))
+}
+
+func (f plMap) at(x *big.Rat) *big.Rat {
+	ii := f.piece(x)
+	y := new(big.Rat).Sub(x, f.xs[ii])
+	return y.Mul(y, f.slope(ii)).Add(y, f.ys[ii])
+}
+
+func (f plMap) inverse() plMap {
+	return plMap{xs: f.ys, ys: f.xs}
+}
+
+// slopeRight and slopeLeft return the slope of f just after and just
+// before x.
+func (f plMap) slopeRight(x *big.Rat) *big.Rat {
+	return f.slope(f.piece(x))
+}
+
+func (f plMap) slopeLeft(x *big.Rat) *big.Rat {
+	ii := sort.Search(len(f.xs), func(i int) bool { return f.xs[i].Cmp(x) >= 0 }) - 1
+	return f.slope(max(0, ii))
+}
+
+// nextBreak and prevBreak return the nearest breakpoints after and before x.
+func (f plMap) nextBreak(x *big.Rat) *big.Rat {
+	return f.xs[sort.Search(len(f.xs), func(i int) bool { return f.xs[i].Cmp(x) > 0 })]
+}
+
+func (f plMap) prevBreak(x *big.Rat) *big.Rat {
+	return f.xs[sort.Search(len(f.xs), func(i int) bool { return f.xs[i].Cmp(x) >= 0 })-1]
+}
+
+// restrict returns f on [lo, hi].
+func (f plMap) restrict(lo, hi *big.Rat) plMap {
+	g := plMap{xs: []*big.Rat{lo}, ys: []*big.Rat{f.at(lo)}}
+	for ii, x := range f.xs {
+		if x.Cmp(lo) > 0 && x.Cmp(hi) < 0 {
+			g.add(x, f.ys[ii])
+		}
+	}
+	g.add(hi, f.at(hi))
+	return g
+}
+
+// then returns g after f, g being defined on the range of f.
+func (f plMap) then(g plMap) plMap {
+	inv := f.inverse()
+	xs := append([]*big.Rat(nil), f.xs...)
+	for _, y := range g.xs {
+		if y.Cmp(f.ys[0]) > 0 && y.Cmp(f.ys[len(f.ys)-1]) < 0 {
+			xs = append(xs, inv.at(y))
+		}
+	}
+	sort.Slice(xs, func(i, j int) bool { return xs[i].Cmp(xs[j]) < 0 })
+	var h plMap
+	for _, x := range xs {
+		h.add(x, g.at(f.at(x)))
+	}
+	return h
+}
+
+// affine reports whether f is a single affine piece.
+func (f plMap) affine() bool {
+	for ii := 1; ii+1 < len(f.xs); ii++ {
+		if 0 != f.slope(ii).Cmp(f.slope(0)) {
+			return false
+		}
+	}
+	return true
+}
+
+// breakpoints returns f as FromPLMap takes it, leaving out the points where
+// the slope does not change.
+func (f plMap) breakpoints() []Breakpoint {
+	var points []Breakpoint
+	for ii := 0; ii+1 < len(f.xs); ii++ {
+		s := f.slope(ii)
+		if last := len(points) - 1; 0 <= last && 0 == points[last].Slope.Cmp(s) {
+			continue
+		}
+		points = append(points, Breakpoint{X: f.xs[ii], Y: f.ys[ii], Slope: s})
+	}
+	return append(points, Breakpoint{X: f.xs[len(f.xs)-1], Y: f.ys[len(f.ys)-1]})
+}
+
+// fixedPart is a fixed point, lo = hi, or a fixed interval of a map.
+type fixedPart struct {
+	lo, hi *big.Rat
+}
+
+// fixedParts returns the fixed points and maximal fixed intervals of the map
+// f of [0,1], in order.
+func (f plMap) fixedParts() []fixedPart {
+	var parts []fixedPart
+	add := func(lo, hi *big.Rat) {
+		if last := len(parts) - 1; 0 <= last && parts[last].hi.Cmp(lo) >= 0 {
+			if parts[last].hi.Cmp(hi) < 0 {
+				parts[last].hi = hi
+			}
+			return
+		}
+		parts = append(parts, fixedPart{lo: lo, hi: hi})
+	}
+	for ii := 0; ii+1 < len(f.xs); ii++ {
+		d0 := new(big.Rat).Sub(f.ys[ii], f.xs[ii])
+		d1 := new(big.Rat).Sub(f.ys[ii+1], f.xs[ii+1])
+		switch {
+		case 0 == d0.Sign() && 0 == d1.Sign():
+			add(f.xs[ii], f.xs[ii+1])
+		case 0 == d0.Sign():
+			add(f.xs[ii], f.xs[ii])
+		case d0.Sign()*d1.Sign() < 0:
+			// f(x) - x is affine on the piece and vanishes at x.
+			x := new(big.Rat).Sub(f.xs[ii+1], f.xs[ii])
+			x.Mul(x, d0).Quo(x, new(big.Rat).Sub(d0, d1)).Add(x, f.xs[ii])
+			add(x, x)
+		}
+	}
+	last := f.xs[len(f.xs)-1]
+	add(last, last)
+	return parts
+}
+
+// bump is an open interval (p, q) moved by a, with its image (pb, qb) moved
+// by b, and A and B the maps, a and b or both their inverses, moving points
+// down towards p and pb.
+type bump struct {
+	p, q, pb, qb *big.Rat
+	A, B         plMap
+	linked       bool // p is not dyadic, so a conjugator cannot break there
+}
+
+// period returns the exponent of the slope of A at p, up to sign, modulo
+// which the slope of a conjugator at p matters.
+func (bp bump) period() int {
+	return max(log2Rat(bp.A.slopeRight(bp.p)), -log2Rat(bp.A.slopeRight(bp.p)))
+}
+
+// conjugator returns the conjugator on the bump with slope 2^k at p, as its
+// breakpoints inside (p, q), with the exponent of its slope at q, if it is
+// linear near q.
+func (bp bump) conjugator(k int) (plMap, int, bool) {
+	scale := pow2(k)
+	germ := func(x *big.Rat) *big.Rat {
+		y := new(big.Rat).Sub(x, bp.p)
+		return y.Mul(y, scale).Add(y, bp.pb)
+	}
+
+	// the germ conjugates below w, where A and, on its image, B are linear.
+	w := bp.A.nextBreak(bp.p)
+	wb := new(big.Rat).Sub(bp.B.nextBreak(bp.pb), bp.pb)
+	if wb.Quo(wb, scale).Add(wb, bp.p); wb.Cmp(w) < 0 {
+		w = wb
+	}
+	lo := dyadicBetween(bp.p, w)
+	low := bp.A.at(lo)
+	h := plMap{xs: []*big.Rat{low, lo}, ys: []*big.Rat{germ(low), germ(lo)}}
+	pieces := h
+
+	// pull h up along the orbits until a fundamental domain and its image lie
+	// in the last linear pieces of A and B.
+	up, down := bp.A.inverse(), bp.B.inverse()
+	r, rb := bp.A.prevBreak(bp.q), bp.B.prevBreak(bp.qb)
+	for {
+		hi := up.at(lo)
+		h = bp.A.restrict(lo, hi).then(h).then(down)
+		for ii := range h.xs {
+			pieces.add(h.xs[ii], h.ys[ii])
+		}
+		if lo.Cmp(r) > 0 && h.ys[0].Cmp(rb) > 0 {
+			break
+		}
+		lo = hi
+	}
+	if !h.affine() {
+		return plMap{}, 0, false
+	}
+	return pieces, log2Rat(h.slope(0)), true
+}
+
+// dyadicMap adds to h an element of F taking [lo, hi] onto [loB, hiB], all
+// dyadic: both are cut into standard dyadic intervals, the larger ones
+// halved until the counts agree, and matched in order.
+func dyadicMap(h *plMap, lo, hi, loB, hiB *big.Rat) {
+	from, to := dyadicCuts(lo, hi), dyadicCuts(loB, hiB)
+	for len(from) < len(to) {
+		from = halveWidest(from)
+	}
+	for len(to) < len(from) {
+		to = halveWidest(to)
+	}
+	for ii := range from {
+		h.add(from[ii], to[ii])
+	}
+}
+
+// dyadicCuts returns the ends of the standard dyadic intervals, greedily
+// the widest, tiling [lo, hi].
+func dyadicCuts(lo, hi *big.Rat) []*big.Rat {
+	cuts := []*big.Rat{lo}
+	for x := lo; x.Cmp(hi) < 0; {
+		width := big.NewRat(1, 1)
+		for !new(big.Rat).Quo(x, width).IsInt() || new(big.Rat).Add(x, width).Cmp(hi) > 0 {
+			width.Quo(width, big.NewRat(2, 1))
+		}
+		x = new(big.Rat).Add(x, width)
+		cuts = append(cuts, x)
+	}
+	return cuts
+}
+
+// halveWidest splits the widest interval between cuts in two.
+func halveWidest(cuts []*big.Rat) []*big.Rat {
+	widest := 0
+	for ii := 1; ii+1 < len(cuts); ii++ {
+		if new(big.Rat).Sub(cuts[ii+1], cuts[ii]).Cmp(new(big.Rat).Sub(cuts[widest+1], cuts[widest])) > 0 {
+			widest = ii
+		}
+	}
+	mid := midpoint(cuts[widest], cuts[widest+1])
+	return append(cuts[:widest+1], append([]*big.Rat{mid}, cuts[widest+1:]...)...)
+}
+
+// dyadicBetween returns a dyadic rational strictly between lo and hi.
+func dyadicBetween(lo, hi *big.Rat) *big.Rat {
+	for scale := big.NewInt(1); ; scale.Lsh(scale, 1) {
+		m := new(big.Int).Mul(lo.Num(), scale)
+		m.Quo(m, lo.Denom()).Add(m, big.NewInt(1))
+		if x := new(big.Rat).SetFrac(m, scale); x.Cmp(hi) < 0 {
+			return x
+		}
+	}
+}
+
+func midpoint(x, y *big.Rat) *big.Rat {
+	m := new(big.Rat).Add(x, y)
+	return m.Quo(m, big.NewRat(2, 1))
+}
+
+func isDyadic(x *big.Rat) bool {
+	return isNAdic(x, big.NewInt(2))
+}
+
+// pow2 returns 2^k.
+func pow2(k int) *big.Rat {
+	power := new(big.Int).Lsh(big.NewInt(1), uint(max(k, -k)))
+	if k < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), power)
+	}
+	return new(big.Rat).SetInt(power)
+}
+
+// log2Rat returns the exponent of the power of two x.
+func log2Rat(x *big.Rat) int {
+	if x.IsInt() {
+		return x.Num().BitLen() - 1
+	}
+	return 1 - x.Denom().BitLen()
+}
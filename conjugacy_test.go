@@ -0,0 +1,105 @@
+package prefcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestConjugateInF(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	x0, x1 := generatorF(t, 0), generatorF(t, 1)
+
+	// conjugates checks c is a conjugator from a to b.
+	conjugates := func(t *testing.T, a, b, c TreePair) {
+		t.Helper()
+		if got := mustCompose(t, mustCompose(t, c.Inverse(), a), c); !got.Equals(b) {
+			t.Errorf("conjugator %v takes %v to %v, not %v", c, a, got, b)
+		}
+	}
+
+	t.Run("Checking conjugate and non-conjugate pairs.", func(t *testing.T) {
+		for _, c := range []struct {
+			a, b TreePair
+			want bool
+		}{
+			{x0, x1, false},
+			{x0, x0.Inverse(), false},
+			{x0, mustCompose(t, x0, x0), false},
+			{x0, mustCompose(t, mustCompose(t, x1.Inverse(), x0), x1), true},
+			{x1, generatorF(t, 3), true},
+			{mustCompose(t, x0, x1), mustCompose(t, x1, x0), true},
+			{mustCompose(t, x1, generatorF(t, 2).Inverse()), mustCompose(t, x1, generatorF(t, 3).Inverse()), true},
+		} {
+			ok, conj, err := ConjugateInF(c.a, c.b)
+			if nil != err {
+				t.Fatalf("ConjugateInF(%v, %v): %v", c.a, c.b, err)
+			}
+			assertCorrectMessage(t, fmt.Sprint(ok), fmt.Sprint(c.want))
+			if ok {
+				conjugates(t, c.a, c.b, conj)
+			}
+		}
+	})
+
+	t.Run("Checking planted conjugates are found.", func(t *testing.T) {
+		g, err := NewCayleyGraph(x0, x1)
+		if nil != err {
+			t.Fatalf("NewCayleyGraph: %v", err)
+		}
+		elements := collectBall(g, 2)
+		for _, a := range elements {
+			for _, c := range elements {
+				b := mustCompose(t, mustCompose(t, c.Inverse(), a), c)
+				ok, conj, err := ConjugateInF(a, b)
+				if nil != err {
+					t.Fatalf("ConjugateInF(%v, %v): %v", a, b, err)
+				}
+				if !ok {
+					t.Errorf("%v and its conjugate %v by %v were not found conjugate", a, b, c)
+					continue
+				}
+				conjugates(t, a, b, conj)
+			}
+		}
+	})
+
+	t.Run("Checking answers agree with the abelian image.", func(t *testing.T) {
+		g, err := NewCayleyGraph(x0, x1)
+		if nil != err {
+			t.Fatalf("NewCayleyGraph: %v", err)
+		}
+		elements := collectBall(g, 2)
+		for _, a := range elements {
+			for _, b := range elements {
+				ok, conj, err := ConjugateInF(a, b)
+				if nil != err {
+					t.Fatalf("ConjugateInF(%v, %v): %v", a, b, err)
+				}
+				p, q := a.AbelianImage()
+				r, s := b.AbelianImage()
+				if ok && (p != r || q != s) {
+					t.Errorf("%v and %v have different abelian images but were found conjugate", a, b)
+				}
+				if ok {
+					conjugates(t, a, b, conj)
+				}
+			}
+		}
+	})
+
+	t.Run("Checking pairs outside F are rejected.", func(t *testing.T) {
+		swap := pairFromMaps(t, map[string]int{"0": 0, "1": 1}, map[string]int{"0": 1, "1": 0})
+		_, _, err := ConjugateInF(x0, swap)
+		assertCorrectMessage(t, fmt.Sprint(err), "Tree pair is not order preserving")
+		id, _ := IdentityPair([]rune("abc"))
+		_, _, err = ConjugateInF(id, id)
+		assertCorrectMessage(t, fmt.Sprint(err), "Conjugacy in F needs two tree pairs over one binary alphabet")
+	})
+}
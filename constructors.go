@@ -0,0 +1,129 @@
+package prefcode
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+)
+
+// NewPrefCodeFromLeaves returns the code over alphabet whose leaves are
+// leaves, labelled in dictionary order, after checking they form a complete
+// prefix code: no word is a prefix of another and the Kraft sum is 1.  Use
+// it rather than SetCode, which trusts its input.
+func NewPrefCodeFromLeaves(alphabet []rune, leaves []string) (PrefCode, error) {
+	alpha := make([]rune, len(alphabet))
+	copy(alpha, alphabet)
+	pc, err := NewPrefCodeAlphaRunes(alpha)
+	if nil != err {
+		return nil, err
+	}
+	sorted, err := checkCompleteLeaves(alpha, leaves)
+	if nil != err {
+		return nil, err
+	}
+	pc.code = make(map[string]int, len(sorted))
+	for k, leaf := range sorted {
+		pc.code[leaf] = k
+	}
+	pc.reindex()
+	return pc, nil
+}
+
+// checkCompleteLeaves returns leaves in dictionary order, the empty word as
+// EmptyString, or an error saying why they are not a complete prefix code.
+func checkCompleteLeaves(alpha []rune, leaves []string) ([]string, error) {
+	sorted, err := checkCompletePaths(alpha, asPaths(leaves))
+	if nil != err {
+		return nil, err
+	}
+	if 1 == len(sorted) {
+		sorted[0] = EmptyString
+	}
+	return sorted, nil
+}
+
+// checkCompletePaths is checkCompleteLeaves for paths, "" alone being the
+// empty word, which it sorts in place.
+func checkCompletePaths(alpha []rune, paths []string) ([]string, error) {
+	sorted, err := sortedPaths(paths, alpha)
+	if nil != err {
+		return nil, err
+	}
+	if a, b, ok := prefixPair(sorted); ok {
+		return nil, errors.New("Word " + strconv.Quote(a) + " is a prefix of " + strconv.Quote(b))
+	}
+	if sum := kraftSum(sorted, len(alpha)); 0 != sum.Cmp(big.NewRat(1, 1)) {
+		return nil, errors.New("Kraft sum is " + sum.RatString() + ", not 1, so the code is not complete")
+	}
+	return sorted, nil
+}
+
+// checkAntichain returns words in dictionary order, or an error if one is a
+// prefix of another or uses letters outside alpha.
+func checkAntichain(alpha []rune, words []string) ([]string, error) {
+	sorted, err := sortedWords(words, alpha)
+	if nil != err {
+		return nil, err
+	}
+	if a, b, ok := prefixPair(sorted); ok {
+		return nil, errors.New("Word " + strconv.Quote(a) + " is a prefix of " + strconv.Quote(b))
+	}
+	return sorted, nil
+}
+
+// CompletePrefixSet returns the least complete code over alphabet containing
+// words as leaves, labelled in dictionary order, after checking the words are
+// an antichain.  The extra leaves are the children, off the paths to words,
+// of the carets on those paths.  No words at all give the trivial code.
+func CompletePrefixSet(alphabet []rune, words []string) (PrefCode, error) {
+	alpha := make([]rune, len(alphabet))
+	copy(alpha, alphabet)
+	if _, err := NewPrefCodeAlphaRunes(alpha); nil != err {
+		return nil, err
+	}
+	sorted, err := checkAntichain(alpha, words)
+	if nil != err {
+		return nil, err
+	}
+	nodes := make(map[string]bool)
+	for _, w := range sorted {
+		for ii := range w {
+			nodes[w[:ii]] = true
+		}
+	}
+	return codeFromInternalNodes(alpha, false, nodes), nil
+}
+
+// NewPrefCodeFromMap returns a code over alphabet with a copy of m as its
+// code, after checking the keys form a complete prefix code (as for
+// NewPrefCodeFromLeaves) and the labels are a permutation of 0 ... n-1.  The
+// key "" is read as EmptyString.
+func NewPrefCodeFromMap(alphabet []rune, m map[string]int) (PrefCode, error) {
+	alpha := make([]rune, len(alphabet))
+	copy(alpha, alphabet)
+	pc, err := NewPrefCodeAlphaRunes(alpha)
+	if nil != err {
+		return nil, err
+	}
+	leaves := make([]string, 0, len(m))
+	seen := make([]bool, len(m))
+	for leaf, label := range m {
+		if label < 0 || label >= len(m) || seen[label] {
+			return nil, errors.New("Labels are not a permutation of 0 ... " + strconv.Itoa(len(m)-1) + " at " + strconv.Quote(leaf))
+		}
+		seen[label] = true
+		leaves = append(leaves, leaf)
+	}
+	if _, err := checkCompleteLeaves(alpha, leaves); nil != err {
+		return nil, err
+	}
+	pc.code = make(map[string]int, len(m))
+	for leaf, label := range m {
+		if "" == leaf {
+			leaf = EmptyString
+		}
+		pc.code[leaf] = label
+	}
+	pc.reindex()
+	return pc, nil
+}
@@ -0,0 +1,100 @@
+package prefcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestConstructors(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking NewPrefCodeFromLeaves.", func(t *testing.T) {
+		pc, err := NewPrefCodeFromLeaves([]rune("01"), []string{"11", "0", "10"})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeFromLeaves() on a complete code.")
+		}
+		assertCorrectMessage(t, pc.String(), "[0 0], [10 1], [11 2]")
+		pc.ExpandAt("0")
+		assertCorrectMessage(t, pc.String(), "[00 0], [01 1], [10 2], [11 3]")
+
+		pc, _ = NewPrefCodeFromLeaves([]rune("01"), []string{EmptyString})
+		assertCorrectMessage(t, pc.String(), "[𝛆 0]")
+		pc, _ = NewPrefCodeFromLeaves([]rune("01"), []string{""})
+		assertCorrectMessage(t, pc.String(), "[𝛆 0]")
+
+		for _, test := range []struct {
+			alpha  string
+			leaves []string
+			want   string
+		}{
+			{"01", []string{"0", "10"}, "Kraft sum is 3/4, not 1, so the code is not complete"},
+			{"01", nil, "Kraft sum is 0, not 1, so the code is not complete"},
+			{"01", []string{"0", "01", "1"}, `Word "0" is a prefix of "01"`},
+			{"01", []string{"0", "1", "1"}, `Word "1" is a prefix of "1"`},
+			{"01", []string{"0", "12"}, `Word "12" uses letter '2' outside the alphabet`},
+			{"", []string{"0"}, "Empty Alphabet forbidden"},
+		} {
+			_, err := NewPrefCodeFromLeaves([]rune(test.alpha), test.leaves)
+			assertCorrectMessage(t, fmt.Sprint(err), test.want)
+		}
+	})
+
+	t.Run("Checking CompletePrefixSet.", func(t *testing.T) {
+		pc, err := CompletePrefixSet([]rune("01"), []string{"010", "11"})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "CompletePrefixSet() on an antichain.")
+		}
+		assertCorrectMessage(t, pc.String(), "[00 0], [010 1], [011 2], [10 3], [11 4]")
+		pc, _ = CompletePrefixSet([]rune("abc"), []string{"b"})
+		assertCorrectMessage(t, pc.String(), "[a 0], [b 1], [c 2]")
+		pc, _ = CompletePrefixSet([]rune("01"), []string{"0", "10", "11"})
+		assertCorrectMessage(t, pc.String(), "[0 0], [10 1], [11 2]")
+		pc, _ = CompletePrefixSet([]rune("01"), nil)
+		assertCorrectMessage(t, pc.String(), "[𝛆 0]")
+		pc, _ = CompletePrefixSet([]rune("01"), []string{EmptyString})
+		assertCorrectMessage(t, pc.String(), "[𝛆 0]")
+
+		_, err = CompletePrefixSet([]rune("01"), []string{"1", "10"})
+		assertCorrectMessage(t, fmt.Sprint(err), `Word "1" is a prefix of "10"`)
+		_, err = CompletePrefixSet([]rune("01"), []string{"a"})
+		assertCorrectMessage(t, fmt.Sprint(err), `Word "a" uses letter 'a' outside the alphabet`)
+	})
+
+	t.Run("Checking NewPrefCodeFromMap.", func(t *testing.T) {
+		m := map[string]int{"0": 2, "10": 0, "11": 1}
+		pc, err := NewPrefCodeFromMap([]rune("01"), m)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeFromMap() on a valid map.")
+		}
+		assertCorrectMessage(t, pc.String(), "[0 2], [10 0], [11 1]")
+		m["0"] = 7
+		assertCorrectMessage(t, pc.String(), "[0 2], [10 0], [11 1]")
+		pc.ExpandAt("0")
+		assertCorrectMessage(t, pc.String(), "[00 2], [01 3], [10 0], [11 1]")
+		pc, _ = NewPrefCodeFromMap([]rune("01"), map[string]int{"": 0})
+		assertCorrectMessage(t, pc.String(), "[𝛆 0]")
+
+		for _, test := range []struct {
+			m    map[string]int
+			want string
+		}{
+			{map[string]int{"0": 0, "10": 1}, "Kraft sum is 3/4, not 1, so the code is not complete"},
+			{map[string]int{"0": 0, "1": 1, "10": 2}, `Word "1" is a prefix of "10"`},
+			{map[string]int{"0": 1, "1": 1}, `Labels are not a permutation of 0 ... 1 at "`},
+			{map[string]int{"0": 0, "1": 2}, `Labels are not a permutation of 0 ... 1 at "1"`},
+		} {
+			_, err := NewPrefCodeFromMap([]rune("01"), test.m)
+			got := fmt.Sprint(err)
+			if len(got) > len(test.want) {
+				got = got[:len(test.want)]
+			}
+			assertCorrectMessage(t, got, test.want)
+		}
+	})
+}
@@ -0,0 +1,19 @@
+package prefcode
+
+import "math/big"
+
+// CountCodes returns the number of complete prefix codes over an alphabet of
+// size alphabetSize having exactly carets carets.  This is the Fuss–Catalan
+// number  C(n*k, k) / ((n-1)*k + 1)  with n the alphabet size and k the caret
+// count.  Nonsensical requests (empty alphabet, negative carets) count zero codes.
+func CountCodes(alphabetSize, carets int) *big.Int {
+	count := new(big.Int)
+	if alphabetSize < 1 || carets < 0 {
+		return count
+	}
+
+	n := int64(alphabetSize)
+	k := int64(carets)
+	count.Binomial(n*k, k)
+	return count.Quo(count, big.NewInt((n-1)*k+1))
+}
@@ -0,0 +1,38 @@
+package prefcode
+
+import (
+	"testing"
+)
+
+func TestCountCodes(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	// binary codes are counted by the Catalan numbers.
+	t.Run("Checking CountCodes binary.", func(t *testing.T) {
+		got := ""
+		for k := 0; k < 8; k++ {
+			got += CountCodes(2, k).String() + " "
+		}
+		assertCorrectMessage(t, got, "1 1 2 5 14 42 132 429 ")
+	})
+
+	// ternary codes are counted by the Fuss–Catalan numbers 1, 1, 3, 12, 55, ...
+	t.Run("Checking CountCodes ternary.", func(t *testing.T) {
+		got := ""
+		for k := 0; k < 6; k++ {
+			got += CountCodes(3, k).String() + " "
+		}
+		assertCorrectMessage(t, got, "1 1 3 12 55 273 ")
+	})
+
+	t.Run("Checking CountCodes bad input.", func(t *testing.T) {
+		assertCorrectMessage(t, CountCodes(0, 3).String(), "0")
+		assertCorrectMessage(t, CountCodes(2, -1).String(), "0")
+	})
+}
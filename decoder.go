@@ -0,0 +1,174 @@
+package prefcode
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// DecodeError reports where a stream stopped being decodable by a Decoder.
+type DecodeError struct {
+	Offset int64  // byte offset at which the offending codeword started
+	Word   string // the runes read for that codeword before failing
+	Err    error  // ErrNotInCode or io.ErrUnexpectedEOF
+}
+
+func (e *DecodeError) Error() string {
+	return "prefcode: cannot decode " + strconv.Quote(e.Word) + " at byte " + strconv.FormatInt(e.Offset, 10) + ": " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ErrNotInCode is wrapped by a DecodeError when a word leaves the tree of the
+// code, that is, it is neither a leaf nor a prefix of one.
+var ErrNotInCode = errors.New("word is not a prefix of any codeword")
+
+// Decoder reads runes from an io.Reader and walks them down the tree of a
+// prefix code, emitting the label of each leaf reached.
+type Decoder struct {
+	r      *bufio.Reader
+	code   map[string]int
+	inner  map[string]bool // proper prefixes of the leaves
+	offset int64
+}
+
+// NewDecoder returns a Decoder for the codewords of pc read from r.  The code is
+// copied, so later changes to pc do not affect the Decoder.
+func NewDecoder(pc PrefCode, r io.Reader) *Decoder {
+	d := &Decoder{r: bufio.NewReader(r), inner: internalNodes(pc)}
+	d.code = make(map[string]int, pc.Size())
+	for leaf, label := range pc.Code() {
+		d.code[leaf] = label
+	}
+	return d
+}
+
+// Decode reads the next codeword and returns its label.  It returns io.EOF
+// when the stream ends cleanly between codewords, and a *DecodeError when the
+// stream is not a concatenation of codewords.  The trivial code has the empty
+// codeword only, which cannot delimit anything, so it decodes nothing.
+func (d *Decoder) Decode() (int, error) {
+	start := d.offset
+	word := ""
+	for {
+		r, size, err := d.r.ReadRune()
+		if err == io.EOF {
+			if "" == word {
+				return FAILURE, io.EOF
+			}
+			return FAILURE, &DecodeError{Offset: start, Word: word, Err: io.ErrUnexpectedEOF}
+		}
+		if err != nil {
+			return FAILURE, err
+		}
+		d.offset += int64(size)
+		word += string(r)
+
+		if label, ok := d.code[word]; ok {
+			return label, nil
+		}
+		if !d.inner[word] {
+			return FAILURE, &DecodeError{Offset: start, Word: word, Err: ErrNotInCode}
+		}
+	}
+}
+
+// DecodeAll decodes until the end of the stream, returning the labels read.
+// On failure the labels decoded so far are returned with the error.
+func (d *Decoder) DecodeAll() ([]int, error) {
+	var labels []int
+	for {
+		label, err := d.Decode()
+		if err == io.EOF {
+			return labels, nil
+		}
+		if err != nil {
+			return labels, err
+		}
+		labels = append(labels, label)
+	}
+}
+
+// TokenizeError reports where a string stopped being a concatenation of
+// codewords in Tokenize.
+type TokenizeError struct {
+	Pos       int    // byte offset of the first codeword that failed
+	Remainder string // the untokenized tail of the input, from Pos on
+}
+
+func (e *TokenizeError) Error() string {
+	return "prefcode: no codeword at byte " + strconv.Itoa(e.Pos) + " of remainder " + strconv.Quote(e.Remainder)
+}
+
+// Tokenize splits s into consecutive codewords of p (prefix decoding).  Since p
+// is a prefix code the split is unique when it exists.  Otherwise the
+// codewords found so far are returned along with a *TokenizeError.
+func (p *prefixCode) Tokenize(s string) ([]string, error) {
+	inner := internalNodes(p)
+	var tokens []string
+
+	start := 0
+	for start < len(s) {
+		end := start
+		found := false
+		for end < len(s) {
+			_, size := utf8.DecodeRuneInString(s[end:])
+			end += size
+			if _, ok := p.code[s[start:end]]; ok {
+				found = true
+				break
+			}
+			if !inner[s[start:end]] {
+				break
+			}
+		}
+		if !found {
+			return tokens, &TokenizeError{Pos: start, Remainder: s[start:]}
+		}
+		tokens = append(tokens, s[start:end])
+		start = end
+	}
+	return tokens, nil
+}
+
+// SplitFunc returns a bufio.SplitFunc scanning a stream into the codewords of
+// p, for use with a bufio.Scanner.  A stream that is not a concatenation of
+// codewords stops the Scanner with a *DecodeError.  The returned function
+// counts bytes to report offsets, so use a fresh one for each Scanner.
+func (p *prefixCode) SplitFunc() bufio.SplitFunc {
+	inner := internalNodes(p)
+	code := make(map[string]bool, len(p.code))
+	for leaf := range p.code {
+		code[leaf] = true
+	}
+	var offset int64
+
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if atEOF && 0 == len(data) {
+			return 0, nil, nil
+		}
+		end := 0
+		for end < len(data) {
+			if !atEOF && !utf8.FullRune(data[end:]) {
+				return 0, nil, nil // wait for the rest of the rune
+			}
+			_, size := utf8.DecodeRune(data[end:])
+			end += size
+			if code[string(data[:end])] {
+				offset += int64(end)
+				return end, data[:end], nil
+			}
+			if !inner[string(data[:end])] {
+				return 0, nil, &DecodeError{Offset: offset, Word: string(data[:end]), Err: ErrNotInCode}
+			}
+		}
+		if atEOF {
+			return 0, nil, &DecodeError{Offset: offset, Word: string(data), Err: io.ErrUnexpectedEOF}
+		}
+		return 0, nil, nil
+	}
+}
@@ -0,0 +1,120 @@
+package prefcode
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking Decoder round trip.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking Decoder.")
+		}
+		baseCode.ExpandAt("10")
+
+		dec := NewDecoder(baseCode, strings.NewReader("01011000"))
+		labels, err := dec.DecodeAll()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "DecodeAll in test checking Decoder.")
+		}
+		assertCorrectMessage(t, fmt.Sprint(labels), "[0 2 1 0]")
+	})
+
+	t.Run("Checking Decoder errors.", func(t *testing.T) {
+		uniCode, err := NewUniformCode([]rune("日本"), 2)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewUniformCode in test checking Decoder errors.")
+		}
+
+		// each codeword is 6 bytes; the third starts at byte 12.
+		dec := NewDecoder(uniCode, strings.NewReader("日本本本日x"))
+		labels, err := dec.DecodeAll()
+		assertCorrectMessage(t, fmt.Sprint(labels), "[1 3]")
+		var decErr *DecodeError
+		if !errors.As(err, &decErr) {
+			t.Fatalf("got %v want a *DecodeError", err)
+		}
+		assertCorrectMessage(t, fmt.Sprint(decErr.Offset), "12")
+		assertCorrectMessage(t, decErr.Word, "日x")
+		if !errors.Is(err, ErrNotInCode) {
+			assertCorrectMessage(t, err.Error(), "wrapping ErrNotInCode")
+		}
+
+		dec = NewDecoder(uniCode, strings.NewReader("日本本"))
+		_, err = dec.DecodeAll()
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			assertCorrectMessage(t, fmt.Sprint(err), "wrapping io.ErrUnexpectedEOF")
+		}
+	})
+	t.Run("Checking Tokenize.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking Tokenize.")
+		}
+		baseCode.ExpandAt("10")
+
+		tokens, err := baseCode.Tokenize("01011000")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "Tokenize in test checking Tokenize.")
+		}
+		assertCorrectMessage(t, strings.Join(tokens, " "), "0 101 100 0")
+
+		tokens, err = baseCode.Tokenize("0111012")
+		assertCorrectMessage(t, strings.Join(tokens, " "), "0 11 101")
+		var tokErr *TokenizeError
+		if !errors.As(err, &tokErr) {
+			t.Fatalf("got %v want a *TokenizeError", err)
+		}
+		assertCorrectMessage(t, fmt.Sprint(tokErr.Pos), "6")
+		assertCorrectMessage(t, tokErr.Remainder, "2")
+
+		// running out mid-codeword.
+		_, err = baseCode.Tokenize("010")
+		if !errors.As(err, &tokErr) {
+			t.Fatalf("got %v want a *TokenizeError", err)
+		}
+		assertCorrectMessage(t, tokErr.Remainder, "10")
+	})
+	t.Run("Checking SplitFunc.", func(t *testing.T) {
+		uniCode, err := NewUniformCode([]rune("日本"), 2)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewUniformCode in test checking SplitFunc.")
+		}
+
+		// a tiny buffer forces runes to be split across reads.
+		scanner := bufio.NewScanner(strings.NewReader("日本本本日日"))
+		scanner.Buffer(make([]byte, 4), 64)
+		scanner.Split(uniCode.SplitFunc())
+		var got []string
+		for scanner.Scan() {
+			got = append(got, scanner.Text())
+		}
+		if nil != scanner.Err() {
+			assertCorrectMessage(t, scanner.Err().Error(), "no error")
+		}
+		assertCorrectMessage(t, strings.Join(got, " "), "日本 本本 日日")
+
+		scanner = bufio.NewScanner(strings.NewReader("日本本"))
+		scanner.Split(uniCode.SplitFunc())
+		for scanner.Scan() {
+		}
+		var decErr *DecodeError
+		if !errors.As(scanner.Err(), &decErr) {
+			t.Fatalf("got %v want a *DecodeError", scanner.Err())
+		}
+		assertCorrectMessage(t, fmt.Sprint(decErr.Offset), "6")
+	})
+}
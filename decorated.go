@@ -0,0 +1,235 @@
+package prefcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Group is a finite group with elements of type E, supplied by the user to
+// decorate the leaves of a DecoratedPair.  Mul(a, b) is the product a then b.
+// Elements lists the whole group; it is only used to decide whether a caret
+// may be reduced under a PushRule other than copying.
+type Group[E any] interface {
+	Identity() E
+	Mul(a, b E) E
+	Inverse(a E) E
+	Equal(a, b E) bool
+	Elements() []E
+}
+
+// PushRule gives the decoration of the child along letter of a leaf decorated
+// g, when the leaf is expanded.  A nil PushRule copies g to every child, which
+// gives the restricted wreath product of the group with V.
+type PushRule[E any] func(g E, letter rune) E
+
+// DecoratedPair is a TreePair whose domain leaves carry elements of a finite
+// group, the data of wreath-product style and Brin–Thompson like groups.
+// The decoration of the domain leaf labelled ii is decor[ii], so, as in
+// LabeledCode, decorations ride on the int labels and are spliced with them.
+//
+// A word w below the domain leaf d is taken to (Apply(w), decoration of d),
+// and composition multiplies decorations along the way: the decoration of
+// t.Compose(q) at d is that of t at d times that of q at the image of d.
+type DecoratedPair[E any] struct {
+	pair  TreePair
+	group Group[E]
+	push  PushRule[E]
+	decor []E
+}
+
+// NewDecoratedPair returns pair with every domain leaf decorated by the
+// identity of group, pushing decorations down by push (nil to copy).
+func NewDecoratedPair[E any](pair TreePair, group Group[E], push PushRule[E]) (*DecoratedPair[E], error) {
+	if nil == pair.domain {
+		return nil, errors.New("Tree pair is not initialised")
+	}
+	if nil == group {
+		return nil, errors.New("Group is nil")
+	}
+	d := &DecoratedPair[E]{pair: pair.clone(), group: group, push: push, decor: make([]E, pair.Size())}
+	for ii := range d.decor {
+		d.decor[ii] = group.Identity()
+	}
+	return d, nil
+}
+
+// Pair returns the underlying tree pair, forgetting the decorations.
+func (d *DecoratedPair[E]) Pair() TreePair {
+	return d.pair.clone()
+}
+
+// Decoration returns the decoration of the domain leaf, if leaf is one.
+func (d *DecoratedPair[E]) Decoration(leaf string) (E, bool) {
+	ii := d.pair.domain.LabelAtLeaf(leaf)
+	if FAILURE == ii {
+		var zero E
+		return zero, false
+	}
+	return d.decor[ii], true
+}
+
+// SetDecoration decorates the domain leaf by g, reporting whether leaf is a
+// domain leaf.
+func (d *DecoratedPair[E]) SetDecoration(leaf string, g E) bool {
+	ii := d.pair.domain.LabelAtLeaf(leaf)
+	if FAILURE == ii {
+		return false
+	}
+	d.decor[ii] = g
+	return true
+}
+
+// ExpandAt expands the domain leaf and its image, the new domain leaves
+// taking the decoration of leaf pushed down by the rule.  It reports false if
+// leaf is not a domain leaf.
+func (d *DecoratedPair[E]) ExpandAt(leaf string) bool {
+	if !d.pair.domain.IsLeaf(leaf) {
+		return false
+	}
+	d.expandAt(leaf)
+	return true
+}
+
+func (d *DecoratedPair[E]) expandAt(leaf string) {
+	g := d.decor[d.pair.domain.LabelAtLeaf(leaf)]
+	label := d.pair.expandAt(leaf)
+	n := len(d.pair.domain.alphabet)
+	pushed := make([]E, n)
+	for jj := range pushed {
+		child := []rune(d.pair.domain.LeafAtLabel(label + jj))
+		pushed[jj] = d.pushDown(g, child[len(child)-1])
+	}
+	decor := make([]E, 0, len(d.decor)+n-1)
+	decor = append(decor, d.decor[:label]...)
+	decor = append(decor, pushed...)
+	d.decor = append(decor, d.decor[label+1:]...)
+}
+
+func (d *DecoratedPair[E]) pushDown(g E, letter rune) E {
+	if nil == d.push {
+		return g
+	}
+	return d.push(g, letter)
+}
+
+// pullUp returns the decoration which pushes down to children, letter by
+// letter in alphabet order, if there is one.
+func (d *DecoratedPair[E]) pullUp(children []E) (E, bool) {
+	candidates := []E{children[0]}
+	if nil != d.push {
+		candidates = d.group.Elements()
+	}
+	for _, g := range candidates {
+		ok := true
+		for ii, r := range d.pair.domain.alphabet {
+			if !d.group.Equal(d.pushDown(g, r), children[ii]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return g, true
+		}
+	}
+	var zero E
+	return zero, false
+}
+
+// Inverse returns the inverse: the pair inverted, each decoration inverted.
+func (d *DecoratedPair[E]) Inverse() *DecoratedPair[E] {
+	inv := &DecoratedPair[E]{pair: d.pair.Inverse(), group: d.group, push: d.push, decor: make([]E, len(d.decor))}
+	for ii, g := range d.decor {
+		inv.decor[ii] = d.group.Inverse(g)
+	}
+	return inv
+}
+
+// Compose returns d followed by q, in reduced form, multiplying decorations:
+// the result decorates a domain leaf by that of d times that of q at its
+// image.  The two should share group and rule.
+func (d *DecoratedPair[E]) Compose(q *DecoratedPair[E]) (*DecoratedPair[E], error) {
+	if string(d.pair.domain.alphabet) != string(q.pair.domain.alphabet) {
+		return nil, errors.New("Tree pairs have different alphabets")
+	}
+	a, b := d.clone(), q.clone()
+	refineTogether(a.pair, b.pair, a.expandAt, b.expandAt)
+	c := &DecoratedPair[E]{pair: composeRefined(a.pair, b.pair), group: d.group, push: d.push, decor: make([]E, len(a.decor))}
+	for ii := range c.decor {
+		mid := a.pair.rng.LeafAtLabel(ii)
+		c.decor[ii] = d.group.Mul(a.decor[ii], b.decor[b.pair.domain.LabelAtLeaf(mid)])
+	}
+	c.Reduce()
+	return c, nil
+}
+
+// Reduce reduces the pair in place, as TreePair.Reduce does, except that a
+// caret only goes if the decorations of its children are those pushed down
+// from a single decoration, which the new leaf takes.
+func (d *DecoratedPair[E]) Reduce() {
+	d.pair.reduceWhere(func(labels []int) bool {
+		children := make([]E, len(labels))
+		least := labels[0]
+		for ii, label := range labels {
+			children[ii] = d.decor[label]
+			least = min(least, label)
+		}
+		g, ok := d.pullUp(children)
+		if !ok {
+			return false
+		}
+		gone := make(map[int]bool, len(labels))
+		for _, label := range labels {
+			gone[label] = label != least
+		}
+		d.decor[least] = g
+		decor := d.decor[:0]
+		for ii, h := range d.decor {
+			if !gone[ii] {
+				decor = append(decor, h)
+			}
+		}
+		d.decor = decor
+		return true
+	})
+	perm := d.pair.normalize()
+	decor := make([]E, len(d.decor))
+	for ii, g := range d.decor {
+		decor[perm[ii]] = g
+	}
+	d.decor = decor
+}
+
+// Equals reports whether d and q are the same decorated element.
+func (d *DecoratedPair[E]) Equals(q *DecoratedPair[E]) bool {
+	a, b := d.clone(), q.clone()
+	a.Reduce()
+	b.Reduce()
+	if a.pair.String() != b.pair.String() {
+		return false
+	}
+	for ii := range a.decor {
+		if !d.group.Equal(a.decor[ii], b.decor[ii]) {
+			return false
+		}
+	}
+	return true
+}
+
+// String prints the domain with its decorations, then the range.
+func (d *DecoratedPair[E]) String() string {
+	var sb strings.Builder
+	for k, leaf := range d.pair.domain.sortedKeys() {
+		if 0 < k {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "[%s %d %v]", leaf, d.pair.domain.LabelAtLeaf(leaf), d.decor[d.pair.domain.LabelAtLeaf(leaf)])
+	}
+	return sb.String() + " -> " + d.pair.rng.String()
+}
+
+func (d *DecoratedPair[E]) clone() *DecoratedPair[E] {
+	c := &DecoratedPair[E]{pair: d.pair.clone(), group: d.group, push: d.push, decor: make([]E, len(d.decor))}
+	copy(c.decor, d.decor)
+	return c
+}
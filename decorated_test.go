@@ -0,0 +1,85 @@
+package prefcode
+
+import (
+	"strconv"
+	"testing"
+)
+
+// cyclic is Z/n under addition.
+type cyclic int
+
+func (c cyclic) Identity() int       { return 0 }
+func (c cyclic) Mul(a, b int) int    { return (a + b) % int(c) }
+func (c cyclic) Inverse(a int) int   { return (int(c) - a) % int(c) }
+func (c cyclic) Equal(a, b int) bool { return a == b }
+func (c cyclic) Elements() []int {
+	elements := make([]int, int(c))
+	for ii := range elements {
+		elements[ii] = ii
+	}
+	return elements
+}
+
+func TestDecorated(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	x0 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "11": 2}, map[string]int{"00": 0, "01": 1, "1": 2})
+
+	t.Run("Checking decorations are copied down and multiplied.", func(t *testing.T) {
+		d, err := NewDecoratedPair[int](x0, cyclic(3), nil)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewDecoratedPair() in test checking copied decorations.")
+		}
+		d.SetDecoration("0", 1)
+		d.SetDecoration("11", 2)
+		assertCorrectMessage(t, strconv.FormatBool(d.SetDecoration("1", 2)), "false")
+		d.ExpandAt("0")
+		assertCorrectMessage(t, d.String(), "[00 0 1], [01 1 1], [10 2 0], [11 3 2] -> [000 0], [001 1], [01 2], [1 3]")
+
+		sq, _ := d.Compose(d)
+		// 0 goes to 00, decorated 1 then 1; 10 goes to 01, decorated 0 then 1.
+		g, _ := sq.Decoration("0")
+		assertCorrectMessage(t, strconv.Itoa(g), "2")
+		assertCorrectMessage(t, sq.String(), "[0 0 2], [10 1 1], [110 2 2], [111 3 1] -> [000 0], [001 1], [01 2], [1 3]")
+
+		id, _ := d.Compose(d.Inverse())
+		assertCorrectMessage(t, id.String(), "[𝛆 0 0] -> [𝛆 0]")
+
+		d.Reduce()
+		assertCorrectMessage(t, d.String(), "[0 0 1], [10 1 0], [11 2 2] -> [00 0], [01 1], [1 2]")
+	})
+
+	t.Run("Checking a push rule acting by the letter.", func(t *testing.T) {
+		shift := func(g int, letter rune) int { return (g + int(letter-'0')) % 3 }
+		d, _ := NewDecoratedPair[int](x0, cyclic(3), shift)
+		d.SetDecoration("11", 1)
+		d.ExpandAt("11")
+		assertCorrectMessage(t, d.String(), "[0 0 0], [10 1 0], [110 2 1], [111 3 2] -> [00 0], [01 1], [10 2], [11 3]")
+		before := d.String()
+		d.Reduce()
+		assertCorrectMessage(t, d.String(), "[0 0 0], [10 1 0], [11 2 1] -> [00 0], [01 1], [1 2]")
+
+		// decorations which are not pushed down from one element stay put.
+		d.SetDecoration("0", 2)
+		d.ExpandAt("0")
+		d.SetDecoration("01", 2)
+		d.Reduce()
+		assertCorrectMessage(t, d.String(), "[00 0 2], [01 1 2], [10 2 0], [11 3 1] -> [000 0], [001 1], [01 2], [1 3]")
+		assertCorrectMessage(t, strconv.FormatBool(before == d.String()), "false")
+	})
+
+	t.Run("Checking Equals.", func(t *testing.T) {
+		a, _ := NewDecoratedPair[int](x0, cyclic(2), nil)
+		b, _ := NewDecoratedPair[int](x0, cyclic(2), nil)
+		b.ExpandAt("10")
+		assertCorrectMessage(t, strconv.FormatBool(a.Equals(b)), "true")
+		b.SetDecoration("100", 1)
+		assertCorrectMessage(t, strconv.FormatBool(a.Equals(b)), "false")
+	})
+}
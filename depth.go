@@ -0,0 +1,71 @@
+package prefcode
+
+// Depths are measured in runes (letters), not bytes, and the empty word
+// EmptyString of the trivial code has depth 0.
+
+// DepthOf returns the depth of leaf, or the FAILURE global constant if leaf
+// is not in the code.
+func (p *prefixCode) DepthOf(leaf string) int {
+	if _, ok := p.code[leaf]; !ok {
+		return FAILURE
+	}
+	return wordLen(leaf)
+}
+
+// MaxDepth returns the depth of the deepest leaf.
+func (p *prefixCode) MaxDepth() int {
+	maxDepth := 0
+	for leaf := range p.code {
+		if d := wordLen(leaf); d > maxDepth {
+			maxDepth = d
+		}
+	}
+	return maxDepth
+}
+
+// MinDepth returns the depth of the shallowest leaf.
+func (p *prefixCode) MinDepth() int {
+	minDepth := FAILURE
+	for leaf := range p.code {
+		if d := wordLen(leaf); FAILURE == minDepth || d < minDepth {
+			minDepth = d
+		}
+	}
+	return minDepth
+}
+
+// IsUniformDepth reports whether all leaves have the same depth, i.e. the code
+// is a full tree as built by NewUniformCode.
+func (p *prefixCode) IsUniformDepth() bool {
+	return p.MinDepth() == p.MaxDepth()
+}
+
+// DepthHistogram maps each depth occurring in the code to the number of leaves
+// at that depth.
+func (p *prefixCode) DepthHistogram() map[int]int {
+	hist := make(map[int]int)
+	for leaf := range p.code {
+		hist[wordLen(leaf)]++
+	}
+	return hist
+}
+
+// MeanDepth returns the average depth of the leaves, each leaf counting once.
+func (p *prefixCode) MeanDepth() float64 {
+	total := 0
+	for leaf := range p.code {
+		total += wordLen(leaf)
+	}
+	return float64(total) / float64(len(p.code))
+}
+
+// DepthVariance returns the (population) variance of the leaf depths.
+func (p *prefixCode) DepthVariance() float64 {
+	mean := p.MeanDepth()
+	variance := 0.0
+	for leaf := range p.code {
+		diff := float64(wordLen(leaf)) - mean
+		variance += diff * diff
+	}
+	return variance / float64(len(p.code))
+}
@@ -0,0 +1,60 @@
+package prefcode
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestDepth(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking depth queries.", func(t *testing.T) {
+		baseCode, err := NewPrefCodeAlphaString("ab")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString in test checking depths.")
+		}
+		assertCorrectMessage(t, strconv.Itoa(baseCode.DepthOf(EmptyString)), "0")
+		assertCorrectMessage(t, strconv.FormatBool(baseCode.IsUniformDepth()), "true")
+
+		baseCode.ExpandAt("ba")
+		assertCorrectMessage(t, strconv.Itoa(baseCode.DepthOf("bab")), "3")
+		assertCorrectMessage(t, strconv.Itoa(baseCode.DepthOf("b")), "-1")
+		assertCorrectMessage(t, strconv.Itoa(baseCode.MaxDepth()), "3")
+		assertCorrectMessage(t, strconv.Itoa(baseCode.MinDepth()), "1")
+		assertCorrectMessage(t, strconv.FormatBool(baseCode.IsUniformDepth()), "false")
+	})
+
+	t.Run("Checking IsUniformDepth on uniform code.", func(t *testing.T) {
+		uniCode, err := NewUniformCode([]rune("abc"), 3)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewUniformCode in test checking IsUniformDepth.")
+		}
+		assertCorrectMessage(t, strconv.FormatBool(uniCode.IsUniformDepth()), "true")
+		assertCorrectMessage(t, strconv.Itoa(uniCode.MinDepth()), "3")
+	})
+	t.Run("Checking depth statistics.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking depth statistics.")
+		}
+		baseCode.ExpandAt("11")
+
+		hist := baseCode.DepthHistogram()
+		assertCorrectMessage(t, fmt.Sprint(hist), "map[1:1 2:1 3:2]")
+		assertCorrectMessage(t, strconv.FormatFloat(baseCode.MeanDepth(), 'f', 4, 64), "2.2500")
+		assertCorrectMessage(t, strconv.FormatFloat(baseCode.DepthVariance(), 'f', 4, 64), "0.6875")
+
+		uniCode, err := NewUniformCode([]rune("01"), 2)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewUniformCode in test checking depth statistics.")
+		}
+		assertCorrectMessage(t, strconv.FormatFloat(uniCode.DepthVariance(), 'f', 4, 64), "0.0000")
+	})
+}
@@ -0,0 +1,154 @@
+package prefcode
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DFSFormat describes how a DFS sequence is written by other tools, so their
+// output can be read without preprocessing.  The zero DFSFormat is the
+// format of the package: '1' for a caret and '0' for a leaf.  Reading skips
+// white space in every format.
+type DFSFormat struct {
+	Caret, Leaf rune // the symbols for a caret and a leaf, '1' and '0' if zero
+	// Brackets writes each node as "(", its children, then ")", so a leaf
+	// is "()" and the caret over two leaves "(()())".  Caret and Leaf are
+	// then unused.
+	Brackets bool
+}
+
+// BracketDFS is the balanced-parentheses format.
+var BracketDFS = DFSFormat{Brackets: true}
+
+func (f DFSFormat) symbols() (caret, leaf rune) {
+	caret, leaf = f.Caret, f.Leaf
+	if 0 == caret {
+		caret = '1'
+	}
+	if 0 == leaf {
+		leaf = '0'
+	}
+	return caret, leaf
+}
+
+// Normalize returns s, written in format f, as a DFS sequence of 1s and 0s
+// without white space.  It checks the symbols and, for brackets, their
+// balance, but not that the sequence is a tree over any alphabet.
+func (f DFSFormat) Normalize(s string) (string, error) {
+	if f.Brackets {
+		return f.normalizeBrackets(s)
+	}
+	caret, leaf := f.symbols()
+	if caret == leaf {
+		return "", errors.New("Caret and leaf are both written " + strconv.QuoteRune(caret))
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case caret == r:
+			b.WriteByte('1')
+		case leaf == r:
+			b.WriteByte('0')
+		case unicode.IsSpace(r):
+		default:
+			return "", errors.New("Unexpected " + strconv.QuoteRune(r) + " in DFS sequence " + strconv.Quote(s))
+		}
+	}
+	return b.String(), nil
+}
+
+// normalizeBrackets reads each "(" as a caret, or as a leaf when the next
+// bracket closes it.
+func (f DFSFormat) normalizeBrackets(s string) (string, error) {
+	var b strings.Builder
+	depth := 0
+	open := false // the last bracket was a "(" not yet written
+	for _, r := range s {
+		switch r {
+		case '(':
+			if open {
+				b.WriteByte('1')
+			}
+			open = true
+			depth++
+		case ')':
+			if 0 == depth {
+				return "", errors.New("Unbalanced ) in " + strconv.Quote(s))
+			}
+			if open {
+				b.WriteByte('0')
+			}
+			open = false
+			depth--
+		default:
+			if !unicode.IsSpace(r) {
+				return "", errors.New("Unexpected " + strconv.QuoteRune(r) + " in bracket sequence " + strconv.Quote(s))
+			}
+		}
+	}
+	if 0 != depth {
+		return "", errors.New("Unbalanced ( in " + strconv.Quote(s))
+	}
+	return b.String(), nil
+}
+
+// Parse returns the code over alphabet whose DFS sequence, children in
+// natural rune order, is s written in format f, labelled in dictionary
+// order.
+func (f DFSFormat) Parse(alphabet []rune, s string) (PrefCode, error) {
+	dfs, err := f.Normalize(s)
+	if nil != err {
+		return nil, err
+	}
+	pc, err := NewPrefCodeAlphaString(string(alphabet))
+	if nil != err {
+		return nil, err
+	}
+	leaves, err := dfsLeaves(pc.alphabet, dfs)
+	if nil != err {
+		return nil, err
+	}
+	pc.code = make(map[string]int, len(leaves))
+	for ii, leaf := range leaves {
+		pc.code[leaf] = ii
+	}
+	pc.reindex()
+	return pc, nil
+}
+
+// Format returns the DFS sequence of pc, children in natural rune order, in
+// format f.
+func (f DFSFormat) Format(pc PrefCode) string {
+	dfs, arity := naturalDFS(pc)
+	if !f.Brackets {
+		caret, leaf := f.symbols()
+		return strings.Map(func(r rune) rune {
+			if '1' == r {
+				return caret
+			}
+			return leaf
+		}, dfs)
+	}
+	var b strings.Builder
+	var left []int // left[d] counts the children still to come at depth d+1
+	for _, r := range dfs {
+		b.WriteByte('(')
+		if '1' == r {
+			left = append(left, arity)
+			continue
+		}
+		b.WriteByte(')')
+		// close the carets whose last child this was.
+		for 0 < len(left) {
+			left[len(left)-1]--
+			if 0 < left[len(left)-1] {
+				break
+			}
+			left = left[:len(left)-1]
+			b.WriteByte(')')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package prefcode
+
+import (
+	"testing"
+)
+
+func TestDFSFormat(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking codes written in other formats.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking DFSFormat.")
+		}
+		assertCorrectMessage(t, BracketDFS.Format(baseCode), "()")
+		baseCode.ExpandAt("0")
+		baseCode.ExpandAt("01")
+		assertCorrectMessage(t, DFSFormat{}.Format(baseCode), "1101000")
+		assertCorrectMessage(t, DFSFormat{Caret: 'x', Leaf: '.'}.Format(baseCode), "xx.x...")
+		assertCorrectMessage(t, BracketDFS.Format(baseCode), "((()(()()))())")
+
+		ternary, err := NewPrefCodeAlphaString("abc")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString() in test checking DFSFormat.")
+		}
+		ternary.ExpandAt("b")
+		assertCorrectMessage(t, BracketDFS.Format(ternary), "(()(()()())())")
+	})
+
+	t.Run("Checking other formats parse to the same code.", func(t *testing.T) {
+		for _, c := range []struct {
+			format DFSFormat
+			s      string
+		}{
+			{DFSFormat{}, "1101000"},
+			{DFSFormat{}, " 11 01\n000\t"},
+			{DFSFormat{Caret: 'x', Leaf: '.'}, "xx.x..."},
+			{BracketDFS, "((()(()()))())"},
+			{BracketDFS, "( (\n() (() ()) ) () )"},
+		} {
+			pc, err := c.format.Parse([]rune("01"), c.s)
+			if nil != err {
+				t.Errorf("Parse(%q): %v", c.s, err)
+				continue
+			}
+			assertCorrectMessage(t, pc.String(), "[00 0], [010 1], [011 2], [1 3]")
+		}
+
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking DFSToPrefCode.")
+		}
+		DFSToPrefCode(baseCode, "11 01 000")
+		assertCorrectMessage(t, baseCode.String(), "[00 0], [010 1], [011 2], [1 3]")
+	})
+
+	t.Run("Checking badly written sequences are rejected.", func(t *testing.T) {
+		for _, c := range []struct {
+			format  DFSFormat
+			s, want string
+		}{
+			{DFSFormat{}, "1a00", `Unexpected 'a' in DFS sequence "1a00"`},
+			{DFSFormat{Caret: '0'}, "100", `Caret and leaf are both written '0'`},
+			{BracketDFS, "(()", `Unbalanced ( in "(()"`},
+			{BracketDFS, "())", `Unbalanced ) in "())"`},
+			{BracketDFS, "(1)", `Unexpected '1' in bracket sequence "(1)"`},
+			{BracketDFS, "(())", `DFS sequence "10" ends before its tree is complete`},
+		} {
+			_, err := c.format.Parse([]rune("01"), c.s)
+			if nil == err {
+				t.Errorf("Parse(%q) gave no error", c.s)
+				continue
+			}
+			assertCorrectMessage(t, err.Error(), c.want)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package prefcode
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Move is one step of the way between two trees: the expansion or the
+// reduction of a caret.  The caret is named by its path from the root, the
+// index of each child taken among the letters in natural rune order, so a
+// Move does not depend on the alphabet.
+type Move struct {
+	Expand bool  // expand at Path, else reduce the caret at Path
+	Path   []int // the child indices from the root to the caret
+	Pos    int   // the index of the caret in the DFS sequence it is in
+}
+
+// Word returns the word of the caret of m over alphabet.
+func (m Move) Word(alphabet []rune) string {
+	letters := MakeAlphabet(string(alphabet))
+	word := make([]rune, len(m.Path))
+	for ii, k := range m.Path {
+		word[ii] = letters[k]
+	}
+	return string(word)
+}
+
+func (m Move) String() string {
+	op := "reduce"
+	if m.Expand {
+		op = "expand"
+	}
+	return op + " " + fmt.Sprint(m.Path) + " at " + strconv.Itoa(m.Pos)
+}
+
+// DiffDFS walks the DFS sequences a and b of two trees over an alphabet of
+// alphabetSize letters side by side and returns the moves taking the tree
+// of a to that of b.  Where a has a caret and b a leaf, the carets of that
+// subtree of a are reduced, lowest first (Pos in a); where b has a caret and
+// a a leaf, the carets of that subtree of b are expanded, highest first (Pos
+// in b).  The moves come in the order the walk meets them, so the first is
+// where the trees first diverge, and applied in order they are always legal.
+// Equal trees give no moves.
+func DiffDFS(a, b string, alphabetSize int) ([]Move, error) {
+	if err := checkDFS(a, alphabetSize); nil != err {
+		return nil, err
+	}
+	if err := checkDFS(b, alphabetSize); nil != err {
+		return nil, err
+	}
+	var moves []Move
+	var path []int // the path of the node the walk is at
+	for ii, jj := 0, 0; ii < len(a); {
+		switch {
+		case '1' == a[ii] && '1' == b[jj]:
+			path = append(path, 0)
+			ii++
+			jj++
+			continue
+		case '0' == a[ii] && '0' == b[jj]:
+			ii++
+			jj++
+		case '1' == a[ii]:
+			below := dfsCarets(a, ii, alphabetSize, path)
+			for k := len(below) - 1; k >= 0; k-- {
+				moves = append(moves, below[k])
+			}
+			ii = dfsSubtreeEnd(a, ii, alphabetSize)
+			jj++
+		default:
+			below := dfsCarets(b, jj, alphabetSize, path)
+			for k := range below {
+				below[k].Expand = true
+			}
+			moves = append(moves, below...)
+			jj = dfsSubtreeEnd(b, jj, alphabetSize)
+			ii++
+		}
+		// climb until some caret still has children to come.
+		for 0 < len(path) && path[len(path)-1] == alphabetSize-1 {
+			path = path[:len(path)-1]
+		}
+		if 0 == len(path) {
+			break
+		}
+		path[len(path)-1]++
+	}
+	return moves, nil
+}
+
+// checkDFS returns an error unless dfs is a DFS sequence of 1s and 0s of a
+// tree over an alphabet of arity letters.  Unlike ValidDFSForPrefC it says
+// why and accepts "0", the trivial code.
+func checkDFS(dfs string, arity int) error {
+	return CheckDFSForest(dfs, arity, 1)
+}
+
+// dfsSubtreeEnd returns the index just past the subtree of dfs starting at
+// pos.
+func dfsSubtreeEnd(dfs string, pos, arity int) int {
+	tally := 1
+	for ; tally > 0; pos++ {
+		if '1' == dfs[pos] {
+			tally += arity - 1
+		} else {
+			tally--
+		}
+	}
+	return pos
+}
+
+// dfsCarets returns, as reductions in preorder, the carets of the subtree of
+// dfs starting at pos, whose node has path at.
+func dfsCarets(dfs string, pos, arity int, at []int) []Move {
+	var carets []Move
+	path := append([]int(nil), at...)
+	depth := len(at) // the depth of the subtree root
+	for end := dfsSubtreeEnd(dfs, pos, arity); pos < end; pos++ {
+		if '1' == dfs[pos] {
+			carets = append(carets, Move{Path: append([]int(nil), path...), Pos: pos})
+			path = append(path, 0)
+			continue
+		}
+		for len(path) > depth && path[len(path)-1] == arity-1 {
+			path = path[:len(path)-1]
+		}
+		if len(path) > depth {
+			path[len(path)-1]++
+		}
+	}
+	return carets
+}
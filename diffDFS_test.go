@@ -0,0 +1,83 @@
+package prefcode
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestDiffDFS(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking the moves between two trees.", func(t *testing.T) {
+		// 00, 010, 011, 1 against 0, 100, 101, 11.
+		moves, err := DiffDFS("1101000", "1011000", 2)
+		if nil != err {
+			t.Fatalf("DiffDFS: %v", err)
+		}
+		assertCorrectMessage(t, fmt.Sprint(moves), "[reduce [0 1] at 3 reduce [0] at 1 expand [1] at 2 expand [1 0] at 3]")
+		assertCorrectMessage(t, moves[1].Word([]rune("ba")), "a")
+		assertCorrectMessage(t, moves[3].Word([]rune("ba")), "ba")
+
+		moves, err = DiffDFS("0", "100", 2)
+		if nil != err {
+			t.Fatalf("DiffDFS: %v", err)
+		}
+		assertCorrectMessage(t, fmt.Sprint(moves), "[expand [] at 0]")
+
+		moves, err = DiffDFS("1010000", "1010000", 3)
+		if nil != err {
+			t.Fatalf("DiffDFS: %v", err)
+		}
+		assertCorrectMessage(t, fmt.Sprint(len(moves)), "0")
+	})
+
+	t.Run("Checking the moves take one code to the other.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		alpha := []rune("abc")
+		for ii := 0; ii < 200; ii++ {
+			a, b := RandomDFS(rng, 3, rng.Intn(8)), RandomDFS(rng, 3, rng.Intn(8))
+			moves, err := DiffDFS(a, b, 3)
+			if nil != err {
+				t.Fatalf("DiffDFS(%q, %q): %v", a, b, err)
+			}
+			pc, err := DFSFormat{}.Parse(alpha, a)
+			if nil != err {
+				t.Fatalf("Parse(%q): %v", a, err)
+			}
+			for _, m := range moves {
+				at := m.Word(alpha)
+				if "" == at && 1 == pc.Size() {
+					at = EmptyString
+				}
+				ok := false
+				if m.Expand {
+					ok = pc.ExpandAt(at)
+				} else {
+					ok = pc.ReduceAt(at)
+				}
+				if !ok {
+					t.Fatalf("%v failed going from %q to %q", m, a, b)
+				}
+			}
+			assertCorrectMessage(t, DFSFormat{}.Format(pc), b)
+		}
+	})
+
+	t.Run("Checking bad sequences are rejected.", func(t *testing.T) {
+		_, err := DiffDFS("100", "10", 2)
+		assertCorrectMessage(t, fmt.Sprint(err), `DFS sequence "10" ends before its tree is complete`)
+		_, err = DiffDFS("1000", "100", 2)
+		assertCorrectMessage(t, fmt.Sprint(err), `DFS sequence "1000" goes on past its tree at 3`)
+		_, err = DiffDFS("1x0", "100", 2)
+		assertCorrectMessage(t, fmt.Sprint(err), `Unexpected 'x' in DFS sequence "1x0"`)
+		_, err = DiffDFS("0", "0", 0)
+		assertCorrectMessage(t, fmt.Sprint(err), "Alphabet size 0 is less than 1")
+	})
+}
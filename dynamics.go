@@ -0,0 +1,142 @@
+package prefcode
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// FixedLeaves returns, in dictionary order, the domain leaves of the reduced
+// form of t which t sends to themselves, so that t is the identity on their
+// cylinders.
+func (t TreePair) FixedLeaves() []string {
+	r := t.Reduce()
+	var fixed []string
+	for _, leaf := range r.domain.sortedKeys() {
+		if r.rng.LeafAtLabel(r.domain.LabelAtLeaf(leaf)) == leaf {
+			fixed = append(fixed, leaf)
+		}
+	}
+	return fixed
+}
+
+// PeriodicLeaves returns the domain leaves d of the reduced form of t whose
+// cylinder is carried back onto itself, as a whole, by some power t^k with
+// k <= maxPeriod, each with its least such k.  Fixed leaves have period 1.
+func (t TreePair) PeriodicLeaves(maxPeriod int) map[string]int {
+	r := t.Reduce()
+	periodic := make(map[string]int)
+	for _, leaf := range r.domain.sortedKeys() {
+		w := leaf
+		if EmptyString == w {
+			w = ""
+		}
+		start := w
+		for k := 1; k <= maxPeriod; k++ {
+			var ok bool
+			if w, ok = r.Apply(w); !ok {
+				break
+			}
+			if w == start {
+				periodic[leaf] = k
+				break
+			}
+		}
+	}
+	return periodic
+}
+
+// FixedPointKind says how t behaves near an isolated fixed point.
+type FixedPointKind int
+
+const (
+	// Attracting fixed points pull their neighbourhood in (slope below one).
+	Attracting FixedPointKind = iota
+	// Repelling fixed points push their neighbourhood out (slope above one).
+	Repelling
+)
+
+func (k FixedPointKind) String() string {
+	if Attracting == k {
+		return "attracting"
+	}
+	return "repelling"
+}
+
+// FixedPoint is an isolated fixed point of t, the infinite word Prefix
+// followed by Cycle repeated forever, and the point Value of [0,1) it names.
+type FixedPoint struct {
+	Prefix string
+	Cycle  string
+	Value  *big.Rat
+	Kind   FixedPointKind
+}
+
+func (f FixedPoint) String() string {
+	return f.Prefix + "(" + f.Cycle + ")^∞ " + f.Kind.String()
+}
+
+// FixedPoints returns the isolated fixed points of t found on the domain
+// leaves of its reduced form, in dictionary order of Prefix.  A domain leaf d
+// sent to a proper extension du is contracted onto the attracting point
+// du^∞; a leaf ru sent to its proper prefix r has the repelling point ru^∞.
+// Leaves sent to unrelated words hold no fixed point, and fixed leaves hold
+// a whole interval of them, see FixedLeaves.
+func (t TreePair) FixedPoints() []FixedPoint {
+	r := t.Reduce()
+	var points []FixedPoint
+	for leaf, label := range r.domain.Code() {
+		d, image := leaf, r.rng.LeafAtLabel(label)
+		if EmptyString == d {
+			d = ""
+		}
+		if EmptyString == image {
+			image = ""
+		}
+		switch {
+		case d != image && strings.HasPrefix(image, d):
+			points = append(points, FixedPoint{Prefix: d, Cycle: image[len(d):], Kind: Attracting})
+		case d != image && strings.HasPrefix(d, image):
+			points = append(points, FixedPoint{Prefix: image, Cycle: d[len(image):], Kind: Repelling})
+		}
+	}
+	for ii := range points {
+		points[ii].Value = periodicValue(r.domain.letters(), points[ii].Prefix, points[ii].Cycle)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Prefix+points[i].Cycle < points[j].Prefix+points[j].Cycle })
+	return points
+}
+
+// periodicValue returns the point of [0,1) named by prefix followed by cycle
+// repeated forever.  With the cylinder of cycle starting at c and of width w,
+// cycle^∞ is c/(1 - w), which is then placed in the cylinder of prefix.
+func periodicValue(alpha []rune, prefix, cycle string) *big.Rat {
+	lo, hi := wordInterval(alpha, prefix)
+	c, w := wordInterval(alpha, cycle)
+	w.Sub(w, c)
+	tail := new(big.Rat).Quo(c, w.Sub(big.NewRat(1, 1), w))
+	return tail.Mul(tail, hi.Sub(hi, lo)).Add(tail, lo)
+}
+
+// Orbit returns w, t(w), t²(w), ... applying t up to maxSteps times, and
+// whether the orbit is eventually periodic: it stops, reporting true, as soon
+// as a word repeats (the repeat is not listed again).  It stops, reporting
+// false, at maxSteps or when a word is too short for t to apply to (see
+// Apply), the last word listed being the one reached.
+func (t TreePair) Orbit(w string, maxSteps int) ([]string, bool) {
+	orbit := []string{w}
+	seen := map[string]bool{w: true}
+	for step := 0; step < maxSteps; step++ {
+		next, ok := t.Apply(w)
+		if !ok {
+			return orbit, false
+		}
+		if seen[next] {
+			return orbit, true
+		}
+		seen[next] = true
+		orbit = append(orbit, next)
+		w = next
+	}
+	return orbit, false
+}
@@ -0,0 +1,70 @@
+package prefcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDynamics(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	x0 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "11": 2}, map[string]int{"00": 0, "01": 1, "1": 2})
+	x1 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "110": 2, "111": 3}, map[string]int{"0": 0, "100": 1, "101": 2, "11": 3})
+	// swaps the halves of [0,1/2), fixing [1/2,1)
+	swap := pairFromMaps(t, map[string]int{"00": 0, "01": 1, "1": 2}, map[string]int{"01": 0, "00": 1, "1": 2})
+
+	t.Run("Checking FixedLeaves.", func(t *testing.T) {
+		assertCorrectMessage(t, fmt.Sprint(x0.FixedLeaves()), "[]")
+		assertCorrectMessage(t, fmt.Sprint(x1.FixedLeaves()), "[0]")
+		assertCorrectMessage(t, fmt.Sprint(swap.FixedLeaves()), "[1]")
+		id, _ := IdentityPair([]rune("01"))
+		assertCorrectMessage(t, fmt.Sprint(id.FixedLeaves()), "[𝛆]")
+	})
+
+	t.Run("Checking PeriodicLeaves.", func(t *testing.T) {
+		assertCorrectMessage(t, fmt.Sprint(swap.PeriodicLeaves(1)), "map[1:1]")
+		assertCorrectMessage(t, fmt.Sprint(swap.PeriodicLeaves(5)), "map[00:2 01:2 1:1]")
+		assertCorrectMessage(t, fmt.Sprint(x0.PeriodicLeaves(5)), "map[]")
+		three := pairFromMaps(t, map[string]int{"00": 0, "01": 1, "1": 2}, map[string]int{"01": 0, "1": 1, "00": 2})
+		assertCorrectMessage(t, fmt.Sprint(three.PeriodicLeaves(3)), "map[00:3 01:3 1:3]")
+	})
+
+	t.Run("Checking FixedPoints.", func(t *testing.T) {
+		// x0 pulls towards 0 and pushes away from 1.
+		assertCorrectMessage(t, fmt.Sprint(x0.FixedPoints()), "[0(0)^∞ attracting 1(1)^∞ repelling]")
+		points := x0.FixedPoints()
+		assertCorrectMessage(t, points[0].Value.RatString()+" "+points[1].Value.RatString(), "0 1")
+		inv := x0.Inverse().FixedPoints()
+		assertCorrectMessage(t, fmt.Sprint(inv), "[0(0)^∞ repelling 1(1)^∞ attracting]")
+
+		// 0 -> 010 contracts [0,1/2) onto 1/3 = 0.(01)^∞.
+		v := pairFromMaps(t, map[string]int{"0": 0, "100": 1, "101": 2, "11": 3}, map[string]int{"010": 0, "011": 1, "00": 2, "1": 3})
+		points = v.FixedPoints()
+		assertCorrectMessage(t, fmt.Sprint(points), "[0(10)^∞ attracting 1(1)^∞ repelling]")
+		assertCorrectMessage(t, points[0].Value.RatString(), "1/3")
+	})
+
+	t.Run("Checking Orbit.", func(t *testing.T) {
+		orbit, periodic := swap.Orbit("0011", 10)
+		assertCorrectMessage(t, fmt.Sprint(orbit, periodic), "[0011 0111] true")
+		orbit, periodic = x0.Orbit("1", 10)
+		assertCorrectMessage(t, fmt.Sprint(orbit, periodic), "[1] false")
+		orbit, periodic = x0.Orbit("110", 10)
+		assertCorrectMessage(t, fmt.Sprint(orbit, periodic), "[110 10 01 001 0001 00001 000001 0000001 00000001 000000001 0000000001] false")
+		orbit, periodic = x0.Orbit("110", 2)
+		assertCorrectMessage(t, fmt.Sprint(orbit, periodic), "[110 10 01] false")
+		orbit, periodic = x0.Orbit("0", 0)
+		assertCorrectMessage(t, fmt.Sprint(orbit, periodic), "[0] false")
+
+		// the leaves of v are permuted in a three-cycle.
+		v := pairFromMaps(t, map[string]int{"00": 0, "01": 1, "1": 2}, map[string]int{"01": 0, "1": 1, "00": 2})
+		orbit, periodic = v.Orbit("01", 5)
+		assertCorrectMessage(t, fmt.Sprint(orbit, periodic), "[01 1 00] true")
+	})
+}
@@ -0,0 +1,57 @@
+package prefcode
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// Encoder writes the codewords of a prefix code to an io.Writer: each label
+// sent to Encode is replaced by the leaf carrying that label.  Output is
+// buffered, so Flush must be called when done.
+type Encoder struct {
+	w     *bufio.Writer
+	words map[int]string // words[label] is the leaf carrying label
+}
+
+// NewEncoder returns an Encoder writing the codewords of pc to w.  The code is
+// copied, so later changes to pc do not affect the Encoder.  Labels need not
+// be 0 ... n-1, so codes in sparse label mode encode by their own labels.
+func NewEncoder(pc PrefCode, w io.Writer) *Encoder {
+	e := &Encoder{w: bufio.NewWriter(w)}
+	e.words = make(map[int]string, pc.Size())
+	for leaf, label := range pc.Code() {
+		if EmptyString == leaf {
+			leaf = ""
+		}
+		e.words[label] = leaf
+	}
+	return e
+}
+
+// Encode writes the codeword carrying label.
+func (e *Encoder) Encode(label int) error {
+	word, ok := e.words[label]
+	if !ok {
+		return errors.New("No codeword with label " + strconv.Itoa(label))
+	}
+	_, err := e.w.WriteString(word)
+	return err
+}
+
+// EncodeAll writes the codewords for each of labels in turn, stopping at the
+// first failure.
+func (e *Encoder) EncodeAll(labels []int) error {
+	for _, label := range labels {
+		if err := e.Encode(label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered codewords to the underlying io.Writer.
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
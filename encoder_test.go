@@ -0,0 +1,73 @@
+package prefcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking Encoder.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking Encoder.")
+		}
+		baseCode.ExpandAt("10")
+
+		var out strings.Builder
+		enc := NewEncoder(baseCode, &out)
+		if err := enc.EncodeAll([]int{0, 2, 1, 0}); nil != err {
+			assertCorrectMessage(t, "Faied to ", "EncodeAll in test checking Encoder.")
+		}
+		assertCorrectMessage(t, out.String(), "")
+		if err := enc.Flush(); nil != err {
+			assertCorrectMessage(t, "Faied to ", "Flush in test checking Encoder.")
+		}
+		assertCorrectMessage(t, out.String(), "01011000")
+
+		if err := enc.Encode(4); nil == err {
+			assertCorrectMessage(t, "No error for ", "Encode of a missing label.")
+		}
+	})
+
+	t.Run("Checking Encoder with unicode alphabet.", func(t *testing.T) {
+		uniCode, err := NewUniformCode([]rune("日本語"), 1)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewUniformCode in test checking Encoder.")
+		}
+
+		var out strings.Builder
+		enc := NewEncoder(uniCode, &out)
+		enc.EncodeAll([]int{2, 0, 1})
+		enc.Flush()
+		assertCorrectMessage(t, out.String(), "語日本")
+	})
+
+	t.Run("Checking Encoder with sparse labels.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking sparse Encoder.")
+		}
+		baseCode.UseSparseLabels()
+		baseCode.ExpandAt(EmptyString)
+		baseCode.ExpandAt("0")
+
+		var out strings.Builder
+		enc := NewEncoder(baseCode, &out)
+		if err := enc.EncodeAll([]int{4, 2, 3}); nil != err {
+			assertCorrectMessage(t, "Faied to ", "EncodeAll in test checking sparse Encoder.")
+		}
+		enc.Flush()
+		assertCorrectMessage(t, out.String(), "01100")
+		if err := enc.Encode(0); nil == err {
+			assertCorrectMessage(t, "No error for ", "Encode of a missing sparse label.")
+		}
+	})
+}
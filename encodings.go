@@ -0,0 +1,101 @@
+package prefcode
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Beside the DFS sequence, two other encodings of the ordered n-ary tree of
+// a code are common.  Both list the nodes in preorder, children in natural
+// rune order, as the DFS sequence does:
+//
+//   - the Łukasiewicz word gives the arity of each node, n for a caret and 0
+//     for a leaf, so the binary tree of 0, 10, 11 is 2 0 2 0 0;
+//   - the level sequence gives the depth of each node, the root at 0, so the
+//     same tree is 0 1 1 2 2.
+//
+// Codes read from either are labelled in dictionary order.
+
+// LukasiewiczWord returns the Łukasiewicz word of the tree of pc.
+func LukasiewiczWord(pc PrefCode) []int {
+	dfs, arity := naturalDFS(pc)
+	word := make([]int, len(dfs))
+	for ii := range dfs {
+		if '1' == dfs[ii] {
+			word[ii] = arity
+		}
+	}
+	return word
+}
+
+// FromLukasiewiczWord returns the code over alphabet whose tree has
+// Łukasiewicz word word.
+func FromLukasiewiczWord(alphabet []rune, word []int) (PrefCode, error) {
+	arity := len(MakeAlphabet(string(alphabet)))
+	var b strings.Builder
+	for _, k := range word {
+		switch k {
+		case arity:
+			b.WriteByte('1')
+		case 0:
+			b.WriteByte('0')
+		default:
+			return nil, errors.New("Arity " + strconv.Itoa(k) + " in Łukasiewicz word is neither 0 nor " + strconv.Itoa(arity))
+		}
+	}
+	return DFSFormat{}.Parse(alphabet, b.String())
+}
+
+// LevelSequence returns the level sequence of the tree of pc.
+func LevelSequence(pc PrefCode) []int {
+	return dfsLevels(naturalDFS(pc))
+}
+
+// FromLevelSequence returns the code over alphabet whose tree has level
+// sequence levels.  A node is a caret exactly when the next node is one level
+// deeper, which fixes the tree; levels must then be its level sequence.
+func FromLevelSequence(alphabet []rune, levels []int) (PrefCode, error) {
+	var b strings.Builder
+	for ii := range levels {
+		if ii+1 < len(levels) && levels[ii+1] == levels[ii]+1 {
+			b.WriteByte('1')
+		} else {
+			b.WriteByte('0')
+		}
+	}
+	pc, err := DFSFormat{}.Parse(alphabet, b.String())
+	if nil != err {
+		return nil, errors.New("Level sequence is not that of a tree: " + err.Error())
+	}
+	for ii, level := range LevelSequence(pc) {
+		if levels[ii] != level {
+			return nil, errors.New("Level sequence has " + strconv.Itoa(levels[ii]) + " at node " + strconv.Itoa(ii) + " where the tree has " + strconv.Itoa(level))
+		}
+	}
+	return pc, nil
+}
+
+// naturalDFS returns the DFS sequence of pc, children in natural rune order,
+// and the number of letters.
+func naturalDFS(pc PrefCode) (string, int) {
+	return NewCompactFrom(pc).DFS(), len(MakeAlphabet(string(pc.Alphabet())))
+}
+
+// dfsLevels returns the depth of each node of the well-formed DFS sequence
+// dfs of a tree of the given arity.
+func dfsLevels(dfs string, arity int) []int {
+	levels := make([]int, len(dfs))
+	stack := []int{0} // the depths of the nodes still to come, next on top
+	for ii := range dfs {
+		d := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		levels[ii] = d
+		if '1' == dfs[ii] {
+			for k := 0; k < arity; k++ {
+				stack = append(stack, d+1)
+			}
+		}
+	}
+	return levels
+}
@@ -0,0 +1,62 @@
+package prefcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEncodings(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking Łukasiewicz words and level sequences.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking encodings.")
+		}
+		assertCorrectMessage(t, fmt.Sprint(LukasiewiczWord(baseCode), LevelSequence(baseCode)), "[0] [0]")
+		baseCode.ExpandAt("0")
+		baseCode.ExpandAt("01")
+		assertCorrectMessage(t, fmt.Sprint(LukasiewiczWord(baseCode)), "[2 2 0 2 0 0 0]")
+		assertCorrectMessage(t, fmt.Sprint(LevelSequence(baseCode)), "[0 1 2 2 3 3 1]")
+
+		ternary, err := NewPrefCodeAlphaString("abc")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString() in test checking encodings.")
+		}
+		ternary.ExpandAt("b")
+		assertCorrectMessage(t, fmt.Sprint(LukasiewiczWord(ternary)), "[3 0 3 0 0 0 0]")
+		assertCorrectMessage(t, fmt.Sprint(LevelSequence(ternary)), "[0 1 1 2 2 2 1]")
+	})
+
+	t.Run("Checking codes read back from both encodings.", func(t *testing.T) {
+		pc, err := FromLukasiewiczWord([]rune("01"), []int{2, 2, 0, 2, 0, 0, 0})
+		if nil != err {
+			t.Fatalf("FromLukasiewiczWord: %v", err)
+		}
+		assertCorrectMessage(t, pc.String(), "[00 0], [010 1], [011 2], [1 3]")
+
+		pc, err = FromLevelSequence([]rune("cba"), []int{0, 1, 1, 2, 2, 2, 1})
+		if nil != err {
+			t.Fatalf("FromLevelSequence: %v", err)
+		}
+		assertCorrectMessage(t, pc.String(), "[a 0], [ba 1], [bb 2], [bc 3], [c 4]")
+	})
+
+	t.Run("Checking bad encodings are rejected.", func(t *testing.T) {
+		_, err := FromLukasiewiczWord([]rune("01"), []int{3, 0, 0, 0})
+		assertCorrectMessage(t, fmt.Sprint(err), "Arity 3 in Łukasiewicz word is neither 0 nor 2")
+		_, err = FromLukasiewiczWord([]rune("01"), []int{2, 0})
+		assertCorrectMessage(t, fmt.Sprint(err), `DFS sequence "10" ends before its tree is complete`)
+
+		_, err = FromLevelSequence([]rune("01"), []int{0, 1, 1, 1})
+		assertCorrectMessage(t, fmt.Sprint(err), `Level sequence is not that of a tree: DFS sequence "1000" goes on past its tree at 3`)
+		_, err = FromLevelSequence([]rune("01"), []int{0, 1, 2, 2, 2})
+		assertCorrectMessage(t, fmt.Sprint(err), "Level sequence has 2 at node 4 where the tree has 1")
+	})
+}
@@ -0,0 +1,92 @@
+package prefcode
+
+import "math"
+
+// In the methods below probs[ii] is the probability of the source symbol
+// coded by the leaf with label ii, or in sparse label mode by the leaf with
+// the ii-th least label, as listed by LabelsToLeaves.  So len(probs) must
+// equal the size of the code; otherwise they return NaN.  Lengths are
+// counted in letters, and entropies taken to base n for an alphabet of n
+// letters, so the two compare.
+
+// AverageLength returns the expected codeword length  sum probs[ii]*|leaf|.
+func (p *prefixCode) AverageLength(probs []float64) float64 {
+	if len(probs) != len(p.code) {
+		return math.NaN()
+	}
+	avg := 0.0
+	for ii, leaf := range p.LabelsToLeaves() {
+		avg += probs[ii] * float64(wordLen(leaf))
+	}
+	return avg
+}
+
+func (c *CompactPrefCode) AverageLength(probs []float64) float64 {
+	if len(probs) != c.Size() {
+		return math.NaN()
+	}
+	avg := 0.0
+	ii := 0
+	c.walk(func(word []rune, _ int, leaf bool) bool {
+		if leaf {
+			if label := int(c.perm[ii]); label >= 0 && label < len(probs) {
+				avg += probs[label] * float64(len(word))
+			}
+			ii++
+		}
+		return true
+	})
+	return avg
+}
+
+// Entropy returns the base-n Shannon entropy of probs, the lower bound on the
+// average length of any prefix code over an n letter alphabet for the source.
+func (p *prefixCode) Entropy(probs []float64) float64 {
+	return entropy(probs, len(p.code), len(p.alphabet))
+}
+
+func (c *CompactPrefCode) Entropy(probs []float64) float64 {
+	return entropy(probs, c.Size(), len(c.alphabet))
+}
+
+// entropy is Entropy for a code of size leaves over n letters.
+func entropy(probs []float64, size, n int) float64 {
+	if len(probs) != size {
+		return math.NaN()
+	}
+	h := 0.0
+	for _, q := range probs {
+		if q > 0 {
+			h -= q * math.Log(q)
+		}
+	}
+	return h / math.Log(float64(n))
+}
+
+// OptimalityGap returns how much longer codewords of p are on average than
+// those of an optimal (Huffman) code over the same alphabet for probs.  The
+// gap is zero exactly when p is optimal for the source.
+func (p *prefixCode) OptimalityGap(probs []float64) float64 {
+	return optimalityGap(p, probs)
+}
+
+func (c *CompactPrefCode) OptimalityGap(probs []float64) float64 {
+	return optimalityGap(c, probs)
+}
+
+func optimalityGap(pc PrefCode, probs []float64) float64 {
+	if len(probs) != pc.Size() {
+		return math.NaN()
+	}
+	hc, err := NewHuffmanCode(pc.Alphabet(), probs)
+	if err != nil {
+		return math.NaN()
+	}
+	best := 0.0
+	for leaf, label := range hc.Code() {
+		if label < len(probs) {
+			best += probs[label] * float64(wordLen(leaf))
+		}
+	}
+	return pc.AverageLength(probs) - best
+}
@@ -0,0 +1,66 @@
+package prefcode
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestEntropy(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	format := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', 4, 64)
+	}
+
+	// dyadic probabilities are coded perfectly by the matching code.
+	t.Run("Checking AverageLength and Entropy dyadic.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking Entropy.")
+		}
+		baseCode.ExpandAt("11")
+
+		probs := []float64{0.5, 0.25, 0.125, 0.125}
+		assertCorrectMessage(t, format(baseCode.AverageLength(probs)), "1.7500")
+		assertCorrectMessage(t, format(baseCode.Entropy(probs)), "1.7500")
+		assertCorrectMessage(t, format(baseCode.OptimalityGap(probs)), "0.0000")
+	})
+
+	t.Run("Checking OptimalityGap.", func(t *testing.T) {
+		uniCode, err := NewUniformCode([]rune("01"), 2)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewUniformCode in test checking OptimalityGap.")
+		}
+
+		probs := []float64{0.5, 0.25, 0.125, 0.125}
+		assertCorrectMessage(t, format(uniCode.AverageLength(probs)), "2.0000")
+		assertCorrectMessage(t, format(uniCode.OptimalityGap(probs)), "0.2500")
+		assertCorrectMessage(t, format(uniCode.OptimalityGap([]float64{1})), "NaN")
+	})
+
+	// sparse labels 2, 3, 4 take the probabilities in order of label.
+	t.Run("Checking AverageLength and OptimalityGap with sparse labels.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking sparse Entropy.")
+		}
+		baseCode.UseSparseLabels()
+		baseCode.ExpandAt(EmptyString)
+		baseCode.ExpandAt("0")
+		assertCorrectMessage(t, baseCode.String(), "[00 3], [01 4], [1 2]")
+
+		probs := []float64{0.5, 0.25, 0.25}
+		assertCorrectMessage(t, format(baseCode.AverageLength(probs)), "1.5000")
+		assertCorrectMessage(t, format(baseCode.Entropy(probs)), "1.5000")
+		assertCorrectMessage(t, format(baseCode.OptimalityGap(probs)), "0.0000")
+		probs = []float64{0.25, 0.25, 0.5}
+		assertCorrectMessage(t, format(baseCode.AverageLength(probs)), "1.7500")
+		assertCorrectMessage(t, format(baseCode.OptimalityGap(probs)), "0.2500")
+	})
+}
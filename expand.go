@@ -0,0 +1,79 @@
+package prefcode
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ExpandToContain expands p as little as possible so that word is a leaf,
+// returning its label.  Unlike ExpandAt, which makes its argument a caret and
+// only reports a bool, it is an error for word to be a caret already or to use
+// letters outside the alphabet.  A word which is already a leaf is left be.
+func (p *prefixCode) ExpandToContain(word string) (int, error) {
+	return expandToContain(p, word)
+}
+
+func (c *CompactPrefCode) ExpandToContain(word string) (int, error) {
+	return expandToContain(c, word)
+}
+
+// expandToContain does the work of ExpandToContain for any PrefCode, by
+// expanding at the parent of word.
+func expandToContain(pc PrefCode, word string) (int, error) {
+	if "" == word {
+		word = EmptyString
+	}
+	if label := pc.LabelAtLeaf(word); FAILURE != label {
+		return label, nil
+	}
+	isCaret := errors.New("Word " + strconv.Quote(word) + " is a caret of the code, so cannot become a leaf")
+	if EmptyString == word {
+		return FAILURE, isCaret
+	}
+	if err := checkLetters(pc, word); nil != err {
+		return FAILURE, err
+	}
+	if pc.IsInternal(word) {
+		return FAILURE, isCaret
+	}
+	pc.ExpandAt(trimLastChar(word))
+	return pc.LabelAtLeaf(word), nil
+}
+
+// ExpandToDepth expands every leaf shallower than d until all leaves have
+// depth at least d, each such leaf becoming the full tree down to depth d.
+// Labels come out as for ExpandAtAll.
+func (p *prefixCode) ExpandToDepth(d int) {
+	expandToDepth(p, d)
+}
+
+func (c *CompactPrefCode) ExpandToDepth(d int) {
+	expandToDepth(c, d)
+}
+
+// expandToDepth expands at the words of length d-1 below each shallow leaf,
+// ExpandAtAll filling in the carets above them.
+func expandToDepth(pc PrefCode, d int) {
+	alpha := pc.Alphabet()
+	var words []string
+	for leaf := range pc.Code() {
+		if wordLen(leaf) >= d {
+			continue
+		}
+		level := []string{""}
+		if EmptyString != leaf {
+			level[0] = leaf
+		}
+		for depth := wordLen(leaf); depth < d-1; depth++ {
+			next := make([]string, 0, len(level)*len(alpha))
+			for _, w := range level {
+				for _, r := range alpha {
+					next = append(next, w+string(r))
+				}
+			}
+			level = next
+		}
+		words = append(words, level...)
+	}
+	pc.ExpandAtAll(words)
+}
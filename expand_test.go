@@ -0,0 +1,65 @@
+package prefcode
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking ExpandToContain.", func(t *testing.T) {
+		pc, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking ExpandToContain.")
+		}
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		for _, code := range []PrefCode{pc, compact} {
+			label, err := code.ExpandToContain("101")
+			assertCorrectMessage(t, fmt.Sprint(label, err), "2 <nil>")
+			assertCorrectMessage(t, code.String(), "[0 0], [100 1], [101 2], [11 3]")
+			label, err = code.ExpandToContain("11")
+			assertCorrectMessage(t, fmt.Sprint(label, err), "3 <nil>")
+			assertCorrectMessage(t, code.String(), "[0 0], [100 1], [101 2], [11 3]")
+
+			_, err = code.ExpandToContain("10")
+			assertCorrectMessage(t, fmt.Sprint(err), `Word "10" is a caret of the code, so cannot become a leaf`)
+			_, err = code.ExpandToContain("")
+			assertCorrectMessage(t, fmt.Sprint(err), `Word "𝛆" is a caret of the code, so cannot become a leaf`)
+			_, err = code.ExpandToContain("0a")
+			assertCorrectMessage(t, fmt.Sprint(err), `Word "0a" uses letter 'a' outside the alphabet`)
+			assertCorrectMessage(t, code.String(), "[0 0], [100 1], [101 2], [11 3]")
+		}
+
+		trivial, _ := NewPrefCodeAlphaString("abc")
+		label, err := trivial.ExpandToContain(EmptyString)
+		assertCorrectMessage(t, fmt.Sprint(label, err), "0 <nil>")
+		label, err = trivial.ExpandToContain("b")
+		assertCorrectMessage(t, fmt.Sprint(label, err), "1 <nil>")
+	})
+
+	t.Run("Checking ExpandToDepth.", func(t *testing.T) {
+		pc, _ := NewPrefCodeFromMap([]rune("01"), map[string]int{"0": 1, "100": 0, "101": 2, "11": 3})
+		compact := NewCompactFrom(pc)
+		for _, code := range []PrefCode{pc, compact} {
+			code.ExpandToDepth(2)
+			assertCorrectMessage(t, code.String(), "[00 1], [01 2], [100 0], [101 3], [11 4]")
+			code.ExpandToDepth(1)
+			code.ExpandToDepth(-1)
+			assertCorrectMessage(t, code.String(), "[00 1], [01 2], [100 0], [101 3], [11 4]")
+			code.ExpandToDepth(3)
+			assertCorrectMessage(t, strconv.Itoa(code.MinDepth())+" "+strconv.Itoa(code.Size()), "3 8")
+		}
+		trivial, _ := NewPrefCodeAlphaString("abc")
+		trivial.ExpandToDepth(2)
+		uniform, _ := NewUniformCode([]rune("abc"), 2)
+		assertCorrectMessage(t, trivial.String(), uniform.String())
+	})
+}
@@ -0,0 +1,96 @@
+package prefcode
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// A forest of r ordered trees over one alphabet, the domain of the
+// Higman-Thompson groups G_{n,r}, is written as the DFS sequences of its
+// trees one after another.  While reading, the tally of nodes still to come
+// starts at r rather than 1, so the sequence ends exactly when the last tree
+// does, and a forest of r trees with c carets over n letters has
+// (n-1)c + r leaves.  One root is a tree, and its DFS sequence as before.
+
+// CheckDFSForest returns an error unless dfs, of 1s and 0s, is the DFS
+// sequence of a forest of roots trees over an alphabet of alphabetSize
+// letters, saying where it is not.
+func CheckDFSForest(dfs string, alphabetSize, roots int) error {
+	if alphabetSize < 1 {
+		return errors.New("Alphabet size " + strconv.Itoa(alphabetSize) + " is less than 1")
+	}
+	if roots < 1 {
+		return errors.New("Forest of " + strconv.Itoa(roots) + " roots")
+	}
+	what := "tree"
+	if 1 < roots {
+		what = "forest"
+	}
+	tally := roots
+	for ii := 0; ii < len(dfs); ii++ {
+		if 0 == tally {
+			return errors.New("DFS sequence " + strconv.Quote(dfs) + " goes on past its " + what + " at " + strconv.Itoa(ii))
+		}
+		switch dfs[ii] {
+		case '1':
+			tally += alphabetSize - 1
+		case '0':
+			tally--
+		default:
+			return errors.New("Unexpected " + strconv.QuoteRune(rune(dfs[ii])) + " in DFS sequence " + strconv.Quote(dfs))
+		}
+	}
+	if 0 != tally {
+		return errors.New("DFS sequence " + strconv.Quote(dfs) + " ends before its " + what + " is complete")
+	}
+	return nil
+}
+
+// ValidDFSForest reports whether dfs is the DFS sequence of a forest of
+// roots trees over an alphabet of alSize letters, as CheckDFSForest.
+func ValidDFSForest(alSize, roots int, dfs string) bool {
+	return nil == CheckDFSForest(dfs, alSize, roots)
+}
+
+// SplitForestDFS returns the DFS sequences of the trees of the forest dfs,
+// in order, after checking it as CheckDFSForest does.
+func SplitForestDFS(dfs string, alphabetSize, roots int) ([]string, error) {
+	if err := CheckDFSForest(dfs, alphabetSize, roots); nil != err {
+		return nil, err
+	}
+	trees := make([]string, 0, roots)
+	for pos := 0; pos < len(dfs); {
+		end := dfsSubtreeEnd(dfs, pos, alphabetSize)
+		trees = append(trees, dfs[pos:end])
+		pos = end
+	}
+	return trees, nil
+}
+
+// ForestDFS returns the DFS sequence of the forest whose trees are those of
+// codes, in order, each children in natural rune order.
+func ForestDFS(codes ...PrefCode) string {
+	var b strings.Builder
+	for _, pc := range codes {
+		dfs, _ := naturalDFS(pc)
+		b.WriteString(dfs)
+	}
+	return b.String()
+}
+
+// ParseForest returns the codes over alphabet of the trees of the forest of
+// roots trees with DFS sequence dfs, each labelled in dictionary order.
+func ParseForest(alphabet []rune, roots int, dfs string) ([]PrefCode, error) {
+	trees, err := SplitForestDFS(dfs, len(MakeAlphabet(string(alphabet))), roots)
+	if nil != err {
+		return nil, err
+	}
+	codes := make([]PrefCode, len(trees))
+	for ii, tree := range trees {
+		if codes[ii], err = (DFSFormat{}).Parse(alphabet, tree); nil != err {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
@@ -0,0 +1,50 @@
+package prefcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestForest(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking forests are split and read.", func(t *testing.T) {
+		assertCorrectMessage(t, fmt.Sprint(ValidDFSForest(2, 3, "1000100"), ValidDFSForest(2, 2, "1000100")), "true false")
+		assertCorrectMessage(t, fmt.Sprint(ValidDFSForest(2, 1, "0"), ValidDFSForest(3, 2, "10000")), "true true")
+
+		trees, err := SplitForestDFS("1000100", 2, 3)
+		if nil != err {
+			t.Fatalf("SplitForestDFS: %v", err)
+		}
+		assertCorrectMessage(t, fmt.Sprint(trees), "[100 0 100]")
+
+		codes, err := ParseForest([]rune("01"), 3, "11010000100")
+		if nil != err {
+			t.Fatalf("ParseForest: %v", err)
+		}
+		assertCorrectMessage(t, fmt.Sprint(codes), "[[00 0], [010 1], [011 2], [1 3] [𝛆 0] [0 0], [1 1]]")
+		assertCorrectMessage(t, ForestDFS(codes...), "11010000100")
+	})
+
+	t.Run("Checking bad forests are rejected.", func(t *testing.T) {
+		for _, c := range []struct {
+			dfs         string
+			size, roots int
+			want        string
+		}{
+			{"10000", 2, 2, `DFS sequence "10000" goes on past its forest at 4`},
+			{"100", 2, 2, `DFS sequence "100" ends before its forest is complete`},
+			{"1000", 2, 1, `DFS sequence "1000" goes on past its tree at 3`},
+			{"0", 2, 0, "Forest of 0 roots"},
+		} {
+			_, err := SplitForestDFS(c.dfs, c.size, c.roots)
+			assertCorrectMessage(t, fmt.Sprint(err), c.want)
+		}
+	})
+}
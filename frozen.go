@@ -0,0 +1,117 @@
+package prefcode
+
+// FrozenCode is an immutable prefix code.  No method changes it: Expand,
+// Reduce and ApplyPerm return new FrozenCodes, so one may be shared between
+// goroutines and held in caches without copying or locking.  Make one with
+// Freeze or Builder.Freeze, and go back to a mutable code with Thaw.
+type FrozenCode struct {
+	p *prefixCode
+}
+
+// Freeze returns a frozen copy of pc.
+func Freeze(pc PrefCode) FrozenCode {
+	if p, ok := pc.(*prefixCode); ok {
+		return freeze(p.clone())
+	}
+	return freeze(pairCode(pc.Alphabet(), pc))
+}
+
+// Freeze returns the planned code, frozen.  The Builder may carry on planning.
+func (b *Builder) Freeze() FrozenCode {
+	return freeze(b.Build().(*prefixCode))
+}
+
+// freeze takes p over, working out the cached data up front so that the
+// reads of a FrozenCode never write.
+func freeze(p *prefixCode) FrozenCode {
+	p.warm()
+	return FrozenCode{p: p}
+}
+
+// Thaw returns a mutable copy of f.
+func (f FrozenCode) Thaw() PrefCode {
+	return f.p.clone()
+}
+
+func (f FrozenCode) Alphabet() []rune {
+	return f.p.Alphabet()
+}
+
+func (f FrozenCode) Size() int {
+	return f.p.Size()
+}
+
+// Code returns a copy of the map from leaves to labels.
+func (f FrozenCode) Code() map[string]int {
+	return f.p.Code()
+}
+
+// View returns a read-only view of f, without copying.
+func (f FrozenCode) View() CodeView {
+	return f.p.View()
+}
+
+func (f FrozenCode) LabelAtLeaf(leaf string) int {
+	return f.p.LabelAtLeaf(leaf)
+}
+
+func (f FrozenCode) LeafAtLabel(label int) string {
+	return f.p.LeafAtLabel(label)
+}
+
+func (f FrozenCode) IsLeaf(word string) bool {
+	return f.p.IsLeaf(word)
+}
+
+func (f FrozenCode) GetPrefixOf(s string) string {
+	return f.p.GetPrefixOf(s)
+}
+
+func (f FrozenCode) ExposedCarets() []string {
+	return f.p.ExposedCarets()
+}
+
+func (f FrozenCode) Permutation() Perm {
+	return f.p.Permutation()
+}
+
+// Expand returns f with s made a caret, as ExpandAt would, reporting false,
+// and returning f, if ExpandAt would do nothing.
+func (f FrozenCode) Expand(s string) (FrozenCode, bool) {
+	c := f.p.clone()
+	if !c.ExpandAt(s) {
+		return f, false
+	}
+	return freeze(c), true
+}
+
+// Reduce returns f with the tree below s collapsed, as ReduceAt would,
+// reporting false, and returning f, if ReduceAt would do nothing.
+func (f FrozenCode) Reduce(s string) (FrozenCode, bool) {
+	c := f.p.clone()
+	if !c.ReduceAt(s) {
+		return f, false
+	}
+	return freeze(c), true
+}
+
+// ApplyPerm returns f with its labels permuted, as ApplyPerm would,
+// reporting false, and returning f, if perm is refused.
+func (f FrozenCode) ApplyPerm(perm Perm) (FrozenCode, bool) {
+	c := f.p.clone()
+	if !c.ApplyPerm(perm) {
+		return f, false
+	}
+	return freeze(c), true
+}
+
+func (f FrozenCode) Equals(q FrozenCode) bool {
+	return f.String() == q.String()
+}
+
+func (f FrozenCode) String() string {
+	if nil == f.p {
+		return "<nil>"
+	}
+	return f.p.String()
+}
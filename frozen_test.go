@@ -0,0 +1,88 @@
+package prefcode
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFrozen(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking Freeze and Thaw.", func(t *testing.T) {
+		pc, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking Freeze.")
+		}
+		pc.ExpandAt("1")
+		f := Freeze(pc)
+		pc.ExpandAt("0")
+		assertCorrectMessage(t, f.String(), "[0 0], [10 1], [11 2]")
+
+		thawed := f.Thaw()
+		thawed.ReduceAt("1")
+		assertCorrectMessage(t, f.String(), "[0 0], [10 1], [11 2]")
+
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		compact.ExpandAt("1")
+		assertCorrectMessage(t, strconv.FormatBool(Freeze(compact).Equals(f)), "true")
+		assertCorrectMessage(t, FrozenCode{}.String(), "<nil>")
+	})
+
+	t.Run("Checking Expand, Reduce and ApplyPerm.", func(t *testing.T) {
+		bld, _ := NewBuilder([]rune("01"))
+		bld.ExpandAt("1")
+		f := bld.Freeze()
+
+		g, ok := f.Expand("0")
+		assertCorrectMessage(t, strconv.FormatBool(ok), "true")
+		assertCorrectMessage(t, g.String(), "[00 0], [01 1], [10 2], [11 3]")
+		assertCorrectMessage(t, strings.Join(g.ExposedCarets(), ","), "0,1")
+		assertCorrectMessage(t, f.String(), "[0 0], [10 1], [11 2]")
+
+		h, ok := g.Reduce("1")
+		assertCorrectMessage(t, strconv.FormatBool(ok), "true")
+		assertCorrectMessage(t, h.String(), "[00 0], [01 1], [1 2]")
+		_, ok = g.Reduce("111")
+		assertCorrectMessage(t, strconv.FormatBool(ok), "false")
+
+		p, ok := f.ApplyPerm(Perm{0: 2, 1: 0, 2: 1})
+		assertCorrectMessage(t, strconv.FormatBool(ok), "true")
+		assertCorrectMessage(t, p.String(), "[0 2], [10 0], [11 1]")
+		assertCorrectMessage(t, strconv.Itoa(p.LabelAtLeaf("11"))+" "+p.LeafAtLabel(2), "1 0")
+		assertCorrectMessage(t, f.Permutation().String(), "()")
+	})
+
+	// run with -race: concurrent reads must not write.
+	t.Run("Checking concurrent reads.", func(t *testing.T) {
+		pc, _ := NewUniformCode([]rune("01"), 6)
+		pc.SwapPermAtKeys("000000", "111111")
+		pc.ExpandAt("0101010")
+		f := Freeze(pc)
+		want := f.String()
+		var wg sync.WaitGroup
+		for ii := 0; ii < 8; ii++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				got := f.String() + f.Permutation().String() + strings.Join(f.ExposedCarets(), ",")
+				f.LabelAtLeaf("111111")
+				f.LeafAtLabel(3)
+				f.GetPrefixOf("0101010101")
+				for range f.View().Leaves() {
+				}
+				if !strings.HasPrefix(got, want) {
+					t.Errorf("got %q want %q", got, want)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
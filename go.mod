@@ -1,3 +1,3 @@
 module github.com/loeksnokes/prefcode
 
-go 1.17
+go 1.23
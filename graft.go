@@ -0,0 +1,138 @@
+package prefcode
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// GraftAt replaces leaf by the whole tree of sub, a code over the same
+// alphabet: the leaves of sub, prefixed by leaf, take its place.  In label
+// order they come where leaf was, in the order of their labels in sub, and
+// the later labels shift up to make room, just as for ExpandAt.
+func (p *prefixCode) GraftAt(leaf string, sub PrefCode) error {
+	return graftAt(p, leaf, sub)
+}
+
+func (c *CompactPrefCode) GraftAt(leaf string, sub PrefCode) error {
+	return graftAt(c, leaf, sub)
+}
+
+// graftAt does the work of GraftAt for any PrefCode: it expands at the carets
+// of sub below leaf, then reorders the labels of the new leaves.
+func graftAt(pc PrefCode, leaf string, sub PrefCode) error {
+	if string(MakeAlphabet(string(pc.Alphabet()))) != string(MakeAlphabet(string(sub.Alphabet()))) {
+		return errors.New("Cannot graft a code over a different alphabet")
+	}
+	if FAILURE == pc.LabelAtLeaf(leaf) {
+		return errors.New("Cannot graft at " + strconv.Quote(leaf) + ", which is not a leaf")
+	}
+	if 1 == sub.Size() {
+		return nil
+	}
+	base := leaf
+	if EmptyString == base {
+		base = ""
+	}
+	carets := sub.InternalNodes()
+	for ii, caret := range carets {
+		carets[ii] = base + caret
+	}
+	if err := pc.ExpandAtAll(carets); nil != err {
+		return err
+	}
+
+	// the grafted leaves hold a block of labels; hand them out in the order
+	// of sub.
+	grafted := sub.LabelsToLeaves()
+	labels := make([]int, len(grafted))
+	for ii, v := range grafted {
+		labels[ii] = pc.LabelAtLeaf(base + v)
+	}
+	sort.Ints(labels)
+	perm := make(Perm, pc.Size())
+	for _, label := range pc.Code() {
+		perm[label] = label
+	}
+	for ii, v := range grafted {
+		perm[pc.LabelAtLeaf(base+v)] = labels[ii]
+	}
+	pc.ApplyPerm(perm)
+	return nil
+}
+
+// SubcodeAt returns the code formed by the leaves at or below the caret
+// prefix, with prefix cut off, their labels renumbered 0 ... k-1 in the same
+// order.  At a leaf it gives the trivial code.
+func (p *prefixCode) SubcodeAt(prefix string) (PrefCode, error) {
+	return subcodeAt(p, prefix)
+}
+
+func (c *CompactPrefCode) SubcodeAt(prefix string) (PrefCode, error) {
+	return subcodeAt(c, prefix)
+}
+
+func subcodeAt(pc PrefCode, prefix string) (PrefCode, error) {
+	if err := checkLetters(pc, prefix); nil != err {
+		return nil, err
+	}
+	if !pc.IsLeaf(prefix) && !pc.IsInternal(prefix) {
+		return nil, errors.New("Word " + strconv.Quote(prefix) + " lies below the code")
+	}
+	base := prefix
+	if EmptyString == base {
+		base = ""
+	}
+	var below []string
+	for _, leaf := range pc.LabelsToLeaves() {
+		if EmptyString != leaf && len(base) < len(leaf) && base == leaf[:len(base)] {
+			below = append(below, leaf)
+		}
+	}
+	sub := &prefixCode{alphabet: pc.Alphabet(), code: make(map[string]int, len(below))}
+	for k, leaf := range below {
+		sub.code[leaf[len(base):]] = k
+	}
+	if 0 == len(below) {
+		sub.code[EmptyString] = 0
+	}
+	sub.reindex()
+	return sub, nil
+}
+
+// Product returns the code whose leaves are the words uv, u a leaf of p and v
+// one of q, over their common alphabet: p with q grafted at every leaf.  The
+// leaf uv is labelled label(u)*|q| + label(v), so labels run through those of
+// q within each leaf of p in turn.
+func (p *prefixCode) Product(q PrefCode) (PrefCode, error) {
+	return product(p, q)
+}
+
+func (c *CompactPrefCode) Product(q PrefCode) (PrefCode, error) {
+	return product(c, q)
+}
+
+func product(p, q PrefCode) (PrefCode, error) {
+	if string(MakeAlphabet(string(p.Alphabet()))) != string(MakeAlphabet(string(q.Alphabet()))) {
+		return nil, errors.New("Cannot multiply codes over different alphabets")
+	}
+	qCode := q.Code()
+	c := &prefixCode{alphabet: p.Alphabet(), code: make(map[string]int, p.Size()*len(qCode))}
+	for u, i := range p.Code() {
+		if EmptyString == u {
+			u = ""
+		}
+		for v, j := range qCode {
+			if EmptyString == v {
+				v = ""
+			}
+			word := u + v
+			if "" == word {
+				word = EmptyString
+			}
+			c.code[word] = i*len(qCode) + j
+		}
+	}
+	c.reindex()
+	return c, nil
+}
@@ -0,0 +1,95 @@
+package prefcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGraft(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking GraftAt.", func(t *testing.T) {
+		sub, err := NewPrefCodeFromMap([]rune("01"), map[string]int{"0": 2, "10": 0, "11": 1})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeFromMap() in test checking GraftAt.")
+		}
+		pc, _ := NewPrefCode()
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		for _, code := range []PrefCode{pc, compact} {
+			code.ExpandAt("")
+			code.SwapPermAtKeys("0", "1")
+			assertCorrectMessage(t, fmt.Sprint(code.GraftAt("1", sub)), "<nil>")
+			assertCorrectMessage(t, code.String(), "[0 3], [10 2], [110 0], [111 1]")
+			assertCorrectMessage(t, fmt.Sprint(code.GraftAt("0", sub)), "<nil>")
+			assertCorrectMessage(t, code.String(), "[00 5], [010 3], [011 4], [10 2], [110 0], [111 1]")
+			trivial, _ := NewPrefCode()
+			assertCorrectMessage(t, fmt.Sprint(code.GraftAt("10", trivial)), "<nil>")
+			assertCorrectMessage(t, code.String(), "[00 5], [010 3], [011 4], [10 2], [110 0], [111 1]")
+
+			assertCorrectMessage(t, fmt.Sprint(code.GraftAt("1", sub)), `Cannot graft at "1", which is not a leaf`)
+			other, _ := NewPrefCodeAlphaString("ab")
+			assertCorrectMessage(t, fmt.Sprint(code.GraftAt("10", other)), "Cannot graft a code over a different alphabet")
+		}
+
+		root, _ := NewPrefCode()
+		root.GraftAt(EmptyString, sub)
+		assertCorrectMessage(t, root.String(), sub.String())
+	})
+
+	t.Run("Checking SubcodeAt.", func(t *testing.T) {
+		pc, _ := NewPrefCodeFromMap([]rune("01"), map[string]int{"00": 5, "010": 3, "011": 4, "10": 2, "110": 0, "111": 1})
+		compact := NewCompactFrom(pc)
+		for _, code := range []PrefCode{pc, compact} {
+			sub, err := code.SubcodeAt("0")
+			assertCorrectMessage(t, fmt.Sprint(sub, err), "[0 2], [10 0], [11 1] <nil>")
+			sub, _ = code.SubcodeAt("11")
+			assertCorrectMessage(t, sub.String(), "[0 0], [1 1]")
+			sub, _ = code.SubcodeAt("10")
+			assertCorrectMessage(t, sub.String(), "[𝛆 0]")
+			sub, _ = code.SubcodeAt("")
+			assertCorrectMessage(t, sub.String(), code.String())
+			_, err = code.SubcodeAt("100")
+			assertCorrectMessage(t, fmt.Sprint(err), `Word "100" lies below the code`)
+
+			// grafting the subcode back gives the code again, up to labels.
+			sub, _ = code.SubcodeAt("1")
+			trimmed := NewCompactFrom(code)
+			trimmed.ReduceAt("1")
+			trimmed.GraftAt("1", sub)
+			trimmed.RelabelLexicographic()
+			whole := NewCompactFrom(code)
+			whole.RelabelLexicographic()
+			assertCorrectMessage(t, trimmed.String(), whole.String())
+		}
+	})
+
+	t.Run("Checking Product.", func(t *testing.T) {
+		p, _ := NewPrefCodeFromMap([]rune("01"), map[string]int{"0": 1, "1": 0})
+		q, _ := NewPrefCodeFromMap([]rune("01"), map[string]int{"0": 0, "10": 1, "11": 2})
+		for _, code := range []PrefCode{p, NewCompactFrom(p)} {
+			pq, err := code.Product(q)
+			assertCorrectMessage(t, fmt.Sprint(pq, err), "[00 3], [010 4], [011 5], [10 0], [110 1], [111 2] <nil>")
+		}
+		qp, _ := q.Product(p)
+		assertCorrectMessage(t, qp.String(), "[00 1], [01 0], [100 3], [101 2], [110 5], [111 4]")
+
+		// the trivial code is a two-sided identity.
+		trivial, _ := NewPrefCode()
+		tq, _ := trivial.Product(q)
+		assertCorrectMessage(t, tq.String(), q.String())
+		qt, _ := q.Product(trivial)
+		assertCorrectMessage(t, qt.String(), q.String())
+		tt, _ := trivial.Product(trivial)
+		assertCorrectMessage(t, tt.String(), "[𝛆 0]")
+
+		other, _ := NewPrefCodeAlphaString("ab")
+		_, err := p.Product(other)
+		assertCorrectMessage(t, fmt.Sprint(err), "Cannot multiply codes over different alphabets")
+	})
+}
@@ -0,0 +1,92 @@
+package prefcode
+
+import (
+	"errors"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A letter a reader sees, like "é" written with a combining accent or a thumbs
+// up with a skin tone, may be several runes, and one may be a prefix of
+// another.  A grapheme alphabet is a TokenAlphabet whose tokens are such
+// clusters: strings are cut into clusters, not matched against the tokens,
+// and each cluster becomes a single letter of the code, so that the rune
+// indexing of the package is cluster indexing of the text.
+
+// Graphemes splits s into grapheme clusters.  The segmentation approximates
+// the extended grapheme clusters of Unicode Standard Annex #29, enough for
+// combining marks, variation selectors, emoji modifiers and tags, joiner
+// sequences, flags and CR LF; Hangul syllables spelt in conjoining jamo are
+// not joined.
+func Graphemes(s string) []string {
+	var clusters []string
+	for start := 0; start < len(s); {
+		r, size := utf8.DecodeRuneInString(s[start:])
+		end := start + size
+		// a regional indicator pairs with the next one to make a flag.
+		prev, pairs := r, isRegionalIndicator(r)
+		for end < len(s) {
+			next, n := utf8.DecodeRuneInString(s[end:])
+			join := extendsCluster(next) || '\u200d' == prev ||
+				('\r' == prev && '\n' == next) ||
+				(pairs && isRegionalIndicator(next))
+			if !join {
+				break
+			}
+			prev, pairs = next, false
+			end += n
+		}
+		clusters = append(clusters, s[start:end])
+		start = end
+	}
+	return clusters
+}
+
+// extendsCluster reports whether r attaches to the cluster before it.
+func extendsCluster(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		'\u200c' == r || '\u200d' == r ||
+		(0x1f3fb <= r && r <= 0x1f3ff) ||
+		(0xe0020 <= r && r <= 0xe007f)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return 0x1f1e6 <= r && r <= 0x1f1ff
+}
+
+// NewGraphemeAlphabet returns the alphabet whose letters are the grapheme
+// clusters letters, in the order given.  The letters need not be prefix-free,
+// "👍" and "👍🏽" being different letters, but each must be one cluster.
+func NewGraphemeAlphabet(letters []string) (*TokenAlphabet, error) {
+	if len(letters) < 1 {
+		return nil, errors.New("Empty Alphabet forbidden")
+	}
+	if len(letters) > maxTokens {
+		return nil, errors.New("Too many tokens in alphabet")
+	}
+	ta := &TokenAlphabet{tokens: make([]string, len(letters)), index: make(map[string]int, len(letters)), graphemes: true}
+	copy(ta.tokens, letters)
+	for ii, letter := range ta.tokens {
+		if 1 != len(Graphemes(letter)) {
+			return nil, errors.New("Letter " + letter + " is not a single grapheme cluster")
+		}
+		if _, ok := ta.index[letter]; ok {
+			return nil, errors.New("Repeated token " + letter + " in alphabet")
+		}
+		ta.index[letter] = ii
+	}
+	return ta, nil
+}
+
+// splitGraphemes is Split for a grapheme alphabet.
+func (ta *TokenAlphabet) splitGraphemes(s string) ([]string, error) {
+	clusters := Graphemes(s)
+	rest := s
+	for _, c := range clusters {
+		if _, ok := ta.index[c]; !ok {
+			return nil, errors.New("No token at the start of " + rest)
+		}
+		rest = rest[len(c):]
+	}
+	return clusters, nil
+}
@@ -0,0 +1,57 @@
+package prefcode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGraphemes(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking Graphemes.", func(t *testing.T) {
+		for _, tc := range []struct{ s, want string }{
+			{"", ""},
+			{"abc", "a|b|c"},
+			{"e\u0301a", "e\u0301|a"},
+			{"👍👍\U0001F3FD", "👍|👍\U0001F3FD"},
+			{"\U0001F1EB\U0001F1F7\U0001F1E9\U0001F1EA\U0001F1EE", "\U0001F1EB\U0001F1F7|\U0001F1E9\U0001F1EA|\U0001F1EE"},
+			{"👩‍👧x", "👩‍👧|x"},
+			{"\r\n\n", "\r\n|\n"},
+		} {
+			assertCorrectMessage(t, strings.Join(Graphemes(tc.s), "|"), tc.want)
+		}
+	})
+
+	t.Run("Checking NewGraphemeAlphabet.", func(t *testing.T) {
+		_, err := NewGraphemeAlphabet([]string{"ab"})
+		assertCorrectMessage(t, fmt.Sprint(err), "Letter ab is not a single grapheme cluster")
+		_, err = NewGraphemeAlphabet([]string{"👍", "👍"})
+		assertCorrectMessage(t, fmt.Sprint(err), "Repeated token 👍 in alphabet")
+
+		thumbs := "👍\U0001F3FD"
+		ta, err := NewGraphemeAlphabet([]string{"👍", thumbs, "e\u0301"})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewGraphemeAlphabet() in test checking NewGraphemeAlphabet.")
+		}
+		tokens, _ := ta.Split(thumbs + "👍e\u0301")
+		assertCorrectMessage(t, strconv.Itoa(len(tokens)), "3")
+		assertCorrectMessage(t, tokens[0], thumbs)
+		_, err = ta.Split("👍e")
+		assertCorrectMessage(t, fmt.Sprint(err), "No token at the start of e")
+
+		pc, _ := ta.NewPrefCode()
+		w, _ := ta.Encode(thumbs)
+		pc.ExpandAt(w)
+		w, _ = ta.Encode(thumbs + "👍e\u0301")
+		s, _ := ta.Decode(pc.GetPrefixOf(w))
+		assertCorrectMessage(t, s, thumbs+"👍")
+	})
+}
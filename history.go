@@ -0,0 +1,238 @@
+package prefcode
+
+import "fmt"
+
+// History records the mutations of a PrefCode so they can be undone and
+// redone.  Reads go straight to the code.  Each recorded step keeps a copy of
+// the code as it was, so a long history of a large code is costly; a limit
+// keeps only the latest steps.  Mutations which report doing nothing (false,
+// or an error) are not recorded.
+type History struct {
+	PrefCode
+	limit int
+	undo  []historyStep
+	redo  []historyStep
+}
+
+var _ PrefCode = (*History)(nil)
+
+// historyStep is a mutation and how to get back to the other side of it.
+type historyStep struct {
+	op      string
+	restore func()
+}
+
+// NewHistory returns pc with its mutations recorded from now on, keeping at
+// most limit steps (any number if limit is 0).  pc should not be mutated
+// directly afterwards.
+func NewHistory(pc PrefCode, limit int) *History {
+	return &History{PrefCode: pc, limit: limit}
+}
+
+// snapshot returns a function putting pc back as it is now.  A prefixCode
+// keeps the hooks it has when put back.
+func snapshot(pc PrefCode) func() {
+	switch c := pc.(type) {
+	case *prefixCode:
+		saved := c.clone()
+		return func() {
+			observers := c.hooks
+			*c = *saved
+			c.hooks = observers
+		}
+	case *CompactPrefCode:
+		saved := NewCompactFrom(c)
+		return func() { *c = *saved }
+	}
+	alpha, code := pc.Alphabet(), pc.Code()
+	return func() {
+		pc.SetAlphabet(alpha)
+		pc.SetCode(code)
+	}
+}
+
+// record runs the mutation op, keeping a step to undo it if it did anything.
+func (h *History) record(op string, mutate func() bool) {
+	restore := snapshot(h.PrefCode)
+	if !mutate() {
+		return
+	}
+	h.undo = append(h.undo, historyStep{op: op, restore: restore})
+	if 0 < h.limit && len(h.undo) > h.limit {
+		h.undo = h.undo[len(h.undo)-h.limit:]
+	}
+	h.redo = nil
+}
+
+// Undo undoes the last n recorded mutations, or as many as there are,
+// returning how many it undid.
+func (h *History) Undo(n int) int {
+	return h.step(&h.undo, &h.redo, n)
+}
+
+// Redo redoes the last n undone mutations, or as many as there are, returning
+// how many it redid.  A new mutation forgets what was undone.
+func (h *History) Redo(n int) int {
+	return h.step(&h.redo, &h.undo, n)
+}
+
+// step moves n steps from one stack to the other, restoring the code.
+func (h *History) step(from, to *[]historyStep, n int) int {
+	done := 0
+	for ; done < n && 0 < len(*from); done++ {
+		last := (*from)[len(*from)-1]
+		*from = (*from)[:len(*from)-1]
+		*to = append(*to, historyStep{op: last.op, restore: snapshot(h.PrefCode)})
+		last.restore()
+	}
+	return done
+}
+
+// Log returns the recorded mutations which can be undone, oldest first, as
+// calls such as ExpandAt("10").
+func (h *History) Log() []string {
+	log := make([]string, len(h.undo))
+	for ii, s := range h.undo {
+		log[ii] = s.op
+	}
+	return log
+}
+
+func (h *History) SetAlphabet(alpha []rune) {
+	h.record(fmt.Sprintf("SetAlphabet(%q)", string(alpha)), func() bool {
+		h.PrefCode.SetAlphabet(alpha)
+		return true
+	})
+}
+
+func (h *History) SetCode(code map[string]int) {
+	h.record(fmt.Sprintf("SetCode(%v)", code), func() bool {
+		h.PrefCode.SetCode(code)
+		return true
+	})
+}
+
+func (h *History) ReduceAt(s string) (ok bool) {
+	h.record(fmt.Sprintf("ReduceAt(%q)", s), func() bool {
+		ok = h.PrefCode.ReduceAt(s)
+		return ok
+	})
+	return
+}
+
+func (h *History) ExpandAt(s string) (ok bool) {
+	h.record(fmt.Sprintf("ExpandAt(%q)", s), func() bool {
+		ok = h.PrefCode.ExpandAt(s)
+		return ok
+	})
+	return
+}
+
+func (h *History) ExpandToContain(word string) (label int, err error) {
+	h.record(fmt.Sprintf("ExpandToContain(%q)", word), func() bool {
+		label, err = h.PrefCode.ExpandToContain(word)
+		return nil == err
+	})
+	return
+}
+
+func (h *History) ExpandAtAll(words []string) (err error) {
+	h.record(fmt.Sprintf("ExpandAtAll(%q)", words), func() bool {
+		err = h.PrefCode.ExpandAtAll(words)
+		return nil == err
+	})
+	return
+}
+
+func (h *History) ReduceAtAll(words []string) (err error) {
+	h.record(fmt.Sprintf("ReduceAtAll(%q)", words), func() bool {
+		err = h.PrefCode.ReduceAtAll(words)
+		return nil == err
+	})
+	return
+}
+
+func (h *History) ExpandToDepth(d int) {
+	h.record(fmt.Sprintf("ExpandToDepth(%d)", d), func() bool {
+		h.PrefCode.ExpandToDepth(d)
+		return true
+	})
+}
+
+func (h *History) GraftAt(leaf string, sub PrefCode) (err error) {
+	h.record(fmt.Sprintf("GraftAt(%q, %v)", leaf, sub), func() bool {
+		err = h.PrefCode.GraftAt(leaf, sub)
+		return nil == err
+	})
+	return
+}
+
+func (h *History) ApplyPerm(perm Perm) (ok bool) {
+	h.record(fmt.Sprintf("ApplyPerm(%v)", perm), func() bool {
+		ok = h.PrefCode.ApplyPerm(perm)
+		return ok
+	})
+	return
+}
+
+func (h *History) ComposePerm(perm Perm) (err error) {
+	h.record(fmt.Sprintf("ComposePerm(%v)", perm), func() bool {
+		err = h.PrefCode.ComposePerm(perm)
+		return nil == err
+	})
+	return
+}
+
+func (h *History) SwapPermAtKeys(a, b string) (err error) {
+	h.record(fmt.Sprintf("SwapPermAtKeys(%q, %q)", a, b), func() bool {
+		err = h.PrefCode.SwapPermAtKeys(a, b)
+		return nil == err
+	})
+	return
+}
+
+func (h *History) SwapLabels(pairs [][2]string) (err error) {
+	h.record(fmt.Sprintf("SwapLabels(%q)", pairs), func() bool {
+		err = h.PrefCode.SwapLabels(pairs)
+		return nil == err
+	})
+	return
+}
+
+func (h *History) CycleLabels(leaves []string) (err error) {
+	h.record(fmt.Sprintf("CycleLabels(%q)", leaves), func() bool {
+		err = h.PrefCode.CycleLabels(leaves)
+		return nil == err
+	})
+	return
+}
+
+func (h *History) RelabelLexicographic() {
+	h.record("RelabelLexicographic()", func() bool {
+		h.PrefCode.RelabelLexicographic()
+		return true
+	})
+}
+
+func (h *History) RelabelBy(less func(a, b string) bool) {
+	h.record("RelabelBy(less)", func() bool {
+		h.PrefCode.RelabelBy(less)
+		return true
+	})
+}
+
+func (h *History) InvertLabels() {
+	h.record("InvertLabels()", func() bool {
+		h.PrefCode.InvertLabels()
+		return true
+	})
+}
+
+// Transact records the whole transaction as one step, if it succeeds.
+func (h *History) Transact(fn func(tx Tx) error) (err error) {
+	h.record("Transact(fn)", func() bool {
+		err = h.PrefCode.Transact(fn)
+		return nil == err
+	})
+	return
+}
@@ -0,0 +1,69 @@
+package prefcode
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking Undo and Redo.", func(t *testing.T) {
+		pc, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking History.")
+		}
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		for _, code := range []PrefCode{pc, compact} {
+			h := NewHistory(code, 0)
+			h.ExpandAt("1")
+			h.ExpandAt("1")
+			h.SwapPermAtKeys("0", "11")
+			h.SwapPermAtKeys("0", "2")
+			h.ExpandAt("0")
+			assertCorrectMessage(t, strings.Join(h.Log(), "; "), `ExpandAt("1"); SwapPermAtKeys("0", "11"); ExpandAt("0")`)
+			assertCorrectMessage(t, h.String(), "[00 2], [01 3], [10 1], [11 0]")
+
+			assertCorrectMessage(t, strconv.Itoa(h.Undo(2)), "2")
+			assertCorrectMessage(t, h.String(), "[0 0], [10 1], [11 2]")
+			assertCorrectMessage(t, strconv.Itoa(h.Redo(1)), "1")
+			assertCorrectMessage(t, h.String(), "[0 2], [10 1], [11 0]")
+			assertCorrectMessage(t, strconv.Itoa(h.Undo(5)), "2")
+			assertCorrectMessage(t, h.String(), "[𝛆 0]")
+			assertCorrectMessage(t, strconv.Itoa(h.Redo(5)), "3")
+			assertCorrectMessage(t, h.String(), "[00 2], [01 3], [10 1], [11 0]")
+
+			h.Undo(1)
+			h.ReduceAt("1")
+			assertCorrectMessage(t, strconv.Itoa(h.Redo(1)), "0")
+			assertCorrectMessage(t, h.String(), "[0 1], [1 0]")
+		}
+	})
+
+	t.Run("Checking the limit and Transact.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		h := NewHistory(pc, 2)
+		h.ExpandAt("1")
+		h.ExpandAt("0")
+		h.Transact(func(tx Tx) error {
+			tx.ReduceAt("")
+			return errors.New("Abandoned")
+		})
+		h.Transact(func(tx Tx) error {
+			tx.ReduceAt("1")
+			tx.ReduceAt("0")
+			return nil
+		})
+		assertCorrectMessage(t, strings.Join(h.Log(), "; "), `ExpandAt("0"); Transact(fn)`)
+		assertCorrectMessage(t, strconv.Itoa(h.Undo(3)), "2")
+		assertCorrectMessage(t, h.String(), "[0 0], [10 1], [11 2]")
+	})
+}
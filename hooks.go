@@ -0,0 +1,107 @@
+package prefcode
+
+// hooks holds the observers of a code, called after each change so that
+// views and indexes built on the code can follow it without polling.
+type hooks struct {
+	expand  []func(at string, newLeaves []string)
+	reduce  []func(at string, oldLeaves []string)
+	relabel []func(perm Perm)
+}
+
+// OnExpand adds fn to the functions called after each expansion: at is the
+// leaf which became a caret (EmptyString at the root), newLeaves the leaves
+// replacing it, in dictionary order.  ExpandAt at a word below a leaf of the
+// trivial code makes two expansions, the root first.  The batch ExpandAtAll
+// reports its expansions one at a time.
+func (p *prefixCode) OnExpand(fn func(at string, newLeaves []string)) {
+	p.hooks.expand = append(p.hooks.expand, fn)
+}
+
+// OnReduce adds fn to the functions called after each reduction: at is the
+// new leaf, oldLeaves the leaves it replaced, in dictionary order.
+func (p *prefixCode) OnReduce(fn func(at string, oldLeaves []string)) {
+	p.hooks.reduce = append(p.hooks.reduce, fn)
+}
+
+// OnRelabel adds fn to the functions called after the labels change with the
+// tree staying put, as under ApplyPerm, SwapPermAtKeys and the relabellings
+// of labels.go, with the new Permutation.  The shifts of labels made by
+// ExpandAt and ReduceAt are not reported here.
+//
+// No hook is called by SetCode, SetAlphabet or anything else rebuilding the
+// code wholesale.  Hooks run in the order they were added and must not change
+// p.  Clones do not keep them, but p keeps them through the undo of History
+// and the rollback of Transact.
+func (p *prefixCode) OnRelabel(fn func(perm Perm)) {
+	p.hooks.relabel = append(p.hooks.relabel, fn)
+}
+
+// hooked reports whether any expansion or reduction hook is set, so batches
+// must go one step at a time.
+func (p *prefixCode) hooked() bool {
+	return 0 < len(p.hooks.expand) || 0 < len(p.hooks.reduce)
+}
+
+// expanded, reduced and relabelled come at the end of each change, checking
+// the invariants if asked (see invariants.go) and then calling the hooks.
+
+func (p *prefixCode) expanded(at string, leaves []string) {
+	p.verify("expansion at " + at)
+	for _, fn := range p.hooks.expand {
+		fn(at, append([]string(nil), leaves...))
+	}
+}
+
+func (p *prefixCode) reduced(at string, below []string) {
+	p.verify("reduction at " + at)
+	if 0 == len(p.hooks.reduce) {
+		return
+	}
+	leaves := append([]string(nil), below...)
+	p.sortWords(leaves)
+	for _, fn := range p.hooks.reduce {
+		fn(at, append([]string(nil), leaves...))
+	}
+}
+
+func (p *prefixCode) relabelled() {
+	p.verify("relabelling")
+	for _, fn := range p.hooks.relabel {
+		fn(p.Permutation())
+	}
+}
+
+// deferTo returns hooks which, instead of calling those of h, queue the calls
+// on pending, so Transact can make them only once it succeeds.  A kind of hook
+// h does not have stays empty, so batches still go at once.
+func (h hooks) deferTo(pending *[]func()) hooks {
+	var d hooks
+	if 0 < len(h.expand) {
+		d.expand = []func(string, []string){func(at string, newLeaves []string) {
+			*pending = append(*pending, func() {
+				for _, fn := range h.expand {
+					fn(at, append([]string(nil), newLeaves...))
+				}
+			})
+		}}
+	}
+	if 0 < len(h.reduce) {
+		d.reduce = []func(string, []string){func(at string, oldLeaves []string) {
+			*pending = append(*pending, func() {
+				for _, fn := range h.reduce {
+					fn(at, append([]string(nil), oldLeaves...))
+				}
+			})
+		}}
+	}
+	if 0 < len(h.relabel) {
+		d.relabel = []func(Perm){func(perm Perm) {
+			*pending = append(*pending, func() {
+				for _, fn := range h.relabel {
+					fn(perm)
+				}
+			})
+		}}
+	}
+	return d
+}
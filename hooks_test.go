@@ -0,0 +1,100 @@
+package prefcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking hooks follow expansions, reductions and relabellings.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking hooks.")
+		}
+		var events []string
+		baseCode.OnExpand(func(at string, newLeaves []string) {
+			events = append(events, "expand "+at+" "+fmt.Sprint(newLeaves))
+		})
+		baseCode.OnReduce(func(at string, oldLeaves []string) {
+			events = append(events, "reduce "+at+" "+fmt.Sprint(oldLeaves))
+		})
+		baseCode.OnRelabel(func(perm Perm) {
+			events = append(events, "relabel "+fmt.Sprint(perm))
+		})
+
+		baseCode.ExpandAt("1")
+		baseCode.ReduceAt("1")
+		baseCode.SwapPermAtKeys("0", "1")
+		baseCode.ExpandAtAll([]string{"0", "1"})
+		baseCode.ReduceAt("")
+		assertCorrectMessage(t, strings.Join(events, "; "),
+			"expand 𝛆 [0 1]; expand 1 [10 11]; reduce 1 [10 11]; relabel (0 1); "+
+				"expand 0 [00 01]; expand 1 [10 11]; reduce 𝛆 [00 01 10 11]")
+	})
+
+	t.Run("Checking a hook sees the code after the change.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking hooks see the change.")
+		}
+		baseCode.ExpandAt("1")
+		var seen []string
+		baseCode.OnReduce(func(at string, _ []string) {
+			seen = append(seen, baseCode.String())
+		})
+		baseCode.OnRelabel(func(Perm) {
+			seen = append(seen, baseCode.String())
+		})
+		baseCode.ReduceAt("1")
+		baseCode.RelabelBy(func(a, b string) bool { return a > b })
+		assertCorrectMessage(t, strings.Join(seen, "; "), "[0 0], [1 1]; [0 1], [1 0]")
+	})
+
+	t.Run("Checking hooks are told only of changes to their own code.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking hooks of copies.")
+		}
+		baseCode.ExpandAt("1")
+		var events []string
+		baseCode.OnExpand(func(at string, newLeaves []string) {
+			events = append(events, "expand "+at+" "+fmt.Sprint(newLeaves))
+		})
+		baseCode.OnReduce(func(at string, oldLeaves []string) {
+			events = append(events, "reduce "+at+" "+fmt.Sprint(oldLeaves))
+		})
+
+		for range baseCode.Neighbors(nil) {
+		}
+		Freeze(baseCode).Expand("0")
+		baseCode.Transact(func(tx Tx) error {
+			tx.ExpandAt("0")
+			return errors.New("Rolled back")
+		})
+		assertCorrectMessage(t, strings.Join(events, "; "), "")
+
+		baseCode.Transact(func(tx Tx) error {
+			tx.ExpandAt("0")
+			tx.ReduceAt("1")
+			return nil
+		})
+		assertCorrectMessage(t, strings.Join(events, "; "), "expand 0 [00 01]; reduce 1 [10 11]")
+
+		events = nil
+		h := NewHistory(baseCode, 0)
+		h.ExpandAt("00")
+		h.Undo(1)
+		h.ExpandAt("01")
+		assertCorrectMessage(t, strings.Join(events, "; "), "expand 00 [000 001]; expand 01 [010 011]")
+	})
+}
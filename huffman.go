@@ -0,0 +1,228 @@
+package prefcode
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+)
+
+// NewHuffmanCode returns an optimal (n-ary Huffman) prefix code over alpha for
+// source symbols with the given frequencies.  The leaf coding symbol ii carries
+// label ii.  When the number of symbols does not fill a complete n-ary tree,
+// zero-frequency dummy symbols are padded in; their leaves carry the labels
+// len(freqs), len(freqs)+1, ... so the labels stay a permutation.
+func NewHuffmanCode(alpha []rune, freqs []float64) (PrefCode, error) {
+	prefc, err := NewPrefCodeAlphaRunes(alpha)
+	if err != nil {
+		return prefc, err
+	}
+	if err := checkFreqs(len(alpha), freqs); err != nil {
+		return prefc, err
+	}
+	if 1 == len(freqs) {
+		return prefc, nil
+	}
+
+	n := len(alpha)
+	// pad so that (symbols - 1) is a multiple of (n - 1).
+	symbols := len(freqs)
+	for 0 != (symbols-1)%(n-1) {
+		symbols++
+	}
+
+	nodes := make([]huffNode, 0, symbols+(symbols-1)/(n-1))
+	queue := make(huffQueue, 0, symbols)
+	for ii := 0; ii < symbols; ii++ {
+		weight := 0.0
+		if ii < len(freqs) {
+			weight = freqs[ii]
+		}
+		nodes = append(nodes, huffNode{weight: weight, symbol: ii})
+		queue = append(queue, huffItem{weight: weight, node: ii})
+	}
+	heap.Init(&queue)
+
+	for queue.Len() > 1 {
+		merged := huffNode{symbol: FAILURE}
+		for ii := 0; ii < n; ii++ {
+			item := heap.Pop(&queue).(huffItem)
+			merged.weight += item.weight
+			merged.children = append(merged.children, item.node)
+		}
+		nodes = append(nodes, merged)
+		heap.Push(&queue, huffItem{weight: merged.weight, node: len(nodes) - 1})
+	}
+
+	prefc.code = make(map[string]int, symbols)
+	assignHuffWords(prefc, nodes, len(nodes)-1, "")
+	prefc.reindex()
+	return prefc, nil
+}
+
+// checkFreqs verifies a frequency list is usable for a code over an alphabet
+// of size alphaSize.
+func checkFreqs(alphaSize int, freqs []float64) error {
+	if 0 == len(freqs) {
+		return errors.New("No frequencies given")
+	}
+	for ii, f := range freqs {
+		if f < 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+			return errors.New("Bad frequency at index " + strconv.Itoa(ii))
+		}
+	}
+	if 1 == alphaSize && len(freqs) > 1 {
+		return errors.New("Cannot code several symbols over a one letter alphabet")
+	}
+	return nil
+}
+
+// assignHuffWords walks the Huffman tree from node, writing the word of each
+// leaf into p with the leaf's symbol as label.  Skewed frequencies make deep
+// trees, so it keeps its own stack rather than recursing.
+func assignHuffWords(p *prefixCode, nodes []huffNode, node int, word string) {
+	type entry struct {
+		node int
+		word string
+	}
+	stack := []entry{{node, word}}
+	for 0 < len(stack) {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if FAILURE != nodes[e.node].symbol {
+			p.code[e.word] = nodes[e.node].symbol
+			continue
+		}
+		for ii, child := range nodes[e.node].children {
+			stack = append(stack, entry{child, e.word + string(p.alphabet[ii])})
+		}
+	}
+}
+
+// huffNode is a symbol (leaf) or a merged node of the Huffman tree.
+type huffNode struct {
+	weight   float64
+	symbol   int // FAILURE for merged nodes
+	children []int
+}
+
+// huffItem is an entry of the Huffman priority queue; ties in weight are
+// broken by node index so the construction is deterministic.
+type huffItem struct {
+	weight float64
+	node   int
+}
+
+type huffQueue []huffItem
+
+func (q huffQueue) Len() int { return len(q) }
+func (q huffQueue) Less(i, j int) bool {
+	if q[i].weight != q[j].weight {
+		return q[i].weight < q[j].weight
+	}
+	return q[i].node < q[j].node
+}
+func (q huffQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *huffQueue) Push(x any)   { *q = append(*q, x.(huffItem)) }
+func (q *huffQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// NewLengthLimitedHuffman returns an optimal prefix code over alpha for the
+// given symbol frequencies among the codes with no codeword longer than
+// maxDepth.  The lengths are found by the package-merge algorithm (packaging
+// n items at a time for an alphabet of size n, after the same dummy padding as
+// NewHuffmanCode) and the code is then the canonical code with those lengths,
+// as built by NewCodeFromLengths.  Labels are the symbol indices.
+func NewLengthLimitedHuffman(alpha []rune, freqs []float64, maxDepth int) (PrefCode, error) {
+	prefc, err := NewPrefCodeAlphaRunes(alpha)
+	if err != nil {
+		return prefc, err
+	}
+	if err := checkFreqs(len(alpha), freqs); err != nil {
+		return prefc, err
+	}
+	if 1 == len(freqs) {
+		return prefc, nil
+	}
+
+	n := len(alpha)
+	symbols := len(freqs)
+	for 0 != (symbols-1)%(n-1) {
+		symbols++
+	}
+	if maxDepth < 1 || big.NewInt(int64(symbols)).Cmp(new(big.Int).Exp(big.NewInt(int64(n)), big.NewInt(int64(maxDepth)), nil)) > 0 {
+		return prefc, errors.New("Cannot fit " + strconv.Itoa(symbols) + " codewords within depth " + strconv.Itoa(maxDepth))
+	}
+
+	// the original items, cheapest first.
+	var items []pmItem
+	for ii := 0; ii < symbols; ii++ {
+		weight := 0.0
+		if ii < len(freqs) {
+			weight = freqs[ii]
+		}
+		items = append(items, pmItem{weight: weight, symbol: ii})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].weight < items[j].weight })
+
+	// each round packages the list n at a time and merges the original items
+	// back in; the packages remember their parts so lengths can be read off.
+	list := items
+	for level := 1; level < maxDepth; level++ {
+		var packages []pmItem
+		for ii := 0; ii+n <= len(list); ii += n {
+			pkg := pmItem{symbol: FAILURE, parts: list[ii : ii+n]}
+			for _, part := range pkg.parts {
+				pkg.weight += part.weight
+			}
+			packages = append(packages, pkg)
+		}
+		list = mergeItems(items, packages)
+	}
+
+	lengths := make([]int, symbols)
+	for _, item := range list[:(symbols-1)*n/(n-1)] {
+		item.countInto(lengths)
+	}
+	return NewCodeFromLengths(alpha, lengths)
+}
+
+// pmItem is an original symbol or a package of the package-merge algorithm.
+type pmItem struct {
+	weight float64
+	symbol int // FAILURE for packages
+	parts  []pmItem
+}
+
+// countInto adds one to the length of every symbol occurring in the item.
+func (item pmItem) countInto(lengths []int) {
+	if FAILURE != item.symbol {
+		lengths[item.symbol]++
+		return
+	}
+	for _, part := range item.parts {
+		part.countInto(lengths)
+	}
+}
+
+// mergeItems merges two weight-sorted lists, originals first on ties.
+func mergeItems(a, b []pmItem) []pmItem {
+	merged := make([]pmItem, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if b[0].weight < a[0].weight {
+			merged = append(merged, b[0])
+			b = b[1:]
+			continue
+		}
+		merged = append(merged, a[0])
+		a = a[1:]
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
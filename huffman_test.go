@@ -0,0 +1,80 @@
+package prefcode
+
+import (
+	"testing"
+)
+
+func TestHuffman(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking NewHuffmanCode binary.", func(t *testing.T) {
+		hc, err := NewHuffmanCode([]rune("01"), []float64{0.5, 0.25, 0.125, 0.125})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewHuffmanCode in test checking binary Huffman.")
+		}
+		assertCorrectMessage(t, hc.String(), "[0 0], [10 1], [110 2], [111 3]")
+	})
+
+	// 4 symbols over a ternary alphabet need one dummy, which gets label 4.
+	t.Run("Checking NewHuffmanCode ternary with padding.", func(t *testing.T) {
+		hc, err := NewHuffmanCode([]rune("abc"), []float64{1, 1, 1, 3})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewHuffmanCode in test checking ternary Huffman.")
+		}
+		assertCorrectMessage(t, hc.String(), "[a 2], [ba 4], [bb 0], [bc 1], [c 3]")
+	})
+
+	t.Run("Checking NewHuffmanCode bad input.", func(t *testing.T) {
+		if _, err := NewHuffmanCode([]rune("01"), nil); nil == err {
+			assertCorrectMessage(t, "No error for ", "NewHuffmanCode without frequencies.")
+		}
+		if _, err := NewHuffmanCode([]rune("01"), []float64{1, -1}); nil == err {
+			assertCorrectMessage(t, "No error for ", "NewHuffmanCode with negative frequency.")
+		}
+		single, err := NewHuffmanCode([]rune("01"), []float64{3})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewHuffmanCode for one symbol.")
+		}
+		assertCorrectMessage(t, single.String(), "[𝛆 0]")
+	})
+	// Huffman would give lengths 4, 4, 3, 2, 1; capped at 3 the best is 3, 3, 3, 3, 1.
+	t.Run("Checking NewLengthLimitedHuffman.", func(t *testing.T) {
+		freqs := []float64{1, 1, 2, 4, 8}
+		hc, err := NewHuffmanCode([]rune("01"), freqs)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewHuffmanCode in test checking length limited Huffman.")
+		}
+		assertCorrectMessage(t, hc.String(), "[0 4], [10 3], [110 2], [1110 0], [1111 1]")
+
+		lc, err := NewLengthLimitedHuffman([]rune("01"), freqs, 3)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewLengthLimitedHuffman in test checking length limited Huffman.")
+		}
+		assertCorrectMessage(t, lc.String(), "[0 4], [100 0], [101 1], [110 2], [111 3]")
+
+		// with room to spare package-merge agrees with Huffman on lengths.
+		lc, err = NewLengthLimitedHuffman([]rune("01"), freqs, 10)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewLengthLimitedHuffman with a loose limit.")
+		}
+		assertCorrectMessage(t, lc.String(), "[0 4], [10 3], [110 2], [1110 0], [1111 1]")
+
+		if _, err := NewLengthLimitedHuffman([]rune("01"), freqs, 2); nil == err {
+			assertCorrectMessage(t, "No error for ", "five symbols within depth 2.")
+		}
+	})
+
+	t.Run("Checking NewLengthLimitedHuffman ternary.", func(t *testing.T) {
+		lc, err := NewLengthLimitedHuffman([]rune("abc"), []float64{1, 1, 1, 3}, 2)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewLengthLimitedHuffman in test checking ternary.")
+		}
+		assertCorrectMessage(t, lc.String(), "[a 2], [b 3], [ca 0], [cb 1], [cc 4]")
+	})
+}
@@ -0,0 +1,72 @@
+package prefcode
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// Reading the letters of the alphabet, in letter order (rune order unless the
+// code was made by NewPrefCodeOrdered), as the digits 0 ... n-1
+// of base n, the word w = d1 d2 ... dk names the n-adic interval of [0,1)
+// whose points have expansions starting 0.d1d2...dk.  The leaves of a
+// complete code then partition [0,1), the usual analytic picture of these
+// codes and of the Thompson groups acting on them.
+
+// LeafInterval returns the half-open interval [lo, hi) of leaf, or nil, nil if
+// leaf is not a leaf.  The leaf EmptyString of the trivial code has [0, 1).
+func (p *prefixCode) LeafInterval(leaf string) (lo, hi *big.Rat) {
+	if _, ok := p.code[leaf]; !ok {
+		return nil, nil
+	}
+	return wordInterval(p.letters(), leaf)
+}
+
+// LeafContaining returns the leaf whose interval contains x, which must lie in
+// [0, 1).  Intervals are half-open, so an n-adic endpoint belongs to the leaf
+// on its right.  The trie is walked digit by digit, x being scaled by n at
+// each step.
+func (p *prefixCode) LeafContaining(x *big.Rat) (string, error) {
+	if x.Sign() < 0 || x.Cmp(big.NewRat(1, 1)) >= 0 {
+		return "", errors.New("Point " + x.RatString() + " is not in [0,1)")
+	}
+	if p.trie.leaf {
+		return EmptyString, nil
+	}
+	sorted := p.letters()
+	base := big.NewInt(int64(len(sorted)))
+	num, den := new(big.Int).Set(x.Num()), x.Denom()
+	digit := new(big.Int)
+	var word strings.Builder
+	for node := p.trie; !node.leaf; {
+		// x*n = digit + rest, with num/den the rest.
+		digit.QuoRem(num.Mul(num, base), den, num)
+		r := sorted[digit.Int64()]
+		word.WriteRune(r)
+		if node = node.children[r]; nil == node {
+			return "", errors.New("Code is not complete at " + word.String())
+		}
+	}
+	return word.String(), nil
+}
+
+// wordInterval returns the interval of word over letters, in letter order.
+func wordInterval(letters []rune, word string) (lo, hi *big.Rat) {
+	digits := alphabetDigits(letters)
+	base := big.NewInt(int64(len(letters)))
+	num, den := new(big.Int), big.NewInt(1)
+	if EmptyString != word {
+		for _, r := range word {
+			num.Mul(num, base).Add(num, big.NewInt(int64(digits[r])))
+			den.Mul(den, base)
+		}
+	}
+	lo = new(big.Rat).SetFrac(num, den)
+	hi = new(big.Rat).SetFrac(new(big.Int).Add(num, big.NewInt(1)), den)
+	return lo, hi
+}
+
+// alphabetDigits returns the digit of each letter, its place in letters.
+func alphabetDigits(letters []rune) map[rune]int {
+	return rankLetters(letters)
+}
@@ -0,0 +1,70 @@
+package prefcode
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+func TestInterval(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking LeafInterval.", func(t *testing.T) {
+		pc, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking LeafInterval.")
+		}
+		lo, hi := pc.LeafInterval(EmptyString)
+		assertCorrectMessage(t, lo.String()+" "+hi.String(), "0/1 1/1")
+		pc.ExpandAt("1")
+		pc.ExpandAt("10")
+		for leaf, want := range map[string]string{"0": "0/1 1/2", "100": "1/2 5/8", "101": "5/8 3/4", "11": "3/4 1/1"} {
+			lo, hi = pc.LeafInterval(leaf)
+			assertCorrectMessage(t, lo.String()+" "+hi.String(), want)
+		}
+		lo, hi = pc.LeafInterval("10")
+		assertCorrectMessage(t, strconv.FormatBool(nil == lo && nil == hi), "true")
+	})
+
+	t.Run("Checking digits follow rune order, not the alphabet slice.", func(t *testing.T) {
+		pc, _ := NewPrefCodeAlphaRunes([]rune("cab"))
+		pc.ExpandAt("")
+		pc.ExpandAt("c")
+		lo, hi := pc.LeafInterval("b")
+		assertCorrectMessage(t, lo.String()+" "+hi.String(), "1/3 2/3")
+		lo, hi = pc.LeafInterval("cb")
+		assertCorrectMessage(t, lo.String()+" "+hi.String(), "7/9 8/9")
+	})
+
+	t.Run("Checking LeafContaining.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		leaf, err := pc.LeafContaining(big.NewRat(2, 3))
+		assertCorrectMessage(t, leaf+" "+fmt.Sprint(err), EmptyString+" <nil>")
+		pc.ExpandAt("1")
+		pc.ExpandAt("10")
+		for x, want := range map[string]string{"0": "0", "1/3": "0", "1/2": "100", "5/8": "101", "2/3": "101", "3/4": "11", "99/100": "11"} {
+			r, _ := new(big.Rat).SetString(x)
+			leaf, err = pc.LeafContaining(r)
+			assertCorrectMessage(t, leaf+" "+fmt.Sprint(err), want+" <nil>")
+			lo, hi := pc.LeafInterval(leaf)
+			assertCorrectMessage(t, strconv.FormatBool(lo.Cmp(r) <= 0 && r.Cmp(hi) < 0), "true")
+		}
+		for _, x := range []*big.Rat{big.NewRat(1, 1), big.NewRat(-1, 3), big.NewRat(7, 5)} {
+			_, err = pc.LeafContaining(x)
+			assertCorrectMessage(t, strconv.FormatBool(nil == err), "false")
+		}
+
+		ternary, _ := NewPrefCodeAlphaString("abc")
+		ternary.ExpandAt("")
+		ternary.ExpandAt("c")
+		leaf, _ = ternary.LeafContaining(big.NewRat(8, 9))
+		assertCorrectMessage(t, leaf, "cc")
+	})
+}
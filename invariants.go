@@ -0,0 +1,123 @@
+package prefcode
+
+import (
+	"errors"
+	"strconv"
+)
+
+// EnableInvariantChecks turns on, or off, checking the invariants of p after
+// every change: after each expansion and reduction, each change of labels
+// and each rebuild of the code, as by SetCode or the batches.  A broken
+// invariant panics with what broke and after what.  The checks take time
+// linear in the size of the code, so this is a mode for developing and
+// testing algorithms on top of the type, not for production.
+//
+// SetAlphabet is not checked, as changing the alphabet and then the code
+// passes through a code over the wrong alphabet.
+func (p *prefixCode) EnableInvariantChecks(on bool) {
+	p.checked = on
+}
+
+// verify panics if invariant checks are on and p breaks an invariant, op
+// saying what was just done.
+func (p *prefixCode) verify(op string) {
+	if !p.checked {
+		return
+	}
+	if err := p.CheckInvariants(); nil != err {
+		panic("Invariant broken after " + op + ": " + err.Error())
+	}
+}
+
+// CheckInvariants returns an error describing the first broken invariant of
+// p, or nil: the leaves must be a complete prefix code over the alphabet
+// (just the empty word for the trivial code), the labels a permutation of
+// 0 ... n-1 (distinct, in sparse mode), and the indexes kept beside the code
+// (the trie, the label index and the exposed carets) must agree with it.
+func (p *prefixCode) CheckInvariants() error {
+	p.syncLabels()
+	leaves := make([]string, 0, len(p.code))
+	for leaf := range p.code {
+		leaves = append(leaves, leaf)
+	}
+	empty := p.emptyWord()
+	if 1 == len(leaves) && empty != leaves[0] {
+		return errors.New("Trivial code has leaf " + strconv.Quote(leaves[0]) + " rather than " + empty)
+	}
+	if _, ok := p.code[empty]; ok && 1 < len(leaves) {
+		return errors.New("Leaf " + empty + " is in a code of " + strconv.Itoa(len(leaves)) + " leaves")
+	}
+	paths := make([]string, len(leaves))
+	for ii, leaf := range leaves {
+		paths[ii] = p.path(leaf)
+	}
+	if _, err := checkCompletePaths(p.alphabet, paths); nil != err {
+		return errors.New("Leaves are not a complete prefix code: " + err.Error())
+	}
+	if err := p.checkLabels(); nil != err {
+		return err
+	}
+
+	onTrie := p.leavesBelow(p.trie, "")
+	if len(onTrie) != len(p.code) {
+		return errors.New("Trie has " + strconv.Itoa(len(onTrie)) + " leaves, the code " + strconv.Itoa(len(p.code)))
+	}
+	for _, leaf := range onTrie {
+		if _, ok := p.code[leaf]; !ok {
+			return errors.New("Trie has leaf " + strconv.Quote(leaf) + " which is not in the code")
+		}
+	}
+
+	exposed := 0
+	for caret := range internalNodes(p) {
+		all := true
+		for _, r := range p.alphabet {
+			if _, ok := p.code[caret+string(r)]; !ok {
+				all = false
+			}
+		}
+		switch _, ok := p.exposed[caret]; {
+		case all && !ok:
+			return errors.New("Caret " + strconv.Quote(caret) + " is exposed but not indexed as such")
+		case !all && ok:
+			return errors.New("Caret " + strconv.Quote(caret) + " is indexed as exposed but is not")
+		}
+		if all {
+			exposed++
+		}
+	}
+	if exposed != len(p.exposed) {
+		return errors.New("Index has " + strconv.Itoa(len(p.exposed)) + " exposed carets, the code " + strconv.Itoa(exposed))
+	}
+	return nil
+}
+
+// checkLabels checks the labels of p and the index from labels to leaves.
+func (p *prefixCode) checkLabels() error {
+	if nil != p.sparse {
+		if len(p.sparse.leaves) != len(p.code) {
+			return errors.New("Sparse index has " + strconv.Itoa(len(p.sparse.leaves)) + " labels, the code " + strconv.Itoa(len(p.code)))
+		}
+		for leaf, label := range p.code {
+			if p.sparse.leaves[label] != leaf {
+				return errors.New("Label " + strconv.Itoa(label) + " of " + strconv.Quote(leaf) + " is repeated or not indexed")
+			}
+			if label < 0 || label >= p.sparse.next {
+				return errors.New("Label " + strconv.Itoa(label) + " of " + strconv.Quote(leaf) + " is out of range")
+			}
+		}
+		return nil
+	}
+	if len(p.leaves) != len(p.code) {
+		return errors.New("Label index has " + strconv.Itoa(len(p.leaves)) + " entries, the code " + strconv.Itoa(len(p.code)))
+	}
+	for leaf, label := range p.code {
+		if label < 0 || label >= len(p.code) {
+			return errors.New("Label " + strconv.Itoa(label) + " of " + strconv.Quote(leaf) + " is out of range 0 ... " + strconv.Itoa(len(p.code)-1))
+		}
+		if p.leaves[label] != leaf {
+			return errors.New("Label " + strconv.Itoa(label) + " of " + strconv.Quote(leaf) + " is repeated or indexed to " + strconv.Quote(p.leaves[label]))
+		}
+	}
+	return nil
+}
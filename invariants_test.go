@@ -0,0 +1,85 @@
+package prefcode
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestInvariants(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	// panicked runs fn, returning what it panicked with, or "".
+	panicked := func(fn func()) (msg string) {
+		defer func() {
+			if r := recover(); nil != r {
+				msg = fmt.Sprint(r)
+			}
+		}()
+		fn()
+		return
+	}
+
+	t.Run("Checking random changes keep the invariants.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1129))
+		for _, sparse := range []bool{false, true} {
+			baseCode, err := NewPrefCodeAlphaString("abc")
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString() in test checking invariants.")
+			}
+			if sparse {
+				baseCode.UseSparseLabels()
+			}
+			baseCode.EnableInvariantChecks(true)
+			msg := panicked(func() {
+				for ii := 0; ii < 200; ii++ {
+					leaves := append([]string(nil), baseCode.sortedKeys()...)
+					leaf := leaves[rng.Intn(len(leaves))]
+					switch rng.Intn(4) {
+					case 0, 1:
+						if EmptyString == leaf {
+							leaf = ""
+						}
+						baseCode.ExpandAt(leaf + "ab"[:rng.Intn(3)])
+					case 2:
+						if carets := baseCode.ExposedCarets(); 0 < len(carets) {
+							baseCode.ReduceAt(carets[rng.Intn(len(carets))])
+						}
+					case 3:
+						baseCode.SwapPermAtKeys(leaf, leaves[rng.Intn(len(leaves))])
+					}
+				}
+				baseCode.ExpandAtAll(baseCode.sortedKeys()[:1])
+			})
+			assertCorrectMessage(t, msg, "")
+			assertCorrectMessage(t, fmt.Sprint(baseCode.SparseLabels()), fmt.Sprint(sparse))
+			assertCorrectMessage(t, fmt.Sprint(baseCode.CheckInvariants()), "<nil>")
+		}
+	})
+
+	t.Run("Checking broken invariants are described.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking broken invariants.")
+		}
+		baseCode.EnableInvariantChecks(true)
+		assertCorrectMessage(t, panicked(func() { baseCode.SetCode(map[string]int{"0": 0, "1": 2}) }),
+			`Invariant broken after rebuild: Label 2 of "1" is out of range 0 ... 1`)
+		assertCorrectMessage(t, panicked(func() { baseCode.SetCode(map[string]int{"0": 0, "10": 1}) }),
+			"Invariant broken after rebuild: Leaves are not a complete prefix code: Kraft sum is 3/4, not 1, so the code is not complete")
+		assertCorrectMessage(t, panicked(func() { baseCode.SetCode(map[string]int{"0": 0}) }),
+			`Invariant broken after rebuild: Trivial code has leaf "0" rather than 𝛆`)
+
+		baseCode.EnableInvariantChecks(false)
+		baseCode.SetCode(map[string]int{"0": 0, "1": 1})
+		assertCorrectMessage(t, fmt.Sprint(baseCode.CheckInvariants()), "<nil>")
+		baseCode.exposed = map[string]struct{}{}
+		assertCorrectMessage(t, fmt.Sprint(baseCode.CheckInvariants()), `Caret "" is exposed but not indexed as such`)
+	})
+}
@@ -0,0 +1,98 @@
+package prefcode
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// KraftSum returns the exact Kraft sum  sum n^-|w|  of words over an alphabet of
+// size alphabetSize, with |w| counted in runes.  EmptyString counts as the
+// empty word.  A prefix code has Kraft sum at most 1, and a finite prefix code
+// is complete exactly when the sum is 1.
+func KraftSum(words []string, alphabetSize int) *big.Rat {
+	return kraftSum(asPaths(words), alphabetSize)
+}
+
+// kraftSum is KraftSum of paths, "" alone being the empty word.
+func kraftSum(paths []string, alphabetSize int) *big.Rat {
+	sum := new(big.Rat)
+	if alphabetSize < 1 {
+		return sum
+	}
+	base := big.NewInt(int64(alphabetSize))
+	denom := new(big.Int)
+	for _, w := range paths {
+		denom.Exp(base, big.NewInt(int64(utf8.RuneCountInString(w))), nil)
+		sum.Add(sum, new(big.Rat).SetFrac(big.NewInt(1), denom))
+	}
+	return sum
+}
+
+// IsCompletePrefixSet reports whether words form a complete (maximal) prefix
+// code over alphabet: no word is a prefix of another and the Kraft sum is 1.
+// Such a set can safely be imported as the leaves of a PrefCode.  An error is
+// returned if the alphabet is unusable or a word uses letters outside it.
+func IsCompletePrefixSet(words []string, alphabet []rune) (bool, error) {
+	sorted, err := sortedWords(words, alphabet)
+	if nil != err {
+		return false, err
+	}
+	if _, _, ok := prefixPair(sorted); ok {
+		return false, nil
+	}
+	return 0 == kraftSum(sorted, len(alphabet)).Cmp(big.NewRat(1, 1)), nil
+}
+
+// asPaths returns a copy of words with EmptyString as "".  The words of a
+// code naming the empty word otherwise (see alphabetPolicy.go) are made
+// paths by the code itself, as 𝛆 may then be a letter.
+func asPaths(words []string) []string {
+	paths := make([]string, len(words))
+	for ii, w := range words {
+		if EmptyString == w {
+			w = ""
+		}
+		paths[ii] = w
+	}
+	return paths
+}
+
+// sortedWords returns words in dictionary order, EmptyString as "", after
+// checking they are over alphabet.
+func sortedWords(words []string, alphabet []rune) ([]string, error) {
+	return sortedPaths(asPaths(words), alphabet)
+}
+
+// sortedPaths sorts paths into dictionary order, after checking they are
+// over alphabet.
+func sortedPaths(paths []string, alphabet []rune) ([]string, error) {
+	if 0 == len(alphabet) {
+		return nil, errors.New("Empty Alphabet forbidden")
+	}
+	letters := string(alphabet)
+	for _, w := range paths {
+		for _, r := range w {
+			if !strings.ContainsRune(letters, r) {
+				return nil, errors.New("Word " + strconv.Quote(w) + " uses letter " + strconv.QuoteRune(r) + " outside the alphabet")
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// prefixPair returns a word of sorted and an extension of it (or a copy), if
+// sorted is not an antichain.  In dictionary order a word is immediately
+// followed by its extensions, so only neighbours need comparing.
+func prefixPair(sorted []string) (string, string, bool) {
+	for ii := 1; ii < len(sorted); ii++ {
+		if strings.HasPrefix(sorted[ii], sorted[ii-1]) {
+			return sorted[ii-1], sorted[ii], true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,40 @@
+package prefcode
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestKraft(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking KraftSum.", func(t *testing.T) {
+		assertCorrectMessage(t, KraftSum([]string{"0", "10", "11"}, 2).String(), "1/1")
+		assertCorrectMessage(t, KraftSum([]string{"0", "10"}, 2).String(), "3/4")
+		assertCorrectMessage(t, KraftSum([]string{"日", "本日"}, 3).String(), "4/9")
+		assertCorrectMessage(t, KraftSum([]string{EmptyString}, 2).String(), "1/1")
+	})
+
+	t.Run("Checking IsCompletePrefixSet.", func(t *testing.T) {
+		check := func(words []string, alpha string) string {
+			ok, err := IsCompletePrefixSet(words, []rune(alpha))
+			if nil != err {
+				return "error"
+			}
+			return strconv.FormatBool(ok)
+		}
+		assertCorrectMessage(t, check([]string{"0", "10", "11"}, "01"), "true")
+		assertCorrectMessage(t, check([]string{"0", "10"}, "01"), "false")
+		// not an antichain.
+		assertCorrectMessage(t, check([]string{"0", "00", "01", "1"}, "01"), "false")
+		assertCorrectMessage(t, check([]string{"1", "1"}, "01"), "false")
+		assertCorrectMessage(t, check([]string{EmptyString}, "01"), "true")
+		assertCorrectMessage(t, check([]string{"0", "12"}, "01"), "error")
+	})
+}
@@ -0,0 +1,188 @@
+package prefcode
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"sort"
+	"strings"
+)
+
+// LabeledCode is a complete prefix code whose leaves carry labels of any type
+// L, such as structs or group elements, rather than ints.
+//
+// The shape is an ordinary prefixCode, and the L labels ride on its int
+// labels: the leaf with int label ii carries payload[ii].  Expansions and
+// reductions splice payload just as they shift the ints, so the int
+// permutation behaviour of PrefCode is what Shape shows, and L labels never
+// need a side table keyed by leaf.
+type LabeledCode[L any] struct {
+	shape   *prefixCode
+	payload []L
+}
+
+// NewLabeledCode returns the trivial code over alpha, its leaf labelled root.
+func NewLabeledCode[L any](alpha []rune, root L) (*LabeledCode[L], error) {
+	pc, err := NewPrefCodeAlphaRunes(alpha)
+	if nil != err {
+		return nil, err
+	}
+	return &LabeledCode[L]{shape: pc, payload: []L{root}}, nil
+}
+
+// NewLabeledFrom returns a LabeledCode shaped as pc, the leaf with int label
+// ii (in pc) labelled label(leaf, ii).
+func NewLabeledFrom[L any](pc PrefCode, label func(leaf string, ii int) L) *LabeledCode[L] {
+	c := &LabeledCode[L]{shape: &prefixCode{alphabet: pc.Alphabet(), code: make(map[string]int, pc.Size())}}
+	for leaf, ii := range pc.Code() {
+		c.shape.code[leaf] = ii
+	}
+	c.shape.reindex()
+	c.payload = make([]L, len(c.shape.leaves))
+	for ii, leaf := range c.shape.leaves {
+		c.payload[ii] = label(leaf, ii)
+	}
+	return c
+}
+
+// Shape returns a copy of the underlying code, with its int labels.
+func (c *LabeledCode[L]) Shape() PrefCode {
+	return c.shape.clone()
+}
+
+func (c *LabeledCode[L]) Alphabet() []rune {
+	return c.shape.Alphabet()
+}
+
+func (c *LabeledCode[L]) Size() int {
+	return len(c.payload)
+}
+
+// Label returns the label of leaf, if leaf is a leaf.
+func (c *LabeledCode[L]) Label(leaf string) (L, bool) {
+	ii := c.shape.LabelAtLeaf(leaf)
+	if FAILURE == ii {
+		var zero L
+		return zero, false
+	}
+	return c.payload[ii], true
+}
+
+// SetLabel labels leaf with label, reporting whether leaf is a leaf.
+func (c *LabeledCode[L]) SetLabel(leaf string, label L) bool {
+	ii := c.shape.LabelAtLeaf(leaf)
+	if FAILURE == ii {
+		return false
+	}
+	c.payload[ii] = label
+	return true
+}
+
+// SwapLabels swaps the labels of the leaves a and b.
+func (c *LabeledCode[L]) SwapLabels(a, b string) error {
+	ia, ib := c.shape.LabelAtLeaf(a), c.shape.LabelAtLeaf(b)
+	if FAILURE == ia || FAILURE == ib {
+		return errors.New("Did not find leaf a or leaf b")
+	}
+	c.payload[ia], c.payload[ib] = c.payload[ib], c.payload[ia]
+	return nil
+}
+
+// ExpandAt expands as PrefCode.ExpandAt does.  The leaf on the way to s is
+// replaced by new leaves, which split labels given its old label and the new
+// leaves in dictionary order; split must return one label per leaf.  With a
+// nil split each new leaf inherits the old label.
+func (c *LabeledCode[L]) ExpandAt(s string, split func(old L, leaves []string) []L) bool {
+	prefix, ok := c.shape.prefixLeaf(s)
+	if !ok {
+		return false
+	}
+	at := c.shape.LabelAtLeaf(prefix)
+	before := c.shape.Size()
+	if !c.shape.ExpandAt(s) {
+		return false
+	}
+	leaves := make([]string, c.shape.Size()-before+1)
+	for jj := range leaves {
+		leaves[jj] = c.shape.LeafAtLabel(at + jj)
+	}
+
+	old := c.payload[at]
+	var labels []L
+	if nil != split {
+		labels = split(old, leaves)
+	}
+	if len(labels) != len(leaves) {
+		labels = make([]L, len(leaves))
+		for jj := range labels {
+			labels[jj] = old
+		}
+	}
+	payload := make([]L, 0, c.shape.Size())
+	payload = append(payload, c.payload[:at]...)
+	payload = append(payload, labels...)
+	c.payload = append(payload, c.payload[at+1:]...)
+	return true
+}
+
+// ReduceAt reduces as PrefCode.ReduceAt does.  The new leaf s is labelled by
+// merge, given the labels of the leaves it replaces in dictionary order.
+// With a nil merge it keeps the label the int labels would keep: that of the
+// least int label.
+func (c *LabeledCode[L]) ReduceAt(s string, merge func(labels []L) L) bool {
+	node := c.shape.findNode(s)
+	if nil == node {
+		return false
+	}
+	below := c.shape.leavesBelow(node, s)
+	c.shape.sortWords(below)
+	gone := make([]int, len(below))
+	labels := make([]L, len(below))
+	for jj, leaf := range below {
+		gone[jj] = c.shape.LabelAtLeaf(leaf)
+		labels[jj] = c.payload[gone[jj]]
+	}
+	if !c.shape.ReduceAt(s) {
+		return false
+	}
+
+	sort.Ints(gone)
+	kept := c.payload[gone[0]]
+	if nil != merge {
+		kept = merge(labels)
+	}
+	payload := make([]L, 0, c.shape.Size())
+	for ii, label := range c.payload {
+		if jj := sort.SearchInts(gone, ii); jj < len(gone) && gone[jj] == ii && 0 < jj {
+			continue
+		}
+		payload = append(payload, label)
+	}
+	payload[gone[0]] = kept
+	c.payload = payload
+	return true
+}
+
+// All yields the leaves with their labels in dictionary order.
+func (c *LabeledCode[L]) All() iter.Seq2[string, L] {
+	return func(yield func(string, L) bool) {
+		for _, leaf := range c.shape.sortedKeys() {
+			if !yield(leaf, c.payload[c.shape.LabelAtLeaf(leaf)]) {
+				return
+			}
+		}
+	}
+}
+
+// String lists the "[leaf label]" pairs in dictionary order, as for a
+// PrefCode, the labels printed with %v.
+func (c *LabeledCode[L]) String() string {
+	var b strings.Builder
+	for leaf, label := range c.All() {
+		if 0 < b.Len() {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "[%s %v]", leaf, label)
+	}
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package prefcode
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLabeled(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	type weight struct {
+		name string
+		mass float64
+	}
+
+	t.Run("Checking struct labels through expansion and reduction.", func(t *testing.T) {
+		lc, err := NewLabeledCode([]rune("01"), weight{"root", 1})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewLabeledCode() in test checking struct labels.")
+		}
+		halve := func(old weight, leaves []string) []weight {
+			labels := make([]weight, len(leaves))
+			for jj, leaf := range leaves {
+				labels[jj] = weight{old.name + "/" + leaf, old.mass / float64(len(leaves))}
+			}
+			return labels
+		}
+		lc.ExpandAt(EmptyString, halve)
+		lc.ExpandAt("1", halve)
+		lc.ExpandAt("10", halve)
+		assertCorrectMessage(t, lc.String(), "[0 {root/0 0.5}], [100 {root/1/10/100 0.125}], [101 {root/1/10/101 0.125}], [11 {root/1/11 0.25}]")
+		assertCorrectMessage(t, lc.Shape().String(), "[0 0], [100 1], [101 2], [11 3]")
+
+		lc.SwapLabels("0", "11")
+		label, ok := lc.Label("0")
+		assertCorrectMessage(t, label.name+" "+strconv.FormatBool(ok), "root/1/11 true")
+		_, ok = lc.Label("10")
+		assertCorrectMessage(t, strconv.FormatBool(ok), "false")
+
+		lc.ReduceAt("1", func(labels []weight) weight {
+			sum := weight{name: "merged"}
+			for _, l := range labels {
+				sum.mass += l.mass
+			}
+			return sum
+		})
+		assertCorrectMessage(t, lc.String(), "[0 {root/1/11 0.25}], [1 {merged 0.75}]")
+		assertCorrectMessage(t, strconv.Itoa(lc.Size()), "2")
+	})
+
+	t.Run("Checking nil policies.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		pc.ExpandAt("1")
+		pc.SwapPermAtKeys("0", "11")
+		lc := NewLabeledFrom(pc, func(leaf string, ii int) string { return "x" + strconv.Itoa(ii) })
+		assertCorrectMessage(t, lc.String(), "[0 x2], [10 x1], [11 x0]")
+		lc.ExpandAt("0", nil)
+		assertCorrectMessage(t, lc.String(), "[00 x2], [01 x2], [10 x1], [11 x0]")
+		lc.ReduceAt("1", nil)
+		assertCorrectMessage(t, lc.String(), "[00 x2], [01 x2], [1 x0]")
+		lc.ReduceAt(EmptyString, nil)
+		assertCorrectMessage(t, lc.String(), "[𝛆 x0]")
+	})
+
+	// labels naming their own leaves must keep doing so.
+	t.Run("Checking labels follow their leaves.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(17))
+		lc, _ := NewLabeledCode([]rune("abc"), EmptyString)
+		name := func(_ string, leaves []string) []string { return leaves }
+		common := func(labels []string) string {
+			prefix := labels[0]
+			for _, l := range labels[1:] {
+				for !strings.HasPrefix(l, prefix) {
+					prefix = prefix[:len(prefix)-1]
+				}
+			}
+			return prefix
+		}
+		for step := 0; step < 300; step++ {
+			leaves := lc.shape.sortedKeys()
+			leaf := leaves[rng.Intn(len(leaves))]
+			switch {
+			case EmptyString == leaf:
+				lc.ExpandAt(leaf, name)
+			case 0 < rng.Intn(3):
+				lc.ExpandAt(leaf+"abc"[rng.Intn(3):][:1], name)
+			case 0 < rng.Intn(2):
+				lc.SwapLabels(leaf, leaves[0])
+				lc.SwapLabels(leaf, leaves[0])
+			default:
+				lc.ReduceAt(trimLastChar(leaf), common)
+			}
+			for leaf, label := range lc.All() {
+				if "" == label {
+					label = EmptyString
+				}
+				assertCorrectMessage(t, label, leaf)
+			}
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package prefcode
+
+import "sort"
+
+// LabelsToLeaves returns a copy of the inverse of the labelling: entry ii is
+// the leaf carrying label ii.
+//
+// In sparse label mode it lists the leaves in increasing order of label.
+func (p *prefixCode) LabelsToLeaves() []string {
+	if nil != p.sparse {
+		return p.sparseLabelsToLeaves()
+	}
+	p.syncLabels()
+	leaves := make([]string, len(p.leaves))
+	copy(leaves, p.leaves)
+	return leaves
+}
+
+// reindex rebuilds the trie, the exposed carets, the label index and the rope
+// from the code map.  It is called whenever the map is built or replaced
+// wholesale.
+func (p *prefixCode) reindex() {
+	p.treeChanged()
+	p.newTrie()
+	p.indexCarets()
+	if nil != p.sparse {
+		p.sparse.index(p.code)
+	} else {
+		p.indexLabels()
+		p.buildRope()
+	}
+	p.verify("rebuild")
+}
+
+// indexLabels rebuilds the label to leaf index from the code map.  Labels
+// outside 0 ... n-1 (only possible after a careless SetCode) are not indexed.
+func (p *prefixCode) indexLabels() {
+	if cap(p.leaves) < len(p.code) {
+		p.leaves = make([]string, len(p.code))
+	}
+	p.leaves = p.leaves[:len(p.code)]
+	for ii := range p.leaves {
+		p.leaves[ii] = ""
+	}
+	for leaf, label := range p.code {
+		if label >= 0 && label < len(p.leaves) {
+			p.leaves[label] = leaf
+		}
+	}
+}
+
+// RelabelLexicographic labels each leaf by its rank in dictionary order, so
+// Permutation becomes the identity.
+func (p *prefixCode) RelabelLexicographic() {
+	p.relabelInOrder(append([]string(nil), p.sortedKeys()...))
+}
+
+// RelabelBy labels each leaf by its rank under less, leaves which less does
+// not order keeping dictionary order.
+func (p *prefixCode) RelabelBy(less func(a, b string) bool) {
+	leaves := append([]string(nil), p.sortedKeys()...)
+	sort.SliceStable(leaves, func(i, j int) bool { return less(leaves[i], leaves[j]) })
+	p.relabelInOrder(leaves)
+}
+
+// InvertLabels replaces Permutation by its inverse: the ii-th leaf in
+// dictionary order gets label jj, where the jj-th leaf had label ii.  Sparse
+// labels are normalized first.
+func (p *prefixCode) InvertLabels() {
+	if nil != p.sparse {
+		p.NormalizeLabels()
+	}
+	keys := p.sortedKeys()
+	leaves := make([]string, len(keys))
+	for ii, label := range p.sortedLabels() {
+		leaves[ii] = keys[label]
+	}
+	p.relabelInOrder(leaves)
+}
+
+// relabelInOrder labels leaves[ii] with ii.  The labels are dense after.
+func (p *prefixCode) relabelInOrder(leaves []string) {
+	p.syncLabels()
+	for ii, leaf := range leaves {
+		p.code[leaf] = ii
+	}
+	p.sparse = nil
+	p.labelsChanged()
+	p.indexLabels()
+	p.buildRope()
+	p.relabelled()
+}
@@ -0,0 +1,80 @@
+package prefcode
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLabels(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	// the inverse index must follow every mutator.
+	t.Run("Checking LabelsToLeaves follows mutations.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking LabelsToLeaves.")
+		}
+		assertCorrectMessage(t, strings.Join(baseCode.LabelsToLeaves(), ","), EmptyString)
+
+		baseCode.ExpandAt("1001")
+		assertCorrectMessage(t, strings.Join(baseCode.LabelsToLeaves(), ","), "0,1000,10010,10011,101,11")
+
+		baseCode.SwapPermAtKeys("0", "11")
+		assertCorrectMessage(t, strings.Join(baseCode.LabelsToLeaves(), ","), "11,1000,10010,10011,101,0")
+		assertCorrectMessage(t, baseCode.LeafAtLabel(5), "0")
+
+		baseCode.ApplyPerm(map[int]int{0: 3, 1: 5, 2: 1, 3: 0, 4: 2, 5: 4})
+		assertCorrectMessage(t, strings.Join(baseCode.LabelsToLeaves(), ","), "10011,10010,101,11,0,1000")
+
+		// the returned slice is a copy.
+		baseCode.LabelsToLeaves()[0] = "junk"
+		assertCorrectMessage(t, baseCode.LeafAtLabel(0), "10011")
+
+		baseCode.ExpandAt("11")
+		assertCorrectMessage(t, strings.Join(baseCode.LabelsToLeaves(), ","), "10011,10010,101,110,111,0,1000")
+		baseCode.ReduceAt("11")
+		assertCorrectMessage(t, strings.Join(baseCode.LabelsToLeaves(), ","), "10011,10010,101,11,0,1000")
+	})
+
+	t.Run("Checking relabeling helpers.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking relabeling.")
+		}
+		baseCode.ExpandAt("10")
+		cycle, _ := ParsePerm("(0 1 2)", 4)
+		baseCode.ApplyPerm(cycle)
+		cpc := NewCompactFrom(baseCode)
+
+		baseCode.InvertLabels()
+		cpc.InvertLabels()
+		assertCorrectMessage(t, baseCode.Permutation().String(), "(0 2 1)")
+		assertCorrectMessage(t, cpc.String(), baseCode.String())
+
+		// longest first, then dictionary order.
+		longer := func(a, b string) bool { return len(a) > len(b) }
+		baseCode.RelabelBy(longer)
+		cpc.RelabelBy(longer)
+		assertCorrectMessage(t, baseCode.String(), "[0 3], [100 0], [101 1], [11 2]")
+		assertCorrectMessage(t, cpc.String(), baseCode.String())
+		assertCorrectMessage(t, baseCode.LeafAtLabel(2), "11")
+
+		baseCode.RelabelLexicographic()
+		cpc.RelabelLexicographic()
+		assertCorrectMessage(t, baseCode.Permutation().String(), "()")
+		assertCorrectMessage(t, cpc.String(), baseCode.String())
+
+		baseCode.UseSparseLabels()
+		baseCode.ExpandAt("0")
+		baseCode.RelabelLexicographic()
+		assertCorrectMessage(t, strconv.FormatBool(baseCode.SparseLabels()), "false")
+		assertCorrectMessage(t, baseCode.String(), "[00 0], [01 1], [100 2], [101 3], [11 4]")
+	})
+}
@@ -0,0 +1,103 @@
+package prefcode
+
+import "strings"
+
+// LeavesInRange returns, in dictionary order, the leaves w of the code with
+// lo <= w < hi.  Only the leaves in the window are sorted.  The leaf
+// EmptyString of the trivial code compares as the empty word.
+func (p *prefixCode) LeavesInRange(lo, hi string) []string {
+	var leaves []string
+	for leaf := range p.code {
+		w := leaf
+		if EmptyString == w {
+			w = ""
+		}
+		if !p.wordLess(w, lo) && p.wordLess(w, hi) {
+			leaves = append(leaves, leaf)
+		}
+	}
+	p.sortWords(leaves)
+	return leaves
+}
+
+// SortedLeaves returns the leaves of p in dictionary order.
+func (p *prefixCode) SortedLeaves() []string {
+	return append([]string(nil), p.sortedKeys()...)
+}
+
+// Entries returns the leaves of p with their labels, in dictionary order of
+// the leaves.
+func (p *prefixCode) Entries() []LeafEntry {
+	labels := p.sortedLabels()
+	entries := make([]LeafEntry, len(labels))
+	for ii, leaf := range p.sortedKeys() {
+		entries[ii] = LeafEntry{Leaf: leaf, Label: labels[ii]}
+	}
+	return entries
+}
+
+func (c *CompactPrefCode) SortedLeaves() []string {
+	leaves := make([]string, 0, c.Size())
+	c.walk(func(word []rune, _ int, leaf bool) bool {
+		if leaf {
+			leaves = append(leaves, wordString(word))
+		}
+		return true
+	})
+	return leaves
+}
+
+func (c *CompactPrefCode) Entries() []LeafEntry {
+	leaves := c.SortedLeaves()
+	entries := make([]LeafEntry, len(leaves))
+	for ii, leaf := range leaves {
+		entries[ii] = LeafEntry{Leaf: leaf, Label: int(c.perm[ii])}
+	}
+	return entries
+}
+
+// Page returns up to limit leaves of p with their labels, the first leaves
+// coming after afterLeaf in dictionary order, or the first leaves of all if
+// afterLeaf is "".  Passing the last leaf of a page gets the next, so a
+// listing can be served a page at a time; afterLeaf need not still be a leaf.
+// EmptyString, the leaf of the trivial code, comes before every other word.
+// Only the part of the tree on the way to the page is walked.
+func (p *prefixCode) Page(afterLeaf string, limit int) []LeafEntry {
+	return page(p.WalkDFS, p.wordLess, afterLeaf, limit)
+}
+
+func (c *CompactPrefCode) Page(afterLeaf string, limit int) []LeafEntry {
+	return page(c.WalkDFS, func(a, b string) bool { return a < b }, afterLeaf, limit)
+}
+
+func page(walk func(fn func(node string, isLeaf bool, label int) error) error, less func(a, b string) bool, after string, limit int) []LeafEntry {
+	if limit <= 0 {
+		return nil
+	}
+	start := "" == after
+	if EmptyString == after {
+		after = ""
+	}
+	var entries []LeafEntry
+	walk(func(node string, isLeaf bool, label int) error {
+		w := node
+		if EmptyString == w {
+			w = ""
+		}
+		if !isLeaf {
+			if !strings.HasPrefix(after, w) && less(w, after) {
+				return ErrSkipSubtree
+			}
+			return nil
+		}
+		if !start && !less(after, w) {
+			return nil
+		}
+		entries = append(entries, LeafEntry{Leaf: node, Label: label})
+		if len(entries) == limit {
+			return ErrStopWalk
+		}
+		return nil
+	})
+	return entries
+}
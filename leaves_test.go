@@ -0,0 +1,61 @@
+package prefcode
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLeaves(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking LeavesInRange.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking LeavesInRange.")
+		}
+		assertCorrectMessage(t, strings.Join(baseCode.LeavesInRange("", "1"), ","), EmptyString)
+
+		baseCode.ExpandAt("1001")
+		assertCorrectMessage(t, strings.Join(baseCode.LeavesInRange("1", "11"), ","), "1000,10010,10011,101")
+		assertCorrectMessage(t, strings.Join(baseCode.LeavesInRange("1001", "101"), ","), "10010,10011")
+		assertCorrectMessage(t, strings.Join(baseCode.LeavesInRange("11", "11"), ","), "")
+		assertCorrectMessage(t, strings.Join(baseCode.LeavesInRange("", "2"), ","), "0,1000,10010,10011,101,11")
+	})
+
+	t.Run("Checking SortedLeaves and Entries.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		for _, code := range []PrefCode{pc, compact} {
+			assertCorrectMessage(t, strings.Join(code.SortedLeaves(), ","), EmptyString)
+			code.ExpandAt("10")
+			code.SwapPermAtKeys("0", "11")
+			assertCorrectMessage(t, strings.Join(code.SortedLeaves(), ","), "0,100,101,11")
+			assertCorrectMessage(t, fmt.Sprint(code.Entries()), "[{0 3} {100 1} {101 2} {11 0}]")
+		}
+	})
+
+	t.Run("Checking Page.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		for _, code := range []PrefCode{pc, compact} {
+			assertCorrectMessage(t, fmt.Sprint(code.Page("", 5)), "[{𝛆 0}]")
+			assertCorrectMessage(t, fmt.Sprint(code.Page(EmptyString, 5)), "[]")
+			code.ExpandToDepth(3)
+			assertCorrectMessage(t, fmt.Sprint(code.Page("", 3)), "[{000 0} {001 1} {010 2}]")
+			assertCorrectMessage(t, fmt.Sprint(code.Page("010", 3)), "[{011 3} {100 4} {101 5}]")
+			assertCorrectMessage(t, fmt.Sprint(code.Page("101", 3)), "[{110 6} {111 7}]")
+			assertCorrectMessage(t, fmt.Sprint(code.Page("111", 3)), "[]")
+			assertCorrectMessage(t, fmt.Sprint(code.Page("0", 2)), "[{000 0} {001 1}]")
+			assertCorrectMessage(t, fmt.Sprint(code.Page("0101", 1)), "[{011 3}]")
+			assertCorrectMessage(t, fmt.Sprint(code.Page(EmptyString, 1)), "[{000 0}]")
+			assertCorrectMessage(t, fmt.Sprint(code.Page("", 0)), "[]")
+		}
+	})
+}
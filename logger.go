@@ -0,0 +1,42 @@
+package prefcode
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// The package logs what it used to print: failures it reports only by a
+// false return, as in DFSToPrefCode, at Warn, and the steps of the longer
+// builds at Debug.  Records carry the operation (op), the word or input
+// concerned and the size of the code where they make sense.  Nothing is
+// logged until SetLogger is given a logger.
+
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	SetLogger(nil)
+}
+
+// SetLogger sends the diagnostics of the package to l.  A nil l discards
+// them, which is the default.  It may be called at any time, from any
+// goroutine.
+func SetLogger(l *slog.Logger) {
+	if nil == l {
+		l = slog.New(discardHandler{})
+	}
+	logger.Store(l)
+}
+
+// Logger returns the logger set by SetLogger.
+func Logger() *slog.Logger {
+	return logger.Load()
+}
+
+// discardHandler is enabled at no level, so records are never built.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
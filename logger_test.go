@@ -0,0 +1,61 @@
+package prefcode
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	// lines logs to a buffer without times, returning what was logged.
+	lines := func(level slog.Level, run func()) string {
+		var b bytes.Buffer
+		SetLogger(slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{
+			Level: level,
+			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+				if slog.TimeKey == a.Key {
+					return slog.Attr{}
+				}
+				return a
+			},
+		})))
+		defer SetLogger(nil)
+		run()
+		return strings.TrimSpace(b.String())
+	}
+
+	t.Run("Checking diagnostics go to the logger set.", func(t *testing.T) {
+		got := lines(slog.LevelInfo, func() {
+			DFSToPrefCode(nil, "100")
+			baseCode, _ := NewPrefCode()
+			DFSToPrefCode(baseCode, "1")
+			baseCode.ApplyPerm(Perm{0: 0, 1: 1})
+		})
+		assertCorrectMessage(t, got, strings.Join([]string{
+			`level=WARN msg="called with nil PrefCode" op=DFSToPrefCode dfs=100`,
+			`level=WARN msg="invalid DFS sequence" op=DFSToPrefCode dfs=1 letters=2`,
+			`level=WARN msg="permutation of the wrong size" op=ApplyPerm perm=2 size=1`,
+		}, "\n"))
+	})
+
+	t.Run("Checking debug records and the default discard.", func(t *testing.T) {
+		got := lines(slog.LevelDebug, func() {
+			baseCode, _ := NewPrefCode()
+			DFSToPrefCode(baseCode, "100")
+		})
+		assertCorrectMessage(t, got, `level=DEBUG msg=expanded op=DFSToPrefCode word="" size=2`)
+
+		assertCorrectMessage(t, fmt.Sprint(Logger().Enabled(context.Background(), slog.LevelError)), "false")
+	})
+}
@@ -0,0 +1,79 @@
+package prefcode
+
+import (
+	"math"
+	"math/big"
+	"sort"
+)
+
+// Measure returns the mass, under the Bernoulli (product) measure in which
+// each letter is drawn independently, of the union of the cylinders of the
+// words in leaves: probs[ii] is the probability of the ii-th letter in the
+// letter order of p (see order.go), as the digits of LeafInterval.  Words
+// lying below others in the set are only counted once.  It returns NaN if
+// len(probs) is not the alphabet size or a word is not over the alphabet.
+func (p *prefixCode) Measure(leaves []string, probs []float64) float64 {
+	if len(probs) != len(p.alphabet) {
+		return math.NaN()
+	}
+	digits := alphabetDigits(p.letters())
+	mass := 0.0
+	for _, word := range cylinderRoots(leaves) {
+		m := 1.0
+		for _, r := range word {
+			ii, ok := digits[r]
+			if !ok {
+				return math.NaN()
+			}
+			m *= probs[ii]
+		}
+		mass += m
+	}
+	return mass
+}
+
+// MeasureRat is Measure in exact arithmetic, returning nil where Measure
+// returns NaN.
+func (p *prefixCode) MeasureRat(leaves []string, probs []*big.Rat) *big.Rat {
+	if len(probs) != len(p.alphabet) {
+		return nil
+	}
+	digits := alphabetDigits(p.letters())
+	mass := new(big.Rat)
+	for _, word := range cylinderRoots(leaves) {
+		m := big.NewRat(1, 1)
+		for _, r := range word {
+			ii, ok := digits[r]
+			if !ok {
+				return nil
+			}
+			m.Mul(m, probs[ii])
+		}
+		mass.Add(mass, m)
+	}
+	return mass
+}
+
+// cylinderRoots returns the words of leaves with no proper prefix (nor copy)
+// among them, so their cylinders are disjoint with the same union.
+// EmptyString, the whole space, is returned as "".
+func cylinderRoots(leaves []string) []string {
+	words := make([]string, len(leaves))
+	for ii, w := range leaves {
+		if EmptyString == w {
+			w = ""
+		}
+		words[ii] = w
+	}
+	sort.Strings(words)
+	var roots []string
+	for _, w := range words {
+		// in sorted order a word follows any prefix of it, with only
+		// words below that prefix in between.
+		if n := len(roots); 0 < n && len(roots[n-1]) <= len(w) && roots[n-1] == w[:len(roots[n-1])] {
+			continue
+		}
+		roots = append(roots, w)
+	}
+	return roots
+}
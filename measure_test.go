@@ -0,0 +1,46 @@
+package prefcode
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+func TestMeasure(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	pc, err := NewPrefCode()
+	if nil != err {
+		assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking Measure.")
+	}
+	pc.ExpandAt("1")
+	pc.ExpandAt("10")
+
+	t.Run("Checking Measure.", func(t *testing.T) {
+		probs := []float64{0.25, 0.75}
+		assertCorrectMessage(t, strconv.FormatFloat(pc.Measure([]string{"0", "100"}, probs), 'f', -1, 64), "0.296875")
+		assertCorrectMessage(t, strconv.FormatFloat(pc.Measure([]string{"0", "100", "101", "11"}, probs), 'f', -1, 64), "1")
+		assertCorrectMessage(t, strconv.FormatFloat(pc.Measure([]string{"1", "101", "11", "1"}, probs), 'f', -1, 64), "0.75")
+		assertCorrectMessage(t, strconv.FormatFloat(pc.Measure([]string{EmptyString, "0"}, probs), 'f', -1, 64), "1")
+		assertCorrectMessage(t, strconv.FormatFloat(pc.Measure(nil, probs), 'f', -1, 64), "0")
+		assertCorrectMessage(t, strconv.FormatBool(math.IsNaN(pc.Measure([]string{"0"}, []float64{1}))), "true")
+		assertCorrectMessage(t, strconv.FormatBool(math.IsNaN(pc.Measure([]string{"02"}, probs))), "true")
+	})
+
+	t.Run("Checking MeasureRat.", func(t *testing.T) {
+		probs := []*big.Rat{big.NewRat(1, 3), big.NewRat(2, 3)}
+		assertCorrectMessage(t, pc.MeasureRat([]string{"0", "101"}, probs).String(), "13/27")
+		// the uniform measure is the length of the leaf interval.
+		half := []*big.Rat{big.NewRat(1, 2), big.NewRat(1, 2)}
+		lo, hi := pc.LeafInterval("101")
+		assertCorrectMessage(t, pc.MeasureRat([]string{"101"}, half).String(), new(big.Rat).Sub(hi, lo).String())
+		assertCorrectMessage(t, strconv.FormatBool(nil == pc.MeasureRat([]string{"0"}, probs[:1])), "true")
+	})
+}
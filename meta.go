@@ -0,0 +1,106 @@
+package prefcode
+
+// MetaPolicy says how leaf metadata moves when leaves come and go.
+type MetaPolicy struct {
+	// OnExpand gives the metadata of the leaves replacing an expanded leaf,
+	// in dictionary order, from the metadata of that leaf.  Nil clears it;
+	// CopyMeta copies it to each new leaf.
+	OnExpand func(meta any, leaves []string) []any
+	// OnReduce gives the metadata of the leaf replacing a reduced subtree
+	// from the metadata of its leaves which had any, in dictionary order.
+	// Nil drops it; FirstMeta keeps the first.
+	OnReduce func(metas []any) any
+}
+
+// CopyMeta is an OnExpand policy copying the metadata to every new leaf.
+func CopyMeta(meta any, leaves []string) []any {
+	metas := make([]any, len(leaves))
+	for ii := range metas {
+		metas[ii] = meta
+	}
+	return metas
+}
+
+// FirstMeta is an OnReduce policy keeping the first metadata in dictionary
+// order of the leaves.
+func FirstMeta(metas []any) any {
+	return metas[0]
+}
+
+// SetLeafMeta tags leaf with v.  It does nothing if leaf is not a leaf of p;
+// a nil v removes the tag.
+func (p *prefixCode) SetLeafMeta(leaf string, v any) {
+	if _, ok := p.code[leaf]; !ok {
+		return
+	}
+	if nil == v {
+		delete(p.meta, leaf)
+		return
+	}
+	if nil == p.meta {
+		p.meta = make(map[string]any)
+	}
+	p.meta[leaf] = v
+}
+
+// LeafMeta returns the tag of leaf, or nil if it has none.
+func (p *prefixCode) LeafMeta(leaf string) any {
+	return p.meta[leaf]
+}
+
+// SetMetaPolicy sets how tags follow ExpandAt and ReduceAt.  By default they
+// are cleared and dropped.
+func (p *prefixCode) SetMetaPolicy(policy MetaPolicy) {
+	p.metaPolicy = policy
+}
+
+// metaExpanded moves the tag of old, replaced by leaves, per the policy.
+func (p *prefixCode) metaExpanded(old string, leaves []string) {
+	meta, ok := p.meta[old]
+	if !ok {
+		return
+	}
+	delete(p.meta, old)
+	if nil == p.metaPolicy.OnExpand {
+		return
+	}
+	for ii, v := range p.metaPolicy.OnExpand(meta, leaves) {
+		if ii < len(leaves) && nil != v {
+			p.meta[leaves[ii]] = v
+		}
+	}
+}
+
+// metaReduced moves the tags of the leaves below, replaced by word, per the
+// policy.  below is left as it is.
+func (p *prefixCode) metaReduced(word string, below []string) {
+	if 0 == len(p.meta) {
+		return
+	}
+	leaves := make([]string, len(below))
+	copy(leaves, below)
+	p.sortWords(leaves)
+	var metas []any
+	for _, leaf := range leaves {
+		if meta, ok := p.meta[leaf]; ok {
+			metas = append(metas, meta)
+			delete(p.meta, leaf)
+		}
+	}
+	if 0 == len(metas) || nil == p.metaPolicy.OnReduce {
+		return
+	}
+	if v := p.metaPolicy.OnReduce(metas); nil != v {
+		p.meta[word] = v
+	}
+}
+
+// rootLeaves returns the leaves of the depth one code, in dictionary order.
+func (p *prefixCode) rootLeaves() []string {
+	leaves := make([]string, len(p.alphabet))
+	for ii, r := range p.alphabet {
+		leaves[ii] = string(r)
+	}
+	p.sortWords(leaves)
+	return leaves
+}
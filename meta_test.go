@@ -0,0 +1,81 @@
+package prefcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMeta(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking the default policy clears and drops tags.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking default tags.")
+		}
+		baseCode.SetLeafMeta(EmptyString, "red")
+		assertCorrectMessage(t, fmt.Sprint(baseCode.LeafMeta(EmptyString)), "red")
+		baseCode.ExpandAt("1")
+		assertCorrectMessage(t, fmt.Sprint(baseCode.LeafMeta("10")), "<nil>")
+
+		baseCode.SetLeafMeta("10", "blue")
+		baseCode.SetLeafMeta("1", "green")
+		assertCorrectMessage(t, fmt.Sprint(baseCode.LeafMeta("1")), "<nil>")
+		baseCode.ReduceAt("1")
+		assertCorrectMessage(t, fmt.Sprint(baseCode.LeafMeta("1")), "<nil>")
+		assertCorrectMessage(t, fmt.Sprint(baseCode.LeafMeta("10")), "<nil>")
+	})
+
+	t.Run("Checking copy and merge policies.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking tag policies.")
+		}
+		baseCode.SetMetaPolicy(MetaPolicy{
+			OnExpand: CopyMeta,
+			OnReduce: func(metas []any) any {
+				sum := 0.0
+				for _, m := range metas {
+					sum += m.(float64)
+				}
+				return sum
+			},
+		})
+		baseCode.SetLeafMeta(EmptyString, 1.0)
+		baseCode.ExpandAt("10")
+		assertCorrectMessage(t, fmt.Sprint(baseCode.LeafMeta("0"), baseCode.LeafMeta("101"), baseCode.LeafMeta("11")), "1 1 1")
+
+		baseCode.SetLeafMeta("100", 0.5)
+		baseCode.SetLeafMeta("11", nil)
+		baseCode.ReduceAt("1")
+		assertCorrectMessage(t, fmt.Sprint(baseCode.LeafMeta("1")), "1.5")
+
+		// tags ride along with copies made by Neighbors.
+		for n := range baseCode.Neighbors(nil) {
+			tagged := false
+			for leaf := range n.Code() {
+				tagged = tagged || nil != n.(*prefixCode).LeafMeta(leaf)
+			}
+			assertCorrectMessage(t, fmt.Sprint(tagged), "true")
+		}
+
+		baseCode.ReduceAt(EmptyString)
+		assertCorrectMessage(t, fmt.Sprint(baseCode.LeafMeta(EmptyString)), "2.5")
+
+		baseCode.SetMetaPolicy(MetaPolicy{OnReduce: FirstMeta})
+		baseCode.ExpandAt("1")
+		baseCode.SetLeafMeta("11", "b")
+		baseCode.SetLeafMeta("0", "a")
+		baseCode.ReduceAt(EmptyString)
+		assertCorrectMessage(t, fmt.Sprint(baseCode.LeafMeta(EmptyString)), "a")
+
+		baseCode.SetCode(map[string]int{"0": 0, "1": 1})
+		assertCorrectMessage(t, fmt.Sprint(baseCode.LeafMeta(EmptyString)), "<nil>")
+	})
+}
@@ -0,0 +1,65 @@
+package prefcode
+
+import "errors"
+
+// NormalFormF returns the normal form of t, which must lie in Thompson's F
+// over a binary alphabet, on the generators x0, x1, x2, ... of Cannon,
+// Floyd and Parry: t is
+//
+//	x0^pos[0] x1^pos[1] ... xk^pos[k] xl^-neg[l] ... x1^-neg[1] x0^-neg[0]
+//
+// with trailing zeros cut, so the identity gives two empty slices.  The
+// word acts as a composition of maps, right to left, so that x0^-neg[0]
+// acts first and x0^-1 x1 x0 = x2; built with Compose, which applies its
+// receiver first, the generators go in the opposite order.
+//
+// The exponents are the leaf exponents of the reduced form, pos from the
+// range and neg from the domain: the exponent of the kth leaf is the length
+// of the longest path of left edges going up from it without reaching the
+// right side of the tree.  Coming from the reduced form, whenever xi appears
+// on both sides so does x(i+1) on one, so the normal form is unique and
+// equal elements have equal ones.
+func (t TreePair) NormalFormF() ([]int, []int, error) {
+	if 2 != len(t.domain.alphabet) {
+		return nil, nil, errors.New("Normal form in F needs a binary alphabet")
+	}
+	r := t.Reduce()
+	if !r.IsOrderPreserving() {
+		return nil, nil, errors.New("Tree pair is not order preserving")
+	}
+	return leafExponents(r.rng), leafExponents(r.domain), nil
+}
+
+// leafExponents returns the leaf exponents of the leaves of the binary code
+// pc in dictionary order, trailing zeros cut.  A leaf ending in m left
+// letters has exponent m, or m-1 if the path of them climbs to the right
+// side, that is if the rest of the leaf is all right letters.
+func leafExponents(pc *prefixCode) []int {
+	left, right := pc.alphabet[0], pc.alphabet[1]
+	var exps []int
+	for k, leaf := range pc.sortedKeys() {
+		word := []rune(leaf)
+		if EmptyString == leaf {
+			word = nil
+		}
+		m := 0
+		for m < len(word) && left == word[len(word)-1-m] {
+			m++
+		}
+		rest := word[:len(word)-m]
+		for 0 < len(rest) && right == rest[len(rest)-1] {
+			rest = rest[:len(rest)-1]
+		}
+		e := m
+		if 0 < m && 0 == len(rest) {
+			e = m - 1
+		}
+		if 0 < e {
+			for len(exps) < k {
+				exps = append(exps, 0)
+			}
+			exps = append(exps, e)
+		}
+	}
+	return exps
+}
@@ -0,0 +1,119 @@
+package prefcode
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// generatorF returns the generator xn of F over "01": the identity on the
+// leaves 0, 10, ..., 1^(n-1)0, then x0 below 1^n.
+func generatorF(t *testing.T, n int) TreePair {
+	t.Helper()
+	domain, rng := make(map[string]int), make(map[string]int)
+	for ii := 0; ii < n; ii++ {
+		domain[strings.Repeat("1", ii)+"0"] = ii
+		rng[strings.Repeat("1", ii)+"0"] = ii
+	}
+	top := strings.Repeat("1", n)
+	for ii, w := range []string{"0", "10", "11"} {
+		domain[top+w] = n + ii
+	}
+	for ii, w := range []string{"00", "01", "1"} {
+		rng[top+w] = n + ii
+	}
+	return pairFromMaps(t, domain, rng)
+}
+
+func TestNormalFormF(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	x0, x1 := generatorF(t, 0), generatorF(t, 1)
+
+	t.Run("Checking the normal forms of generators.", func(t *testing.T) {
+		for _, c := range []struct {
+			t    TreePair
+			want string
+		}{
+			{x0, "[1] []"},
+			{x1, "[0 1] []"},
+			{x0.Inverse(), "[] [1]"},
+			{generatorF(t, 3), "[0 0 0 1] []"},
+			{mustCompose(t, x0.Inverse(), x1), "[0 1] [1]"},
+		} {
+			pos, neg, err := c.t.NormalFormF()
+			if nil != err {
+				t.Fatalf("NormalFormF(%v): %v", c.t, err)
+			}
+			assertCorrectMessage(t, fmt.Sprint(pos, neg), c.want)
+		}
+		id, _ := IdentityPair([]rune("01"))
+		pos, neg, _ := id.NormalFormF()
+		assertCorrectMessage(t, fmt.Sprint(len(pos), len(neg)), "0 0")
+	})
+
+	t.Run("Checking normal forms give back their elements.", func(t *testing.T) {
+		g, err := NewCayleyGraph(x0, x1)
+		if nil != err {
+			t.Fatalf("NewCayleyGraph: %v", err)
+		}
+		forms := make(map[string]bool)
+		for u := range g.Ball(3) {
+			pos, neg, err := u.NormalFormF()
+			if nil != err {
+				t.Fatalf("NormalFormF(%v): %v", u, err)
+			}
+			forms[fmt.Sprint(pos, neg)] = true
+			// the word acts right to left, so x0^-neg[0] comes first.
+			w, _ := IdentityPair([]rune("01"))
+			for ii, e := range neg {
+				for ; 0 < e; e-- {
+					w = mustCompose(t, w, generatorF(t, ii).Inverse())
+				}
+			}
+			for ii := len(pos) - 1; ii >= 0; ii-- {
+				for e := pos[ii]; 0 < e; e-- {
+					w = mustCompose(t, w, generatorF(t, ii))
+				}
+			}
+			if !w.Equals(u) {
+				t.Errorf("normal form %v %v of %v gives %v", pos, neg, u, w)
+			}
+			for ii := range pos {
+				if ii < len(neg) && 0 < pos[ii] && 0 < neg[ii] && !exponentAt(pos, ii+1) && !exponentAt(neg, ii+1) {
+					t.Errorf("normal form %v %v is not reduced at x%d", pos, neg, ii)
+				}
+			}
+		}
+		assertCorrectMessage(t, fmt.Sprint(len(forms)), "53")
+	})
+
+	t.Run("Checking elements outside F are rejected.", func(t *testing.T) {
+		swap := pairFromMaps(t, map[string]int{"0": 0, "1": 1}, map[string]int{"0": 1, "1": 0})
+		_, _, err := swap.NormalFormF()
+		assertCorrectMessage(t, fmt.Sprint(err), "Tree pair is not order preserving")
+		id, _ := IdentityPair([]rune("abc"))
+		_, _, err = id.NormalFormF()
+		assertCorrectMessage(t, fmt.Sprint(err), "Normal form in F needs a binary alphabet")
+	})
+}
+
+// mustCompose returns a followed by b, failing the test on an error.
+func mustCompose(t *testing.T, a, b TreePair) TreePair {
+	t.Helper()
+	c, err := a.Compose(b)
+	if nil != err {
+		t.Fatalf("Compose: %v", err)
+	}
+	return c
+}
+
+func exponentAt(exps []int, ii int) bool {
+	return ii < len(exps) && 0 < exps[ii]
+}
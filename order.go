@@ -0,0 +1,132 @@
+package prefcode
+
+import (
+	"errors"
+	"sort"
+)
+
+// By default the letters of a code are ordered by rune, MakeAlphabet sorting
+// whatever it is given, and dictionary order, the labels given by ExpandAt
+// and the n-adic digits of LeafInterval all follow.  A code made by
+// NewPrefCodeOrdered instead orders its letters as its alphabet lists them,
+// so over "zab" the word "z" comes before "a".  The order is kept in rank,
+// nil for rune order.
+
+// MakeOrderedAlphabet turns a string of runes into a slice of runes without
+// duplicates, in order of first appearance.  Compare MakeAlphabet, which
+// sorts.
+func MakeOrderedAlphabet(s string) []rune {
+	seen := make(map[rune]bool, len(s))
+	var a []rune
+	for _, r := range s {
+		if !seen[r] {
+			seen[r] = true
+			a = append(a, r)
+		}
+	}
+	return a
+}
+
+// NewPrefCodeOrdered returns the trivial code over alpha with the letters
+// ordered as alpha lists them, rather than by rune.  Repeated letters are an
+// error, as the order would be ambiguous.
+func NewPrefCodeOrdered(alpha []rune) (*prefixCode, error) {
+	seen := make(map[rune]bool, len(alpha))
+	for _, r := range alpha {
+		if seen[r] {
+			return nil, errors.New("Repeated letter " + string(r) + " in alphabet")
+		}
+		seen[r] = true
+	}
+	letters := make([]rune, len(alpha))
+	copy(letters, alpha)
+	p, err := NewPrefCodeAlphaRunes(letters)
+	if nil != err {
+		return p, err
+	}
+	p.rank = rankLetters(p.alphabet)
+	p.reindex()
+	return p, nil
+}
+
+// IsOrdered reports whether the letters of p are ordered as its alphabet
+// lists them, rather than by rune.
+func (p *prefixCode) IsOrdered() bool {
+	return nil != p.rank
+}
+
+// trivialLike returns the trivial code over the alphabet of p, with the
+// letter order of p.
+func (p *prefixCode) trivialLike() (*prefixCode, error) {
+	if p.IsOrdered() {
+		return NewPrefCodeOrdered(p.alphabet)
+	}
+	return NewPrefCodeAlphaRunes(p.alphabet)
+}
+
+// isOrdered reports whether pc orders its letters as its alphabet lists them.
+func isOrdered(pc PrefCode) bool {
+	p, ok := pc.(*prefixCode)
+	return ok && p.IsOrdered()
+}
+
+// rankLetters returns the place of each letter in alpha.
+func rankLetters(alpha []rune) map[rune]int {
+	rank := make(map[rune]int, len(alpha))
+	for ii, r := range alpha {
+		rank[r] = ii
+	}
+	return rank
+}
+
+// letters returns the alphabet of p in its letter order.
+func (p *prefixCode) letters() []rune {
+	if nil == p.rank {
+		return MakeAlphabet(string(p.alphabet))
+	}
+	return p.Alphabet()
+}
+
+// wordLess reports whether a comes before b in the dictionary order of p.
+// Runes outside the alphabet come after its letters, by rune.
+func (p *prefixCode) wordLess(a, b string) bool {
+	if nil == p.rank {
+		return a < b
+	}
+	rb := []rune(b)
+	ii := 0
+	for _, r := range a {
+		if ii == len(rb) {
+			return false
+		}
+		if r != rb[ii] {
+			return p.letterKey(r) < p.letterKey(rb[ii])
+		}
+		ii++
+	}
+	return ii < len(rb)
+}
+
+func (p *prefixCode) letterKey(r rune) int {
+	if k, ok := p.rank[r]; ok {
+		return k
+	}
+	return len(p.rank) + int(r)
+}
+
+// sortWords sorts words into the dictionary order of p.
+func (p *prefixCode) sortWords(words []string) {
+	if nil == p.rank {
+		sort.Strings(words)
+		return
+	}
+	sort.Slice(words, func(i, j int) bool { return p.wordLess(words[i], words[j]) })
+}
+
+// letterOrder returns the alphabet of pc in its letter order.
+func letterOrder(pc PrefCode) []rune {
+	if p, ok := pc.(*prefixCode); ok {
+		return p.letters()
+	}
+	return MakeAlphabet(string(pc.Alphabet()))
+}
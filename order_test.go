@@ -0,0 +1,95 @@
+package prefcode
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestOrder(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking MakeOrderedAlphabet.", func(t *testing.T) {
+		assertCorrectMessage(t, string(MakeOrderedAlphabet("zabza")), "zab")
+		assertCorrectMessage(t, string(MakeOrderedAlphabet("")), "")
+	})
+
+	t.Run("Checking NewPrefCodeOrdered.", func(t *testing.T) {
+		_, err := NewPrefCodeOrdered([]rune("zaz"))
+		assertCorrectMessage(t, err.Error(), "Repeated letter z in alphabet")
+
+		pc, err := NewPrefCodeOrdered([]rune("zab"))
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeOrdered() in test checking NewPrefCodeOrdered.")
+		}
+		assertCorrectMessage(t, strconv.FormatBool(pc.IsOrdered()), "true")
+		assertCorrectMessage(t, string(pc.Alphabet()), "zab")
+
+		pc.ExpandAt("")
+		assertCorrectMessage(t, pc.String(), "[z 0], [a 1], [b 2]")
+		pc.ExpandAt("a")
+		assertCorrectMessage(t, pc.String(), "[z 0], [az 1], [aa 2], [ab 3], [b 4]")
+		assertCorrectMessage(t, strings.Join(pc.ExposedCarets(), ","), "a")
+		assertCorrectMessage(t, strings.Join(pc.InternalNodes(), ","), ",a")
+		assertCorrectMessage(t, strings.Join(pc.LeavesInRange("az", "b"), ","), "az,aa,ab")
+
+		c := pc.clone()
+		c.ReduceAt("a")
+		c.ExpandAt("b")
+		assertCorrectMessage(t, c.String(), "[z 0], [a 1], [bz 2], [ba 3], [bb 4]")
+
+		natural, _ := NewPrefCodeAlphaString("zab")
+		assertCorrectMessage(t, strconv.FormatBool(natural.IsOrdered()), "false")
+		natural.ExpandAt("")
+		assertCorrectMessage(t, natural.String(), "[a 0], [b 1], [z 2]")
+	})
+
+	t.Run("Checking joins, meets and refinement intervals keep the letter order.", func(t *testing.T) {
+		p, _ := NewPrefCodeOrdered([]rune("zab"))
+		p.ExpandAt("z")
+		q, _ := NewPrefCodeOrdered([]rune("zab"))
+		q.ExpandAt("b")
+		want := "[zz 0], [za 1], [zb 2], [a 3], [bz 4], [ba 5], [bb 6]"
+
+		join, err := p.Join(q)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "Join() in test checking the letter order.")
+		}
+		assertCorrectMessage(t, strconv.FormatBool(join.IsOrdered()), "true")
+		assertCorrectMessage(t, join.String(), want)
+		join, _ = JoinParallel(p, q, 2)
+		assertCorrectMessage(t, strconv.FormatBool(join.IsOrdered()), "true")
+		assertCorrectMessage(t, join.String(), want)
+
+		meet, _ := p.Meet(q)
+		assertCorrectMessage(t, strconv.FormatBool(meet.IsOrdered()), "true")
+		assertCorrectMessage(t, meet.String(), "[z 0], [a 1], [b 2]")
+		meet, _ = MeetParallel(p, q, 2)
+		assertCorrectMessage(t, meet.String(), "[z 0], [a 1], [b 2]")
+
+		var got []string
+		for c := range Interval(meet, p) {
+			got = append(got, c.String())
+		}
+		assertCorrectMessage(t, strings.Join(got, "; "), "[z 0], [a 1], [b 2]; [zz 0], [za 1], [zb 2], [a 3], [b 4]")
+	})
+
+	t.Run("Checking intervals follow the letter order.", func(t *testing.T) {
+		pc, _ := NewPrefCodeOrdered([]rune("zab"))
+		pc.ExpandAt("a")
+		lo, hi := pc.LeafInterval("aa")
+		assertCorrectMessage(t, lo.RatString()+" "+hi.RatString(), "4/9 5/9")
+		leaf, err := pc.LeafContaining(big.NewRat(1, 10))
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "LeafContaining() in test checking intervals.")
+		}
+		assertCorrectMessage(t, leaf, "z")
+	})
+}
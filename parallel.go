@@ -0,0 +1,138 @@
+package prefcode
+
+import (
+	"runtime"
+	"sync"
+)
+
+// JoinParallel computes p.Join(q), spreading the exposed carets of p and q
+// over up to workers goroutines (GOMAXPROCS of them if workers < 1).  Each
+// goroutine collects the carets on the paths to its share, and the code is
+// built once from the union, rather than by one expansion per caret.
+func JoinParallel(p, q PrefCode, workers int) (*prefixCode, error) {
+	if _, err := NewPrefCodeAlphaRunes(p.Alphabet()); nil != err {
+		return nil, err
+	}
+	carets := append(p.ExposedCarets(), q.ExposedCarets()...)
+	nodes := collectParallel(carets, workers, func(caret string, nodes map[string]bool) {
+		addPrefixes(caret, nodes)
+	})
+	return codeFromInternalNodes(p.Alphabet(), isOrdered(p), nodes), nil
+}
+
+// MeetParallel computes p.Meet(q).  The exposed carets of p are shared out
+// over up to workers goroutines (GOMAXPROCS of them if workers < 1), each
+// comparing its share against all the exposed carets of q.  The longest
+// common prefixes found are the exposed carets of the meet.
+func MeetParallel(p, q PrefCode, workers int) (*prefixCode, error) {
+	if _, err := NewPrefCodeAlphaRunes(p.Alphabet()); nil != err {
+		return nil, err
+	}
+	expansionsQ := q.ExposedCarets()
+	nodes := collectParallel(p.ExposedCarets(), workers, func(v string, nodes map[string]bool) {
+		vRunes := []rune(v)
+		for _, w := range expansionsQ {
+			common := 0
+			for _, r := range w {
+				if common == len(vRunes) || vRunes[common] != r {
+					break
+				}
+				common++
+			}
+			addPrefixes(string(vRunes[:common]), nodes)
+		}
+	})
+	return codeFromInternalNodes(p.Alphabet(), isOrdered(p), nodes), nil
+}
+
+// collectParallel runs add on every item, splitting items into contiguous
+// chunks over up to workers goroutines, each with its own set, and returns
+// the union of the sets.
+func collectParallel(items []string, workers int, add func(item string, set map[string]bool)) map[string]bool {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+	sets := make([]map[string]bool, workers)
+	var wg sync.WaitGroup
+	for ii := 0; ii < workers; ii++ {
+		lo, hi := ii*len(items)/workers, (ii+1)*len(items)/workers
+		wg.Add(1)
+		go func(ii int, chunk []string) {
+			defer wg.Done()
+			set := make(map[string]bool)
+			for _, item := range chunk {
+				add(item, set)
+			}
+			sets[ii] = set
+		}(ii, items[lo:hi])
+	}
+	wg.Wait()
+
+	union := make(map[string]bool)
+	for _, set := range sets {
+		for k := range set {
+			union[k] = true
+		}
+	}
+	return union
+}
+
+// addPrefixes adds word and all its prefixes, down to "", to set.
+func addPrefixes(word string, set map[string]bool) {
+	for ii := range word {
+		set[word[:ii]] = true
+	}
+	set[word] = true
+}
+
+// ForEachParallel calls fn on every leaf of pc with its label, over up to
+// workers goroutines (GOMAXPROCS of them if workers < 1), each taking a
+// contiguous run of the leaves in dictionary order.  It returns once every
+// call has.  fn must be safe to call from several goroutines; to gather
+// results without sharing state, see MapReduceParallel.
+func ForEachParallel(pc PrefCode, workers int, fn func(leaf string, label int)) {
+	MapReduceParallel(pc, workers, func(leaf string, label int) struct{} {
+		fn(leaf, label)
+		return struct{}{}
+	}, func(a, _ struct{}) struct{} { return a })
+}
+
+// MapReduceParallel maps every leaf of pc, with its label, by mapLeaf over up
+// to workers goroutines as ForEachParallel does, and merges the results with
+// merge: first each worker its own, in order, then the workers' results, in
+// order.  So for an associative merge the result is as if the leaves were
+// merged one by one in dictionary order, whatever the number of workers.  A
+// code has at least one leaf, so there is always a result.
+func MapReduceParallel[T any](pc PrefCode, workers int, mapLeaf func(leaf string, label int) T, merge func(a, b T) T) T {
+	entries := pc.Entries()
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(entries) {
+		workers = len(entries)
+	}
+	results := make([]T, workers)
+	var wg sync.WaitGroup
+	for ii := 0; ii < workers; ii++ {
+		lo, hi := ii*len(entries)/workers, (ii+1)*len(entries)/workers
+		wg.Add(1)
+		go func(ii int, chunk []LeafEntry) {
+			defer wg.Done()
+			acc := mapLeaf(chunk[0].Leaf, chunk[0].Label)
+			for _, e := range chunk[1:] {
+				acc = merge(acc, mapLeaf(e.Leaf, e.Label))
+			}
+			results[ii] = acc
+		}(ii, entries[lo:hi])
+	}
+	wg.Wait()
+
+	acc := results[0]
+	for _, r := range results[1:] {
+		acc = merge(acc, r)
+	}
+	return acc
+}
@@ -0,0 +1,114 @@
+package prefcode
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParallel(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	randomCode := func(rng *rand.Rand, alpha string, expansions int) *prefixCode {
+		pc, _ := NewPrefCodeAlphaString(alpha)
+		for ii := 0; ii < expansions; ii++ {
+			leaves := pc.sortedKeys()
+			leaf := leaves[rng.Intn(len(leaves))]
+			if EmptyString == leaf {
+				leaf = ""
+			}
+			pc.ExpandAt(leaf + string(alpha[rng.Intn(len(alpha))]))
+		}
+		return pc
+	}
+
+	t.Run("Checking Meet of codes sharing only the root.", func(t *testing.T) {
+		p, _ := NewPrefCode()
+		q, _ := NewPrefCode()
+		p.ExpandAt("1")
+		q.ExpandAt("0")
+		meet, err := p.Meet(q)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "Meet() in test checking Meet at the root.")
+		}
+		assertCorrectMessage(t, meet.String(), "[0 0], [1 1]")
+		meet, _ = MeetParallel(p, q, 4)
+		assertCorrectMessage(t, meet.String(), "[0 0], [1 1]")
+	})
+
+	// the parallel versions must agree with Join and Meet for any split.
+	t.Run("Checking JoinParallel and MeetParallel against Join and Meet.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(11))
+		for _, alpha := range []string{"01", "abc"} {
+			for trial := 0; trial < 20; trial++ {
+				p := randomCode(rng, alpha, rng.Intn(40))
+				q := randomCode(rng, alpha, rng.Intn(40))
+				join, _ := p.Join(q)
+				meet, _ := p.Meet(q)
+				for _, workers := range []int{0, 1, 3, 64} {
+					got, err := JoinParallel(p, q, workers)
+					if nil != err {
+						assertCorrectMessage(t, "Faied to ", "JoinParallel() in test checking parallel refinement.")
+					}
+					assertCorrectMessage(t, got.String(), join.String())
+					got, err = MeetParallel(p, q, workers)
+					if nil != err {
+						assertCorrectMessage(t, "Faied to ", "MeetParallel() in test checking parallel refinement.")
+					}
+					assertCorrectMessage(t, got.String(), meet.String())
+				}
+			}
+		}
+	})
+
+	t.Run("Checking ForEachParallel and MapReduceParallel.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1138))
+		p := randomCode(rng, "abc", 40)
+		compact := NewCompactFrom(p)
+		want := strings.Join(p.SortedLeaves(), ",")
+		for _, code := range []PrefCode{p, compact} {
+			for _, workers := range []int{0, 1, 3, 1000} {
+				var mu sync.Mutex
+				sum := 0
+				ForEachParallel(code, workers, func(_ string, label int) {
+					mu.Lock()
+					sum += label
+					mu.Unlock()
+				})
+				assertCorrectMessage(t, strconv.Itoa(sum), strconv.Itoa(code.Size()*(code.Size()-1)/2))
+
+				got := MapReduceParallel(code, workers, func(leaf string, _ int) string {
+					return leaf
+				}, func(a, b string) string {
+					return a + "," + b
+				})
+				assertCorrectMessage(t, got, want)
+			}
+		}
+	})
+}
+
+func BenchmarkMeetParallel(b *testing.B) {
+	p, _ := NewUniformCode([]rune("01"), 10)
+	q, _ := NewUniformCode([]rune("01"), 10)
+	p.ExpandAt("0000000000000")
+	q.ExpandAt("1111111111111")
+	b.Run("Meet", func(b *testing.B) {
+		for ii := 0; ii < b.N; ii++ {
+			p.Meet(q)
+		}
+	})
+	b.Run("MeetParallel", func(b *testing.B) {
+		for ii := 0; ii < b.N; ii++ {
+			MeetParallel(p, q, 0)
+		}
+	})
+}
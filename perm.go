@@ -0,0 +1,191 @@
+package prefcode
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Perm is a permutation of 0 ... n-1, ii going to perm[ii].  It is what
+// Permutation returns and ApplyPerm takes; being a map[int]int underneath,
+// plain maps still convert freely.
+type Perm map[int]int
+
+// IdentityPerm returns the identity on 0 ... n-1.
+func IdentityPerm(n int) Perm {
+	perm := make(Perm, n)
+	for ii := 0; ii < n; ii++ {
+		perm[ii] = ii
+	}
+	return perm
+}
+
+// Validate returns an error unless perm is a bijection of 0 ... n-1, where n
+// is len(perm).
+func (perm Perm) Validate() error {
+	seen := make([]bool, len(perm))
+	for k, v := range perm {
+		if k < 0 || k >= len(perm) {
+			return errors.New("Permutation moves " + strconv.Itoa(k) + ", which is out of range")
+		}
+		if v < 0 || v >= len(perm) {
+			return errors.New("Permutation sends " + strconv.Itoa(k) + " out of range")
+		}
+		if seen[v] {
+			return errors.New("Permutation is not injective at " + strconv.Itoa(v))
+		}
+		seen[v] = true
+	}
+	return nil
+}
+
+// Compose returns perm followed by q: ii goes to q[perm[ii]].  Both should
+// be valid and of the same size.
+func (perm Perm) Compose(q Perm) Perm {
+	composed := make(Perm, len(perm))
+	for k, v := range perm {
+		composed[k] = q[v]
+	}
+	return composed
+}
+
+// Inverse returns the inverse of the (valid) perm.
+func (perm Perm) Inverse() Perm {
+	inverse := make(Perm, len(perm))
+	for k, v := range perm {
+		inverse[v] = k
+	}
+	return inverse
+}
+
+// Cycles returns the cycles of the (valid) perm of length two or more, each
+// starting from its least point, in increasing order of least point.
+func (perm Perm) Cycles() [][]int {
+	var cycles [][]int
+	seen := make([]bool, len(perm))
+	for start := 0; start < len(perm); start++ {
+		if seen[start] || perm[start] == start {
+			continue
+		}
+		var cycle []int
+		for ii := start; !seen[ii]; ii = perm[ii] {
+			seen[ii] = true
+			cycle = append(cycle, ii)
+		}
+		cycles = append(cycles, cycle)
+	}
+	return cycles
+}
+
+// Parity returns 0 if the (valid) perm is even and 1 if it is odd.
+func (perm Perm) Parity() int {
+	parity := 0
+	for _, cycle := range perm.Cycles() {
+		parity += len(cycle) - 1
+	}
+	return parity % 2
+}
+
+// String writes the (valid) perm in cycle notation, such as "(0 5)(1 3 2)",
+// leaving out fixed points.  The identity is "()".
+func (perm Perm) String() string {
+	cycles := perm.Cycles()
+	if 0 == len(cycles) {
+		return "()"
+	}
+	var b strings.Builder
+	for _, cycle := range cycles {
+		b.WriteByte('(')
+		for ii, v := range cycle {
+			if 0 < ii {
+				b.WriteByte(' ')
+			}
+			b.WriteString(strconv.Itoa(v))
+		}
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// ParsePerm reads a permutation of 0 ... n-1 in the cycle notation of
+// Perm.String.  Points within a cycle are separated by spaces or commas.
+func ParsePerm(s string, n int) (Perm, error) {
+	perm := IdentityPerm(n)
+	moved := make(map[int]bool)
+	rest := strings.TrimSpace(s)
+	for "" != rest {
+		if '(' != rest[0] {
+			return nil, errors.New("Expected ( in cycle notation at " + strconv.Quote(rest))
+		}
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			return nil, errors.New("Unclosed cycle in " + strconv.Quote(s))
+		}
+		var cycle []int
+		for _, field := range strings.FieldsFunc(rest[1:end], func(r rune) bool { return ' ' == r || ',' == r }) {
+			v, err := strconv.Atoi(field)
+			if nil != err {
+				return nil, err
+			}
+			if v < 0 || v >= n {
+				return nil, errors.New("Point " + field + " out of range")
+			}
+			if moved[v] {
+				return nil, errors.New("Point " + field + " appears twice")
+			}
+			moved[v] = true
+			cycle = append(cycle, v)
+		}
+		for ii, v := range cycle {
+			perm[v] = cycle[(ii+1)%len(cycle)]
+		}
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	return perm, nil
+}
+
+// PermFromString reads a permutation in the format written by PermToString,
+// such as "[0 5], [1 1], [2 2], [3 3], [4 4], [5 0]".  The empty string is
+// the empty permutation.
+func PermFromString(s string) (Perm, error) {
+	perm := make(Perm)
+	if "" == s {
+		return perm, nil
+	}
+	for _, pair := range strings.Split(s, ", ") {
+		if len(pair) < 2 || '[' != pair[0] || ']' != pair[len(pair)-1] {
+			return nil, errors.New("Expected [key value] but found " + strconv.Quote(pair))
+		}
+		fields := strings.Split(pair[1:len(pair)-1], " ")
+		if 2 != len(fields) {
+			return nil, errors.New("Expected [key value] but found " + strconv.Quote(pair))
+		}
+		k, err := strconv.Atoi(fields[0])
+		if nil != err {
+			return nil, err
+		}
+		v, err := strconv.Atoi(fields[1])
+		if nil != err {
+			return nil, err
+		}
+		if _, ok := perm[k]; ok {
+			return nil, errors.New("Key " + fields[0] + " appears twice")
+		}
+		perm[k] = v
+	}
+	if err := perm.Validate(); nil != err {
+		return nil, err
+	}
+	return perm, nil
+}
+
+// sortedPermKeys returns the points of perm in increasing order.
+func sortedPermKeys(perm map[int]int) []int {
+	keys := make([]int, 0, len(perm))
+	for k := range perm {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
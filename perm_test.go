@@ -0,0 +1,85 @@
+package prefcode
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestPerm(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking Validate.", func(t *testing.T) {
+		assertCorrectMessage(t, fmt.Sprint(Perm{0: 1, 1: 0}.Validate()), "<nil>")
+		assertCorrectMessage(t, strconv.FormatBool(nil == Perm{0: 1, 1: 1}.Validate()), "false")
+		assertCorrectMessage(t, strconv.FormatBool(nil == Perm{0: 1, 2: 0}.Validate()), "false")
+		assertCorrectMessage(t, strconv.FormatBool(nil == Perm{0: 2, 1: 0}.Validate()), "false")
+	})
+
+	t.Run("Checking Compose, Inverse and Parity.", func(t *testing.T) {
+		p := Perm{0: 1, 1: 2, 2: 0, 3: 3}
+		q := Perm{0: 0, 1: 1, 2: 3, 3: 2}
+		assertCorrectMessage(t, p.String(), "(0 1 2)")
+		assertCorrectMessage(t, p.Compose(q).String(), "(0 1 3 2)")
+		assertCorrectMessage(t, q.Compose(p).String(), "(0 1 2 3)")
+		assertCorrectMessage(t, p.Compose(p.Inverse()).String(), "()")
+		assertCorrectMessage(t, strconv.Itoa(p.Parity()), "0")
+		assertCorrectMessage(t, strconv.Itoa(q.Parity()), "1")
+		assertCorrectMessage(t, strconv.Itoa(p.Compose(q).Parity()), "1")
+		assertCorrectMessage(t, fmt.Sprint(p.Compose(q).Cycles()), "[[0 1 3 2]]")
+		assertCorrectMessage(t, IdentityPerm(3).String(), "()")
+	})
+
+	t.Run("Checking ParsePerm.", func(t *testing.T) {
+		perm, err := ParsePerm("(0 5)(1, 3 2)", 6)
+		assertCorrectMessage(t, fmt.Sprint(err), "<nil>")
+		assertCorrectMessage(t, PermToString(perm), "[0 5], [1 3], [2 1], [3 2], [4 4], [5 0]")
+		assertCorrectMessage(t, perm.String(), "(0 5)(1 3 2)")
+
+		perm, err = ParsePerm(" () ", 2)
+		assertCorrectMessage(t, fmt.Sprint(err)+" "+perm.String(), "<nil> ()")
+		for _, bad := range []string{"(0 1", "0 1", "(0 6)", "(0 1)(1 2)", "(a)"} {
+			_, err = ParsePerm(bad, 6)
+			assertCorrectMessage(t, bad+" "+strconv.FormatBool(nil == err), bad+" false")
+		}
+	})
+
+	t.Run("Checking PermToString on maps which are not permutations.", func(t *testing.T) {
+		assertCorrectMessage(t, PermToString(map[int]int{7: 1, 3: 3}), "[3 3], [7 1]")
+	})
+
+	t.Run("Checking codes use Perm.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking codes use Perm.")
+		}
+		baseCode.ExpandAt("10")
+		perm, _ := ParsePerm("(0 3)", 4)
+		baseCode.ApplyPerm(perm)
+		assertCorrectMessage(t, baseCode.Permutation().String(), "(0 3)")
+		assertCorrectMessage(t, baseCode.String(), "[0 3], [100 1], [101 2], [11 0]")
+	})
+
+	t.Run("Checking PermFromString round trips PermToString.", func(t *testing.T) {
+		for _, text := range []string{"", "[0 0]", "[0 5], [1 1], [2 2], [3 3], [4 4], [5 0]"} {
+			perm, err := PermFromString(text)
+			assertCorrectMessage(t, fmt.Sprint(err), "<nil>")
+			assertCorrectMessage(t, PermToString(perm), text)
+		}
+		perm, _ := ParsePerm("(0 4 2)(1 3)", 12)
+		back, err := PermFromString(PermToString(perm))
+		assertCorrectMessage(t, fmt.Sprint(err), "<nil>")
+		assertCorrectMessage(t, back.String(), perm.String())
+
+		for _, bad := range []string{"[0 1]", "[0 0],[1 1]", "[0 0], [0 0]", "[0 x]", "[0 0 0]", "0 0", "[1 1], [1 0]"} {
+			_, err = PermFromString(bad)
+			assertCorrectMessage(t, bad+" "+strconv.FormatBool(nil == err), bad+" false")
+		}
+	})
+}
@@ -0,0 +1,409 @@
+package prefcode
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// PersistentCode is a prefix code whose ExpandAt and ReduceAt return new
+// versions, leaving the old ones as they were.  The versions share what did
+// not change: an edit copies the path of the tree down to the edit and
+// O(log n) nodes of the label order, so code that branches over many
+// variants of a large code, such as a search over refinements, does not pay
+// for a full copy at each step.  Labels move as they do under the ExpandAt
+// and ReduceAt of PrefCode.  Letters are in rune order.
+//
+// Labels are kept as in rope.go, by position in a sequence of the leaves,
+// here a persistent treap.  A tree shared between versions cannot point up
+// into the sequence, so each leaf carries a key instead, a rational whose
+// place among the keys of the version is its label.  New leaves take keys
+// between the key of the leaf they replace and the next one.
+type PersistentCode struct {
+	alphabet []rune
+	index    map[rune]int // place of each letter in alphabet
+	root     *pnode
+	order    *ptreap
+}
+
+// pnode is a node of the tree of a PersistentCode, never changed once made.
+type pnode struct {
+	children []*pnode // nil at a leaf, else one child per letter
+	key      *big.Rat // the key of a leaf
+}
+
+// ptreap is a node of the persistent treap holding the leaves in order of
+// key, never changed once made.
+type ptreap struct {
+	key         *big.Rat
+	leaf        string
+	prio        uint32
+	size        int
+	left, right *ptreap
+}
+
+// NewPersistentCode returns the trivial code over alpha.
+func NewPersistentCode(alpha []rune) (PersistentCode, error) {
+	alpha = MakeAlphabet(string(alpha))
+	if _, err := NewPrefCodeAlphaRunes(alpha); nil != err {
+		return PersistentCode{}, err
+	}
+	c := PersistentCode{alphabet: alpha, index: rankLetters(alpha), root: &pnode{key: new(big.Rat)}}
+	c.order = treapJoin(nil, newTreap(c.root.key, EmptyString))
+	return c, nil
+}
+
+// Persist returns a persistent copy of pc.
+func Persist(pc PrefCode) (PersistentCode, error) {
+	c, err := NewPersistentCode(pc.Alphabet())
+	if nil != err {
+		return c, err
+	}
+	leaves := make([]string, pc.Size())
+	for leaf, ii := range pc.Code() {
+		if ii < 0 || ii >= len(leaves) || "" != leaves[ii] {
+			return PersistentCode{}, errors.New("Label of " + leaf + " is out of range or repeated")
+		}
+		leaves[ii] = leaf
+	}
+	c.order = nil
+	for ii, leaf := range leaves {
+		key := big.NewRat(int64(ii), 1)
+		c.root = c.setLeaf(c.root, []rune(leaf), key)
+		c.order = treapJoin(c.order, newTreap(key, leaf))
+	}
+	return c, nil
+}
+
+// setLeaf returns root with a leaf keyed key at the end of word, making the
+// nodes on the way carets.  It changes the nodes in place rather than copying
+// the path, so is only used to build a fresh tree.
+func (c PersistentCode) setLeaf(root *pnode, word []rune, key *big.Rat) *pnode {
+	if 0 == len(word) || EmptyString == string(word) {
+		return &pnode{key: key}
+	}
+	if nil == root || nil == root.children {
+		root = &pnode{children: make([]*pnode, len(c.alphabet))}
+	}
+	node := root
+	for _, r := range word[:len(word)-1] {
+		child := &node.children[c.index[r]]
+		if nil == *child || nil == (*child).children {
+			*child = &pnode{children: make([]*pnode, len(c.alphabet))}
+		}
+		node = *child
+	}
+	node.children[c.index[word[len(word)-1]]] = &pnode{key: key}
+	return root
+}
+
+func (c PersistentCode) Alphabet() []rune {
+	alpha := make([]rune, len(c.alphabet))
+	copy(alpha, c.alphabet)
+	return alpha
+}
+
+func (c PersistentCode) Size() int {
+	return treapSize(c.order)
+}
+
+// find returns the node at word, or nil if there is none.
+func (c PersistentCode) find(word string) *pnode {
+	if EmptyString == word {
+		word = ""
+	}
+	node := c.root
+	for _, r := range word {
+		ii, ok := c.index[r]
+		if !ok || nil == node.children {
+			return nil
+		}
+		node = node.children[ii]
+	}
+	return node
+}
+
+func (c PersistentCode) LabelAtLeaf(leaf string) int {
+	node := c.find(leaf)
+	if nil == node || nil != node.children || ("" == leaf && c.root.children == nil) {
+		return FAILURE
+	}
+	return treapRank(c.order, node.key)
+}
+
+// LeafAtLabel returns the leaf labelled label, or "" if there is none.
+func (c PersistentCode) LeafAtLabel(label int) string {
+	if label < 0 || label >= c.Size() {
+		return ""
+	}
+	return treapSelect(c.order, label).leaf
+}
+
+// Code returns the map from leaves to labels.
+func (c PersistentCode) Code() map[string]int {
+	code := make(map[string]int, c.Size())
+	label := 0
+	treapWalk(c.order, func(t *ptreap) {
+		code[t.leaf] = label
+		label++
+	})
+	return code
+}
+
+// Thaw returns a mutable copy of c.
+func (c PersistentCode) Thaw() PrefCode {
+	p := &prefixCode{alphabet: c.Alphabet(), code: c.Code()}
+	p.reindex()
+	return p
+}
+
+// String prints the code as the String of PrefCode does.
+func (c PersistentCode) String() string {
+	var b strings.Builder
+	c.walk(c.root, []rune{}, func(word []rune, node *pnode) {
+		if 0 < b.Len() {
+			b.WriteString(", ")
+		}
+		leaf := string(word)
+		if 0 == len(word) {
+			leaf = EmptyString
+		}
+		b.WriteString("[" + leaf + " " + strconv.Itoa(treapRank(c.order, node.key)) + "]")
+	})
+	return b.String()
+}
+
+// walk visits the leaves at or below node, at word, in dictionary order.  It
+// keeps its own stack rather than recursing, as codes may be very deep.
+func (c PersistentCode) walk(node *pnode, word []rune, visit func(word []rune, node *pnode)) {
+	type entry struct {
+		node *pnode
+		word []rune
+	}
+	stack := []entry{{node, word}}
+	for 0 < len(stack) {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if nil == e.node.children {
+			visit(e.word, e.node)
+			continue
+		}
+		for ii := len(e.node.children) - 1; ii >= 0; ii-- {
+			w := append(e.word[:len(e.word):len(e.word)], c.alphabet[ii])
+			stack = append(stack, entry{e.node.children[ii], w})
+		}
+	}
+}
+
+// ExpandAt returns the code with s made a caret, as the ExpandAt of PrefCode
+// does, reporting false, and returning c, if s is not at or below a leaf.
+func (c PersistentCode) ExpandAt(s string) (PersistentCode, bool) {
+	if EmptyString == s {
+		s = ""
+	}
+	word := []rune(s)
+	for _, r := range word {
+		if _, ok := c.index[r]; !ok {
+			return c, false
+		}
+	}
+	// find the leaf on the way to s.
+	node, depth := c.root, 0
+	for ; nil != node.children && depth < len(word); depth++ {
+		node = node.children[c.index[word[depth]]]
+	}
+	if nil != node.children {
+		return c, false
+	}
+
+	// the new leaves share out the keys from that of the leaf up to the next.
+	n := (len(word)-depth)*(len(c.alphabet)-1) + len(c.alphabet)
+	lo := node.key
+	hi := new(big.Rat).Add(lo, big.NewRat(1, 1))
+	if rank := treapRank(c.order, lo); rank+1 < c.Size() {
+		hi = treapSelect(c.order, rank+1).key
+	}
+	step := new(big.Rat).Sub(hi, lo)
+	step.Quo(step, big.NewRat(int64(n), 1))
+	keys := make([]*big.Rat, n)
+	for ii := range keys {
+		keys[ii] = new(big.Rat).Mul(step, big.NewRat(int64(ii), 1))
+		keys[ii].Add(keys[ii], lo)
+	}
+
+	prefix := append([]rune(nil), word[:depth]...)
+	sub := c.grow(word[depth:], keys)
+	leaves := make([]*ptreap, 0, n)
+	c.walk(sub, prefix, func(w []rune, leaf *pnode) {
+		leaves = append(leaves, newTreap(leaf.key, string(w)))
+	})
+	left, right := treapSplit(c.order, lo, false)
+	_, right = treapSplit(right, lo, true)
+	for _, t := range leaves {
+		left = treapJoin(left, t)
+	}
+	return PersistentCode{alphabet: c.alphabet, index: c.index, root: c.replace(c.root, prefix, sub), order: treapJoin(left, right)}, true
+}
+
+// grow returns the subtree replacing a leaf when spine below it becomes a
+// caret, its leaves taking keys in dictionary order.  The siblings off the
+// spine before it take keys from the front, those after it from the back,
+// leaving the middle to the children at the end of the spine.
+func (c PersistentCode) grow(spine []rune, keys []*big.Rat) *pnode {
+	lo, hi := 0, len(keys)
+	root := &pnode{children: make([]*pnode, len(c.alphabet))}
+	node := root
+	for _, v := range spine {
+		at := c.index[v]
+		for ii := 0; ii < at; ii++ {
+			node.children[ii] = &pnode{key: keys[lo]}
+			lo++
+		}
+		for ii := len(c.alphabet) - 1; ii > at; ii-- {
+			hi--
+			node.children[ii] = &pnode{key: keys[hi]}
+		}
+		node.children[at] = &pnode{children: make([]*pnode, len(c.alphabet))}
+		node = node.children[at]
+	}
+	for ii := range node.children {
+		node.children[ii] = &pnode{key: keys[lo+ii]}
+	}
+	return root
+}
+
+// replace returns node with the node at word replaced by sub, copying the
+// path down to it.
+func (c PersistentCode) replace(node *pnode, word []rune, sub *pnode) *pnode {
+	path := make([]*pnode, len(word))
+	for ii, r := range word {
+		path[ii] = node
+		node = node.children[c.index[r]]
+	}
+	for ii := len(word) - 1; ii >= 0; ii-- {
+		copied := &pnode{children: make([]*pnode, len(path[ii].children))}
+		copy(copied.children, path[ii].children)
+		copied.children[c.index[word[ii]]] = sub
+		sub = copied
+	}
+	return sub
+}
+
+// ReduceAt returns the code with the tree below s collapsed to the leaf s,
+// as the ReduceAt of PrefCode does: s takes the least label below it and the
+// other labels close ranks.  It reports false, returning c, if s is not a
+// node of the tree.
+func (c PersistentCode) ReduceAt(s string) (PersistentCode, bool) {
+	node := c.find(s)
+	if nil == node {
+		return c, false
+	}
+	if EmptyString == s {
+		s = ""
+	}
+	var least *big.Rat
+	order := c.order
+	c.walk(node, []rune(s), func(_ []rune, leaf *pnode) {
+		left, right := treapSplit(order, leaf.key, false)
+		_, right = treapSplit(right, leaf.key, true)
+		order = treapJoin(left, right)
+		if nil == least || leaf.key.Cmp(least) < 0 {
+			least = leaf.key
+		}
+	})
+	leaf := s
+	if "" == leaf {
+		leaf = EmptyString
+	}
+	left, right := treapSplit(order, least, false)
+	order = treapJoin(treapJoin(left, newTreap(least, leaf)), right)
+	return PersistentCode{alphabet: c.alphabet, index: c.index, root: c.replace(c.root, []rune(s), &pnode{key: least}), order: order}, true
+}
+
+func newTreap(key *big.Rat, leaf string) *ptreap {
+	return &ptreap{key: key, leaf: leaf, prio: rand.Uint32(), size: 1}
+}
+
+func treapSize(t *ptreap) int {
+	if nil == t {
+		return 0
+	}
+	return t.size
+}
+
+// withChildren returns a copy of t with the children given.
+func (t *ptreap) withChildren(left, right *ptreap) *ptreap {
+	return &ptreap{key: t.key, leaf: t.leaf, prio: t.prio, size: treapSize(left) + 1 + treapSize(right), left: left, right: right}
+}
+
+// treapSplit returns the treaps of the keys of t below key (at or below key,
+// if inclusive) and of the rest, copying the nodes it passes.
+func treapSplit(t *ptreap, key *big.Rat, inclusive bool) (*ptreap, *ptreap) {
+	if nil == t {
+		return nil, nil
+	}
+	cmp := t.key.Cmp(key)
+	if cmp < 0 || (inclusive && 0 == cmp) {
+		l, r := treapSplit(t.right, key, inclusive)
+		return t.withChildren(t.left, l), r
+	}
+	l, r := treapSplit(t.left, key, inclusive)
+	return l, t.withChildren(r, t.right)
+}
+
+// treapJoin returns the treap of the keys of l then those of r, all of
+// which are greater.
+func treapJoin(l, r *ptreap) *ptreap {
+	if nil == l {
+		return r
+	}
+	if nil == r {
+		return l
+	}
+	if l.prio > r.prio {
+		return l.withChildren(l.left, treapJoin(l.right, r))
+	}
+	return r.withChildren(treapJoin(l, r.left), r.right)
+}
+
+// treapRank returns the number of keys of t below key.
+func treapRank(t *ptreap, key *big.Rat) int {
+	rank := 0
+	for nil != t {
+		if t.key.Cmp(key) < 0 {
+			rank += treapSize(t.left) + 1
+			t = t.right
+		} else {
+			t = t.left
+		}
+	}
+	return rank
+}
+
+// treapSelect returns the node of rank ii.
+func treapSelect(t *ptreap, ii int) *ptreap {
+	for nil != t {
+		switch left := treapSize(t.left); {
+		case ii < left:
+			t = t.left
+		case ii == left:
+			return t
+		default:
+			ii -= left + 1
+			t = t.right
+		}
+	}
+	return nil
+}
+
+// treapWalk visits the nodes of t in order of key.
+func treapWalk(t *ptreap, visit func(*ptreap)) {
+	if nil == t {
+		return
+	}
+	treapWalk(t.left, visit)
+	visit(t)
+	treapWalk(t.right, visit)
+}
@@ -0,0 +1,92 @@
+package prefcode
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestPersistent(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking ExpandAt and ReduceAt.", func(t *testing.T) {
+		c, err := NewPersistentCode([]rune("01"))
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPersistentCode() in test checking ExpandAt.")
+		}
+		assertCorrectMessage(t, c.String(), "[𝛆 0]")
+		d, ok := c.ExpandAt("10")
+		assertCorrectMessage(t, strconv.FormatBool(ok), "true")
+		assertCorrectMessage(t, d.String(), "[0 0], [100 1], [101 2], [11 3]")
+		assertCorrectMessage(t, c.String(), "[𝛆 0]")
+		_, ok = d.ExpandAt("1")
+		assertCorrectMessage(t, strconv.FormatBool(ok), "false")
+		_, ok = d.ExpandAt("2")
+		assertCorrectMessage(t, strconv.FormatBool(ok), "false")
+
+		e, _ := d.ExpandAt("0")
+		f, ok := e.ReduceAt("1")
+		assertCorrectMessage(t, strconv.FormatBool(ok), "true")
+		assertCorrectMessage(t, f.String(), "[00 0], [01 1], [1 2]")
+		assertCorrectMessage(t, e.String(), "[00 0], [01 1], [100 2], [101 3], [11 4]")
+		assertCorrectMessage(t, strconv.Itoa(e.LabelAtLeaf("11"))+" "+e.LeafAtLabel(2), "4 100")
+		assertCorrectMessage(t, strconv.Itoa(e.LabelAtLeaf("1")), strconv.Itoa(FAILURE))
+		_, ok = e.ReduceAt("111")
+		assertCorrectMessage(t, strconv.FormatBool(ok), "false")
+
+		g, _ := e.ReduceAt(EmptyString)
+		assertCorrectMessage(t, g.String(), "[𝛆 0]")
+		assertCorrectMessage(t, e.Thaw().String(), e.String())
+	})
+
+	t.Run("Checking Persist.", func(t *testing.T) {
+		pc, _ := NewPrefCodeAlphaString("abc")
+		pc.ExpandAt("ba")
+		pc.SwapPermAtKeys("a", "bac")
+		c, err := Persist(pc)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "Persist() in test checking Persist.")
+		}
+		assertCorrectMessage(t, c.String(), pc.String())
+		d, _ := c.ExpandAt("bb")
+		pc.ExpandAt("bb")
+		assertCorrectMessage(t, d.String(), pc.String())
+	})
+
+	// branch over random edits, checking every version against PrefCode.
+	t.Run("Checking versions against PrefCode.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(3))
+		c, _ := NewPersistentCode([]rune("012"))
+		versions := []PersistentCode{c}
+		codes := []PrefCode{c.Thaw()}
+		for step := 0; step < 400; step++ {
+			ii := rng.Intn(len(versions))
+			word := ""
+			for jj := rng.Intn(5); jj > 0; jj-- {
+				word += strconv.Itoa(rng.Intn(3))
+			}
+			pc := codes[ii].(*prefixCode).clone()
+			var next PersistentCode
+			var ok bool
+			if 0 == rng.Intn(3) {
+				next, ok = versions[ii].ReduceAt(word)
+				assertCorrectMessage(t, strconv.FormatBool(ok), strconv.FormatBool(pc.ReduceAt(word)))
+			} else {
+				next, ok = versions[ii].ExpandAt(word)
+				assertCorrectMessage(t, strconv.FormatBool(ok), strconv.FormatBool(pc.ExpandAt(word)))
+			}
+			assertCorrectMessage(t, next.String(), pc.String())
+			versions = append(versions, next)
+			codes = append(codes, pc)
+		}
+		for ii, v := range versions {
+			assertCorrectMessage(t, v.String(), codes[ii].String())
+		}
+	})
+}
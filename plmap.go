@@ -0,0 +1,162 @@
+package prefcode
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// Breakpoint is a breakpoint of the piecewise-linear map of [0,1] induced by
+// a tree pair: on [X, X') up to the next breakpoint X' the map is
+// x -> Y + Slope*(x - X).  The last breakpoint has X = 1, with Y the limit of
+// the map from the left and no Slope.  For elements of F the map is the
+// usual homeomorphism; elements of V give maps which are only piecewise
+// continuous, Y being the value after the jump.
+type Breakpoint struct {
+	X, Y, Slope *big.Rat
+}
+
+func (b Breakpoint) String() string {
+	if nil == b.Slope {
+		return "(" + b.X.RatString() + ", " + b.Y.RatString() + ")"
+	}
+	return "(" + b.X.RatString() + ", " + b.Y.RatString() + ") slope " + b.Slope.RatString()
+}
+
+// ToPLMap returns the breakpoints of the map of [0,1] induced by t, one per
+// maximal affine piece, in increasing order of X and ending at X = 1.
+func (t TreePair) ToPLMap() []Breakpoint {
+	r := t.Reduce()
+	slopes := r.Slopes()
+	var points []Breakpoint
+	var end *big.Rat
+	for _, d := range r.domain.sortedKeys() {
+		lo, _ := r.domain.LeafInterval(d)
+		y, yEnd := r.rng.LeafInterval(r.rng.LeafAtLabel(r.domain.LabelAtLeaf(d)))
+		last := len(points) - 1
+		if 0 <= last && 0 == points[last].Slope.Cmp(slopes[d]) && 0 == end.Cmp(y) {
+			end = yEnd
+			continue
+		}
+		points = append(points, Breakpoint{X: lo, Y: y, Slope: slopes[d]})
+		end = yEnd
+	}
+	return append(points, Breakpoint{X: big.NewRat(1, 1), Y: end})
+}
+
+// FromPLMap returns the reduced tree pair over alpha inducing the map with
+// the given breakpoints, as ToPLMap gives them (the alphabet cannot be read
+// off the map).  The breakpoints and their images must be n-adic, for n
+// letters, the slopes powers of n, and the images of the pieces must tile
+// [0,1), so the map may be any element of V which is affine on the pieces.
+func FromPLMap(alpha []rune, points []Breakpoint) (TreePair, error) {
+	if _, err := NewPrefCodeAlphaRunes(alpha); nil != err {
+		return TreePair{}, err
+	}
+	alpha = MakeAlphabet(string(alpha))
+	n := big.NewInt(int64(len(alpha)))
+	if len(points) < 2 || 0 != points[0].X.Sign() || 0 != points[len(points)-1].X.Cmp(big.NewRat(1, 1)) {
+		return TreePair{}, errors.New("Breakpoints must run from 0 to 1")
+	}
+	type piece struct{ x, end, y, slope *big.Rat }
+	pieces := make([]piece, len(points)-1)
+	for ii := range pieces {
+		b := points[ii]
+		if nil == b.Y || nil == b.Slope || b.X.Cmp(points[ii+1].X) >= 0 {
+			return TreePair{}, errors.New("Breakpoint " + b.X.RatString() + " is out of order or incomplete")
+		}
+		if !isNAdic(b.X, n) || !isNAdic(b.Y, n) {
+			return TreePair{}, errors.New("Breakpoint " + b.String() + " is not n-adic")
+		}
+		if b.Slope.Sign() <= 0 || !isNAdic(b.Slope, n) || !isNAdic(new(big.Rat).Inv(b.Slope), n) {
+			return TreePair{}, errors.New("Slope " + b.Slope.RatString() + " is not a power of n")
+		}
+		pieces[ii] = piece{x: b.X, end: points[ii+1].X, y: b.Y, slope: b.Slope}
+	}
+
+	// the images must tile [0,1).
+	images := make([][2]*big.Rat, len(pieces))
+	for ii, p := range pieces {
+		width := new(big.Rat).Sub(p.end, p.x)
+		images[ii] = [2]*big.Rat{p.y, width.Mul(width, p.slope).Add(width, p.y)}
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i][0].Cmp(images[j][0]) < 0 })
+	at := new(big.Rat)
+	for _, image := range images {
+		if 0 != at.Cmp(image[0]) {
+			return TreePair{}, errors.New("Images of the pieces do not tile [0,1) at " + at.RatString())
+		}
+		at = image[1]
+	}
+	if 0 != at.Cmp(big.NewRat(1, 1)) {
+		return TreePair{}, errors.New("Images of the pieces do not tile [0,1) at " + at.RatString())
+	}
+
+	// cut each piece greedily into the largest cylinders whose images are
+	// cylinders.
+	domain := &prefixCode{alphabet: alpha, code: make(map[string]int)}
+	rng := &prefixCode{alphabet: alpha, code: make(map[string]int)}
+	for _, p := range pieces {
+		for x := p.x; x.Cmp(p.end) < 0; {
+			y := new(big.Rat).Sub(x, p.x)
+			y.Mul(y, p.slope).Add(y, p.y)
+			width := big.NewRat(1, 1)
+			for !fitsCylinder(x, y, width, p.end, p.slope) {
+				width.Quo(width, new(big.Rat).SetInt(n))
+			}
+			label := len(domain.code)
+			domain.code[cylinderWord(alpha, x, width)] = label
+			rng.code[cylinderWord(alpha, y, new(big.Rat).Mul(width, p.slope))] = label
+			x = new(big.Rat).Add(x, width)
+		}
+	}
+	domain.reindex()
+	rng.reindex()
+	return TreePair{domain: domain, rng: rng}.Reduce(), nil
+}
+
+// fitsCylinder reports whether [x, x+width) is a cylinder inside [x, end)
+// whose image [y, y+slope*width) is a cylinder too.
+func fitsCylinder(x, y, width, end, slope *big.Rat) bool {
+	image := new(big.Rat).Mul(width, slope)
+	if image.Cmp(big.NewRat(1, 1)) > 0 || new(big.Rat).Add(x, width).Cmp(end) > 0 {
+		return false
+	}
+	return new(big.Rat).Quo(x, width).IsInt() && new(big.Rat).Quo(y, image).IsInt()
+}
+
+// cylinderWord returns the word over the sorted alpha of the cylinder
+// [x, x+width), width being a power of 1/n.
+func cylinderWord(alpha []rune, x, width *big.Rat) string {
+	n := big.NewRat(int64(len(alpha)), 1)
+	k := 0
+	for w := new(big.Rat).Set(width); w.Cmp(big.NewRat(1, 1)) < 0; w.Mul(w, n) {
+		k++
+	}
+	if 0 == k {
+		return EmptyString
+	}
+	// the k digits of x*n^k, most significant first.
+	scaled := new(big.Rat).Quo(x, width).Num()
+	digits := make([]rune, k)
+	base := big.NewInt(int64(len(alpha)))
+	digit := new(big.Int)
+	for ii := k - 1; ii >= 0; ii-- {
+		scaled, digit = new(big.Int).QuoRem(scaled, base, digit)
+		digits[ii] = alpha[digit.Int64()]
+	}
+	return string(digits)
+}
+
+// isNAdic reports whether the denominator of x is a power of n.
+func isNAdic(x *big.Rat, n *big.Int) bool {
+	den := new(big.Int).Set(x.Denom())
+	rem := new(big.Int)
+	for 0 != den.Cmp(big.NewInt(1)) {
+		den.QuoRem(den, n, rem)
+		if 0 != rem.Sign() {
+			return false
+		}
+	}
+	return true
+}
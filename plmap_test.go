@@ -0,0 +1,73 @@
+package prefcode
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+func TestPLMap(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	x0 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "11": 2}, map[string]int{"00": 0, "01": 1, "1": 2})
+	x1 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "110": 2, "111": 3}, map[string]int{"0": 0, "100": 1, "101": 2, "11": 3})
+
+	t.Run("Checking ToPLMap.", func(t *testing.T) {
+		assertCorrectMessage(t, fmt.Sprint(x0.ToPLMap()), "[(0, 0) slope 1/2 (1/2, 1/4) slope 1 (3/4, 1/2) slope 2 (1, 1)]")
+		// the common leaf 0 and the leaf 10 share slope one and join up.
+		assertCorrectMessage(t, fmt.Sprint(x1.ToPLMap()), "[(0, 0) slope 1 (1/2, 1/2) slope 1/2 (3/4, 5/8) slope 1 (7/8, 3/4) slope 2 (1, 1)]")
+		id, _ := IdentityPair([]rune("01"))
+		assertCorrectMessage(t, fmt.Sprint(id.ToPLMap()), "[(0, 0) slope 1 (1, 1)]")
+		swap := pairFromMaps(t, map[string]int{"0": 0, "1": 1}, map[string]int{"1": 0, "0": 1})
+		assertCorrectMessage(t, fmt.Sprint(swap.ToPLMap()), "[(0, 1/2) slope 1 (1/2, 0) slope 1 (1, 1/2)]")
+	})
+
+	t.Run("Checking FromPLMap inverts ToPLMap.", func(t *testing.T) {
+		sq, _ := x0.Compose(x1)
+		swap := pairFromMaps(t, map[string]int{"00": 0, "01": 1, "1": 2}, map[string]int{"1": 0, "00": 1, "01": 2})
+		for _, pair := range []TreePair{x0, x1, sq, swap, x0.Inverse()} {
+			back, err := FromPLMap([]rune("01"), pair.ToPLMap())
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "FromPLMap() of "+pair.String()+": "+err.Error())
+				continue
+			}
+			assertCorrectMessage(t, back.String(), pair.Reduce().String())
+		}
+
+		ternary, _ := NewPrefCodeAlphaString("abc")
+		ternary.ExpandAt("")
+		ternary.ExpandAt("a")
+		other, _ := NewPrefCodeAlphaString("abc")
+		other.ExpandAt("")
+		other.ExpandAt("c")
+		p, _ := NewTreePair(ternary, other)
+		back, err := FromPLMap([]rune("cba"), p.ToPLMap())
+		assertCorrectMessage(t, back.String()+" "+fmt.Sprint(err), p.Reduce().String()+" <nil>")
+	})
+
+	t.Run("Checking FromPLMap validation.", func(t *testing.T) {
+		r := func(a, b int64) *big.Rat { return big.NewRat(a, b) }
+		for _, test := range []struct {
+			points []Breakpoint
+			want   string
+		}{
+			{[]Breakpoint{{r(0, 1), r(0, 1), r(1, 1)}}, "Breakpoints must run from 0 to 1"},
+			{[]Breakpoint{{r(0, 1), r(0, 1), r(1, 3)}, {r(1, 1), r(1, 1), nil}}, "Slope 1/3 is not a power of n"},
+			{[]Breakpoint{{r(0, 1), r(0, 1), r(1, 2)}, {r(1, 3), r(1, 6), r(2, 1)}, {r(1, 1), r(1, 1), nil}}, "Breakpoint (1/3, 1/6) slope 2 is not n-adic"},
+			{[]Breakpoint{{r(0, 1), r(0, 1), r(1, 2)}, {r(1, 1), r(1, 2), nil}}, "Images of the pieces do not tile [0,1) at 1/2"},
+			{[]Breakpoint{{r(0, 1), r(0, 1), r(1, 1)}, {r(1, 2), r(1, 4), r(1, 1)}, {r(1, 1), r(1, 1), nil}}, "Images of the pieces do not tile [0,1) at 1/2"},
+		} {
+			_, err := FromPLMap([]rune("01"), test.points)
+			assertCorrectMessage(t, fmt.Sprint(err), test.want)
+		}
+		_, err := FromPLMap([]rune("01"), x0.ToPLMap())
+		assertCorrectMessage(t, strconv.FormatBool(nil == err), "true")
+	})
+}
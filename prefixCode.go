@@ -1,9 +1,13 @@
 package prefcode
 
 import (
+	"bufio"
+	"context"
 	"errors"
-	"fmt"
+	"io"
+	"iter"
 	"math"
+	"math/big"
 	"sort"
 	"strconv"
 	"strings"
@@ -45,7 +49,8 @@ Can:
 TODO: safety checking: expandAt/reduceAt do not currently check if string is legal for alphabet.
 */
 
-// EmptyString will be represented by the string "𝛆"
+// EmptyString will be represented by the string "𝛆", unless the code was made
+// with an AlphabetPolicy naming the empty word otherwise.
 const EmptyString = "𝛆"
 
 // PrefCode is interface for struct prefixCode which attempts to represent only
@@ -55,26 +60,107 @@ type PrefCode interface {
 	SetAlphabet([]rune)
 	SetCode(map[string]int)
 	Code() map[string]int
+	View() CodeView
 	Equals(PrefCode) bool
 	ReduceAt(s string) bool
 	ExpandAt(s string) bool
-	ApplyPerm(perm map[int]int) bool
+	ExpandToContain(word string) (int, error)
+	ExpandAtAll(words []string) error
+	ReduceAtAll(words []string) error
+	ExpandToDepth(d int)
+	GraftAt(leaf string, sub PrefCode) error
+	SubcodeAt(prefix string) (PrefCode, error)
+	Product(q PrefCode) (PrefCode, error)
+	RelabelAlphabet(mapping map[rune]rune) (PrefCode, error)
+	EmbedInAlphabet(bigger []rune) (PrefCode, error)
+	SameShape(q PrefCode) bool
+	ShapeBijection(q PrefCode) (map[rune]rune, bool)
+	AlphabetOrbit() iter.Seq2[Perm, PrefCode]
+	CanonicalizeUnderAlphabetSymmetry() (PrefCode, Perm)
+	ApplyPerm(perm Perm) bool
+	ComposePerm(perm Perm) error
 	SwapPermAtKeys(a, b string) error
-	Permutation() map[int]int
+	SwapLabels(pairs [][2]string) error
+	CycleLabels(leaves []string) error
+	Permutation() Perm
 	Join(PrefCode) (*prefixCode, error)
 	Meet(PrefCode) (*prefixCode, error)
 	ExposedCarets() []string
+	NumExposedCarets() int
+	ForEachExposedCaret(fn func(caret string) bool)
 	LabelAtLeaf(string) int
 	LeafAtLabel(int) string
+	LabelsToLeaves() []string
+	RelabelLexicographic()
+	RelabelBy(less func(a, b string) bool)
+	InvertLabels()
+	Transact(fn func(tx Tx) error) error
 	Size() int
 	String() string
+	WriteTo(w io.Writer) (int64, error)
 	GetPrefixOf(string) string
+	GetLongestPrefixOf(s string) (string, bool)
+	GetAllCodePrefixesOf(s string) []string
 	CodeToSlice() *[]string
+	SortedLeaves() []string
+	Entries() []LeafEntry
+	Page(afterLeaf string, limit int) []LeafEntry
+	Neighbors(filter func(PrefCode) bool) iter.Seq[PrefCode]
+	Tokenize(s string) ([]string, error)
+	SplitFunc() bufio.SplitFunc
+	AverageLength(probs []float64) float64
+	Entropy(probs []float64) float64
+	OptimalityGap(probs []float64) float64
+	DepthOf(leaf string) int
+	MaxDepth() int
+	MinDepth() int
+	IsUniformDepth() bool
+	DepthHistogram() map[int]int
+	MeanDepth() float64
+	DepthVariance() float64
+	InternalNodes() []string
+	NumCarets() int
+	ParentOf(word string) string
+	ChildrenOf(word string) []string
+	Siblings(leaf string) []string
+	IsLeaf(word string) bool
+	IsInternal(word string) bool
+	IsBelowCode(word string) bool
+	LeavesInRange(lo, hi string) []string
+	LongestCommonPrefix() string
+	SpineTo(leaf string) []string
+	IsRightVine() bool
+	IsLeftVine() bool
+	IsFullTree() bool
+	CaretTypeCounts() CaretCounts
+	WalkDFS(fn func(node string, isLeaf bool, label int) error) error
+	WalkBFS(fn func(node string, isLeaf bool, label int) error) error
+	StreamLeaves(ctx context.Context) <-chan LeafEntry
+	WalkPruned(prune func(prefix string) bool, visit func(leaf string, label int))
+	Serialize() string
+	ToSExpr() string
 }
 
 type prefixCode struct {
 	alphabet []rune
 	code     map[string]int
+	trie     *trieNode            // the leaves of code as a tree, for prefix searches
+	leaves   []string             // leaves[label] is the leaf carrying label
+	order    *ropeNode            // the leaves in label order, see rope.go
+	nodes    map[string]*ropeNode // the rope node of each leaf
+	stale    bool                 // code values and leaves lag behind order
+	exposed  map[string]struct{}  // the exposed carets, see carets.go
+	cache    derived              // sorted keys and the like, see cache.go
+
+	meta        map[string]any // leaf tags, see meta.go
+	metaPolicy  MetaPolicy
+	weights     map[string]*big.Rat // nil while the weights are uniform, see weight.go
+	weightSplit WeightSplit
+	sparse      *sparseIndex // nil unless labels are sparse, see sparse.go
+	rank        map[rune]int // nil for rune order, else each letter's place, see order.go
+	empty       string       // the name of the empty word if not EmptyString, see alphabetPolicy.go
+	hooks       hooks        // observers of changes, see hooks.go
+	checked     bool         // check invariants after changes, see invariants.go
 }
 
 // NewPrefCode returns a prefixCode as a PrefCode.  Magically sets the alphabet to be "01".
@@ -102,6 +188,7 @@ func NewPrefCodeAlphaRunes(alpha []rune) (*prefixCode, error) {
 	prefc.alphabet = alpha
 	prefc.code = make(map[string]int, len(alpha))
 	prefc.code[EmptyString] = 0
+	prefc.reindex()
 	return &prefc, nil
 }
 
@@ -110,21 +197,60 @@ func NewPrefCodeAlphaString(alphaStr string) (*prefixCode, error) {
 	return NewPrefCodeAlphaRunes(MakeAlphabet(alphaStr))
 }
 
+// NewUniformCode returns the complete prefix code over alpha whose leaves are
+// all the words of length depth, labelled in dictionary order.  Depth 0 gives
+// the trivial code.  As for NewPrefCodeAlphaString, repeated letters in alpha
+// count once.
+func NewUniformCode(alpha []rune, depth int) (PrefCode, error) {
+	alpha = MakeAlphabet(string(alpha))
+	prefc, err := NewPrefCodeAlphaRunes(alpha)
+	if err != nil {
+		return prefc, err
+	}
+	if depth < 0 {
+		return prefc, errors.New("Negative depth for uniform code: " + strconv.Itoa(depth))
+	}
+	if 0 == depth {
+		return prefc, nil
+	}
+
+	// grow the words one level at a time.
+	words := []string{""}
+	for level := 0; level < depth; level++ {
+		next := make([]string, 0, len(words)*len(alpha))
+		for _, w := range words {
+			for _, r := range alpha {
+				next = append(next, w+string(r))
+			}
+		}
+		words = next
+	}
+	sort.Strings(words)
+
+	prefc.code = make(map[string]int, len(words))
+	for k, w := range words {
+		prefc.code[w] = k
+	}
+	prefc.reindex()
+	return prefc, nil
+}
+
 // DFSToPrefCode takes an alphabet of runes and a properly shaped DFS sequence
 // for alphabet cardinality and creates the corresponding prefixcode with natural
-// permutation.
+// permutation.  White space in DFS is ignored; see DFSFormat for other
+// ways of writing it.
 // TODO: move to prefcode package.
 func DFSToPrefCode(pc PrefCode, DFS string) bool {
+	DFS = strings.Join(strings.Fields(DFS), "")
 
 	if nil == pc {
-		fmt.Println("DFSToPrefCode called with nil  *PrefCode and DFS: " + DFS)
+		Logger().Warn("called with nil PrefCode", "op", "DFSToPrefCode", "dfs", DFS)
 		return false
 	}
-	//fmt.Println("DFSToPrefCode with pc: " + (*pc).String() + " and DFS: " + DFS)
 	alpha := pc.Alphabet()
 
 	if !ValidDFSForPrefC(len(alpha), DFS) {
-		fmt.Println("DFSToPrefCode: Failed ValidDFSForPrefC(" + strconv.Itoa(int(len(alpha)+'0')) + ", " + DFS + ")")
+		Logger().Warn("invalid DFS sequence", "op", "DFSToPrefCode", "dfs", DFS, "letters", len(alpha))
 		//TODO better error handling.
 		return false
 	}
@@ -164,6 +290,7 @@ func DFSToPrefCode(pc PrefCode, DFS string) bool {
 				}
 				// The tree filled its leaves prematurely:
 				// poorly formatted.  Return Empty prefc
+				Logger().Warn("DFS sequence ends early", "op", "DFSToPrefCode", "dfs", DFS, "at", k)
 				return false
 			}
 			currentWord = stack[top]
@@ -179,15 +306,12 @@ func DFSToPrefCode(pc PrefCode, DFS string) bool {
 			cores[v] = true
 		}
 	}
-	//fmt.Println("DFSToPrefCode with pc: " + (*pc).String() + " before expansions.")
-
 	for k := range cores {
 		if !pc.ExpandAt(k) {
 			continue
 		}
-		//fmt.Println("DFSToPrefCode with pc: " + (*pc).String() + " after " + k + " expansion.")
+		Logger().Debug("expanded", "op", "DFSToPrefCode", "word", k, "size", pc.Size())
 	}
-	//fmt.Println("DFSToPrefCode with pc: " + (*pc).String() + " after expansions.")
 
 	return true
 }
@@ -237,7 +361,7 @@ func ValidDFSForPrefC(alSize int, DFS string) bool {
 }
 
 //returns a ptr to a copy of the alphabet runes.
-func (p prefixCode) Alphabet() []rune {
+func (p *prefixCode) Alphabet() []rune {
 	retVal := make([]rune, len(p.alphabet))
 	for k, v := range p.alphabet {
 		retVal[k] = v
@@ -245,25 +369,21 @@ func (p prefixCode) Alphabet() []rune {
 	return retVal
 }
 
-func (p prefixCode) Size() int {
+func (p *prefixCode) Size() int {
 	return len(p.code)
 }
 
-func (p prefixCode) Permutation() (perm map[int]int) {
-	perm = make(map[int]int, len(p.code))
-	keys := make([]string, 0, len(p.code))
-	for k := range p.code {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	for ii, k := range keys {
-		perm[ii] = p.code[k]
+func (p *prefixCode) Permutation() (perm Perm) {
+	labels := p.sortedLabels()
+	perm = make(Perm, len(labels))
+	for ii, v := range labels {
+		perm[ii] = v
 	}
 	return
 }
 
-func (p prefixCode) SwapPermAtKeys(a, b string) error {
+func (p *prefixCode) SwapPermAtKeys(a, b string) error {
+	p.syncLabels()
 	valuea, oka := p.code[a]
 	valueb, okb := p.code[b]
 	if !oka || !okb {
@@ -271,6 +391,16 @@ func (p prefixCode) SwapPermAtKeys(a, b string) error {
 	}
 	p.code[a] = valueb
 	p.code[b] = valuea
+	p.labelsChanged()
+	if nil != p.sparse {
+		p.sparse.leaves[valuea], p.sparse.leaves[valueb] = b, a
+		p.relabelled()
+		return nil
+	}
+	p.leaves[valuea], p.leaves[valueb] = b, a
+	p.nodes[a].leaf, p.nodes[b].leaf = b, a
+	p.nodes[a], p.nodes[b] = p.nodes[b], p.nodes[a]
+	p.relabelled()
 
 	//todo send some error too if a or b not found.
 	return nil
@@ -278,149 +408,261 @@ func (p prefixCode) SwapPermAtKeys(a, b string) error {
 
 // LabelAtLeaf returns the label at the leaf if it exists.
 // If not, returns FAILURE global constant
-func (p prefixCode) LabelAtLeaf(leaf string) (label int) {
+func (p *prefixCode) LabelAtLeaf(leaf string) (label int) {
 	label, ok := p.code[leaf]
 
 	if !ok {
 		return FAILURE
 	}
+	if n := p.nodes[leaf]; p.stale && nil != n {
+		label = n.rank()
+	}
 	return
 }
 
 // LeafAtLabel returns the leaf which carries the label, if the
 // label is in bound, or the empty string otherwise.
-func (p prefixCode) LeafAtLabel(label int) (leaf string) {
+func (p *prefixCode) LeafAtLabel(label int) (leaf string) {
+	if nil != p.sparse {
+		return p.sparse.leaves[label]
+	}
 	//return empty string if label is out of bounds.
 	//TODO: put in real error handling.
 	if label > (p.Size()-1) || label < 0 {
 		leaf = ""
 		return
 	}
-
-	// find leaf with this label and return it.
-	for k, v := range p.code {
-		if v == label {
-			leaf = k
-			break
+	if p.stale {
+		if n := ropeSelect(p.order, label); nil != n {
+			leaf = n.leaf
 		}
+		return
 	}
-	return
+	return p.leaves[label]
 }
 
 // ApplyPerm applies a permutation map to the values of int
-// labels carried by the prefixes
-func (p prefixCode) ApplyPerm(perm map[int]int) bool {
+// labels carried by the prefixes.  It only checks the size of perm; see
+// ComposePerm for a checked version.
+func (p *prefixCode) ApplyPerm(perm Perm) bool {
 	if len(p.code) != len(perm) {
 		// TODO: add return for err that bad request was made.
+		Logger().Warn("permutation of the wrong size", "op", "ApplyPerm", "perm", len(perm), "size", len(p.code))
 		return false
 	}
 
 	//assumes (w/o testing) values of p.code are 0 -- k-1
 	//for size k code, and likewise for perm.
+	p.syncLabels()
 	for k, v := range p.code {
 		p.code[k] = perm[v]
 	}
+	p.labelsChanged()
+	if nil != p.sparse {
+		p.sparse.index(p.code)
+		p.relabelled()
+		return true
+	}
+	p.indexLabels()
+	p.buildRope()
+	p.relabelled()
 	return true
 }
 
+// ComposePerm relabels p by perm, the leaf labelled v getting perm[v], after
+// checking perm is a bijection of the labels in use.  Composition is on the
+// right: the new Permutation is the old one followed by perm, that is
+// p.Permutation().Compose(perm).  Unlike ApplyPerm, a bad perm changes
+// nothing.
+func (p *prefixCode) ComposePerm(perm Perm) error {
+	if len(perm) != len(p.code) {
+		return errors.New("Permutation has size " + strconv.Itoa(len(perm)) + " but the code has " + strconv.Itoa(len(p.code)) + " leaves")
+	}
+	if err := perm.Validate(); nil != err {
+		return err
+	}
+	p.syncLabels()
+	for _, v := range p.code {
+		if _, ok := perm[v]; !ok {
+			return errors.New("Permutation does not move label " + strconv.Itoa(v))
+		}
+	}
+	p.ApplyPerm(perm)
+	return nil
+}
+
 // PermToString converts a map[int]int into a string.
 // Example Output: "[0 5], [1 1], [2 2], [3 3], [4 4], [5 0]"
 func PermToString(permutation map[int]int) (permStr string) {
 
-	// we reexpress the permutation as an ordered slice then print
-	// to avoid map ordering weirdness.
-	sortedPerm := make([]int, len(permutation))
-	for k, v := range permutation {
-		sortedPerm[k] = v
+	// we list the pairs in order of key, to avoid map ordering weirdness
+	// (and not to trip over maps which are not permutations).
+	var b strings.Builder
+	for _, k := range sortedPermKeys(permutation) {
+		if 0 < b.Len() {
+			b.WriteString(", ")
+		}
+		b.WriteString("[" + strconv.Itoa(k) + " " + strconv.Itoa(permutation[k]) + "]")
 	}
+	return b.String()
+}
 
-	for ii, v := range sortedPerm {
-		permStr += "[" + strconv.Itoa(ii) + " " + strconv.Itoa(v) + "]" + ", "
+func (p *prefixCode) String() string {
+	if !p.cache.hasStr {
+		var b strings.Builder
+		p.WriteTo(&b)
+		p.cache.str, p.cache.hasStr = b.String(), true
 	}
+	return p.cache.str
+}
 
-	permStr = strings.TrimSuffix(permStr, ", ")
-	return
+// WriteTo writes p to w in the format of String, "[leaf label]" entries in
+// dictionary order separated by ", ", without building the whole string.
+func (p *prefixCode) WriteTo(w io.Writer) (int64, error) {
+	out := entryWriter{w: w}
+	labels := p.sortedLabels()
+	for ii, k := range p.sortedKeys() {
+		out.entry(k, labels[ii])
+	}
+	return out.flush()
 }
 
-func (p prefixCode) String() string {
+// entryWriter buffers the "[leaf label]" entries written by WriteTo.
+type entryWriter struct {
+	w       io.Writer
+	buf     []byte
+	total   int64
+	err     error
+	started bool
+}
 
-	keys := make([]string, 0, len(p.code))
-	for k := range p.code {
-		keys = append(keys, k)
+func (e *entryWriter) entry(leaf string, label int) {
+	if nil != e.err {
+		return
 	}
-	sort.Strings(keys)
-
-	var build string
-	for _, k := range keys {
-		build += "[" + k + " " + strconv.Itoa(p.code[k]) + "]" + ", "
+	if e.started {
+		e.buf = append(e.buf, ", "...)
 	}
+	e.started = true
+	e.buf = append(e.buf, '[')
+	e.buf = append(e.buf, leaf...)
+	e.buf = append(e.buf, ' ')
+	e.buf = strconv.AppendInt(e.buf, int64(label), 10)
+	e.buf = append(e.buf, ']')
+	if len(e.buf) >= 4096 {
+		e.flush()
+	}
+}
 
-	return strings.TrimSuffix(build, ", ")
+func (e *entryWriter) flush() (int64, error) {
+	if nil == e.err && 0 < len(e.buf) {
+		n, err := e.w.Write(e.buf)
+		e.total += int64(n)
+		e.err = err
+		e.buf = e.buf[:0]
+	}
+	return e.total, e.err
 }
 
-func (p prefixCode) Code() map[string]int {
-	return p.code
+// Code returns a copy of the map from the leaves of p to their labels.  View
+// reads the leaves without copying.
+func (p *prefixCode) Code() map[string]int {
+	p.syncLabels()
+	code := make(map[string]int, len(p.code))
+	for k, v := range p.code {
+		code[k] = v
+	}
+	return code
 }
 
 // No safety check, that the alphabet of the original prefixcode is the same as that of the new map.
-func (p prefixCode) SetCode(pc map[string]int) {
+//
+// Deprecated: SetCode trusts pc to be a complete prefix code labelled by a
+// permutation; use NewPrefCodeFromMap, which checks.
+func (p *prefixCode) SetCode(pc map[string]int) {
 	p.code = pc
+	p.reindex()
+	for leaf := range p.meta {
+		if _, ok := p.code[leaf]; !ok {
+			delete(p.meta, leaf)
+		}
+	}
+	if nil != p.weights {
+		weights := make(map[string]*big.Rat, len(p.code))
+		for leaf := range p.code {
+			if w, ok := p.weights[leaf]; ok {
+				weights[leaf] = w
+			} else {
+				weights[leaf] = p.uniformWeight(leaf)
+			}
+		}
+		p.weights = weights
+	}
 }
 
-func (p prefixCode) SetAlphabet(a []rune) {
+func (p *prefixCode) SetAlphabet(a []rune) {
 	p.alphabet = make([]rune, len(a))
 	copy(p.alphabet, a)
+	if nil != p.rank {
+		p.rank = rankLetters(p.alphabet)
+	}
 }
 
-func (p prefixCode) Equals(q PrefCode) bool {
+func (p *prefixCode) Equals(q PrefCode) bool {
 	return p.String() == q.String()
 }
 
 //ReduceAt replaces tree dangling at s with
 //just s and updates values of the PrefixCode.
-func (p prefixCode) ReduceAt(s string) bool {
+func (p *prefixCode) ReduceAt(s string) bool {
+	if nil != p.sparse {
+		return p.sparseReduceAt(s)
+	}
 
 	// Handle request to collapse whole PrefCode
-	if "" == s || EmptyString == s {
+	if p.isEmptyWord(s) {
+		root := p.emptyWord()
+		below := p.sortedKeys()
+		if 0 < len(p.meta) || nil != p.weights {
+			p.leavesReduced(root, below)
+		}
 		p.code = make(map[string]int, len(p.alphabet))
-		p.code[EmptyString] = 0
+		p.code[root] = 0
+		p.reindex()
+		p.reduced(root, below)
 		return true
 	}
 
+	p.treeChanged()
+
 	// Now we face a normal request.
 	// we look for s as shallower than some codes.  All such codes are
-	// collapsed to s.  The permutation is re-indexed appropriately.
-	foundCount := 0
-	foundKey := false
+	// collapsed to s, which takes the least of their labels.  The others
+	// close ranks, keeping their order (the rope does this for us).
+	node := p.findNode(s)
+	if nil == node {
+		return false
+	}
+	below := p.leavesBelow(node, s)
 	firstFoundix := len(p.code)
 
-	//	fmt.Println("ReduceAt(" + s + ") start: ")
-	//	fmt.Println(p.String())
-
-	for k, v := range p.code {
-		if strings.HasPrefix(k, s) {
-			if !foundKey {
-				foundKey = true
-			}
-			if v < firstFoundix {
-				firstFoundix = v
-			}
-			foundCount++
-			delete(p.code, k)
-		}
-	}
-	if foundKey {
-		p.code[s] = firstFoundix
-		for k, v := range p.code {
-			if v > firstFoundix {
-				p.code[k] = v + 1 - foundCount
-			}
+	for _, k := range below {
+		if v := p.LabelAtLeaf(k); v < firstFoundix {
+			firstFoundix = v
 		}
 	}
-	if foundCount > 0 {
-		return true
+	for _, k := range below {
+		p.removeLeafFromOrder(k)
+		delete(p.code, k)
 	}
-	return false
+	node.children = nil
+	node.leaf = true
+	p.caretReduced(s, below)
+	p.leavesReduced(s, below)
+	p.code[s] = firstFoundix
+	p.insertLeafInOrder(s, firstFoundix)
+	p.reduced(s, below)
+	return true
 }
 
 //expandAt adds a dangling tree to the prefix r of t
@@ -430,14 +672,22 @@ func (p prefixCode) ReduceAt(s string) bool {
 //TODO: (07Aug2021) refactor logic so gocyclo count (see goreportcard on gitub) is reduced.  Should
 //be easy as initial logic looks over-detected.
 //E.g., 1 == len(p.code) && Emptystring==p.LeafAtLabel(0) is in both first tests.
-func (p prefixCode) ExpandAt(s string) bool {
+func (p *prefixCode) ExpandAt(s string) bool {
+	if nil != p.sparse {
+		return p.sparseExpandAt(s)
+	}
 
 	// p.code is empty (contains EmptyString) and requested expansion is at root.
-	if (EmptyString == s || "" == s) && 1 == len(p.code) && EmptyString == p.LeafAtLabel(0) {
+	root := p.emptyWord()
+	if p.isEmptyWord(s) && 1 == len(p.code) && root == p.LeafAtLabel(0) {
+		p.deleteLeaf(root)
 		for k, v := range p.alphabet {
-			p.code[string(v)] = k
+			p.setLeaf(string(v), k)
 		}
-		delete(p.code, EmptyString)
+		p.indexLabels()
+		p.buildRope()
+		p.caretExpanded(root, "")
+		p.leafExpanded(root, p.rootLeaves())
 		return true
 	}
 
@@ -445,130 +695,144 @@ func (p prefixCode) ExpandAt(s string) bool {
 	// (Implicit from last "If".)
 	// Develop one level of p.code, then pretend we are just starting from the normal case,
 	// but without the EmptyString entry in the code now.
-	if 1 == len(p.code) && EmptyString == p.LeafAtLabel(0) {
+	if 1 == len(p.code) && root == p.LeafAtLabel(0) {
+		p.deleteLeaf(root)
 		for k, v := range p.alphabet {
-			p.code[string(v)] = k
+			p.setLeaf(string(v), k)
 		}
-		delete(p.code, EmptyString)
+		p.indexLabels()
+		p.buildRope()
+		p.caretExpanded(root, "")
+		p.leafExpanded(root, p.rootLeaves())
 		//do not return.  We will now pretend code was not empty and carry on.
 	}
 
-	var labelAtP int
-	prefix := ""
-	var lengthDiff int
-	var numberNewCodes int
-	buildSpine := []rune(s)
-
-	//general handling
-	var toAppend []string
-
-	// this is all made more complicated as our string
-	// has runes, not chars, so slices index poorly (by my current reading)
-	// find expandAt location.
-	for k, v := range p.code {
-		if strings.HasPrefix(s, k) { //if s has k as a prefix ...
-			labelAtP = v
-			prefix = k
-			lengthDiff = len(s) - len(k)
-			numberNewCodes = lengthDiff*(len(p.alphabet)-1) + len(p.alphabet)
-			if 0 < lengthDiff {
-				buildSpine = buildSpine[len(k):] // throw away the prefix
-				break
-			}
-			buildSpine = buildSpine[:0] //force buildSpine to be empty
-			break
-		}
-	}
-	if "" == prefix { //code is not empty but no prefix found: expansion location too shallow so do nothing.
+	// find expandAt location: the leaf on the trie path to s.
+	prefix, ok := p.prefixLeaf(s)
+	if !ok { //code is not empty but no prefix found: expansion location too shallow so do nothing.
 		return false
 	}
+	p.installExpansion(prefix, s, p.LabelAtLeaf(prefix), p.expansionLeaves(prefix, s))
+	return true
+}
 
-	//OK, we found a prefix and built spine for additions.
-	ii := 0
-	//container for new strings
-	toAppend = make([]string, numberNewCodes)
-	if lengthDiff > 0 {
-		var v rune
-		for jj := 0; jj < len(buildSpine); jj++ {
-			v = buildSpine[jj]
-			for _, r := range p.alphabet {
-				if r != v {
-					// maybe incorrect conversion back and forth for runes
-					// slices are structured over bytes.
-					toAppend[ii] = string(buildSpine[:jj]) + string(r)
-					ii++
-				}
+// expansionLeaves returns, in dictionary order, the leaves replacing the leaf
+// prefix when s, at or below it, becomes a caret.
+func (p *prefixCode) expansionLeaves(prefix, s string) []string {
+	prefix = p.path(prefix)
+
+	// the spine is the path from prefix down to s, in runes: letters may
+	// take several bytes, so byte lengths and offsets are no use here.
+	buildSpine := []rune(p.path(s))[utf8.RuneCountInString(prefix):]
+
+	// the new leaves are the siblings off the spine and the children of s.
+	// In dictionary order the siblings before the spine at some depth come
+	// before everything deeper and those after it after, so no sort is
+	// needed: the earlier siblings top down, the children of s, then the
+	// later siblings bottom up.  Sorting would compare the long common
+	// prefixes of deep leaves over and over.
+	letters := p.letters()
+	toAppend := make([]string, 0, len(buildSpine)*(len(p.alphabet)-1)+len(p.alphabet))
+	later := make([][]string, len(buildSpine))
+	path := prefix
+	for jj, v := range buildSpine {
+		after := false
+		for _, r := range letters {
+			switch {
+			case r == v:
+				after = true
+			case after:
+				later[jj] = append(later[jj], path+string(r))
+			default:
+				toAppend = append(toAppend, path+string(r))
 			}
 		}
+		path += string(v)
 	}
 	// full alphabet expansion one rune beyond s
-	for _, r := range p.alphabet {
-		toAppend[ii] = string(buildSpine[:]) + string(r)
-		ii++
-	}
-
-	if nil != toAppend {
-		sort.Strings(toAppend)
-		// delete k from p.code.
-		// then reindex the later keys by adding numberNewCodes-1
-		// (we are adding numberNewCodes new strings but deleted one)
-		// then insert the new codes to the prefixCode
-		delete(p.code, prefix)
-		for lateKey, v := range p.code {
-			if v > labelAtP {
-				p.code[lateKey] = v + numberNewCodes - 1
-			}
-		}
-		for jj, v := range toAppend {
-			p.code[prefix+v] = labelAtP + jj
-		}
+	for _, r := range letters {
+		toAppend = append(toAppend, path+string(r))
 	}
-	return true
+	for jj := len(later) - 1; jj >= 0; jj-- {
+		toAppend = append(toAppend, later[jj]...)
+	}
+	return toAppend
 }
 
-func (p prefixCode) ExposedCarets() (caretRoots []string) {
-	mset := make(map[string]string) // New empty multiset
-	var prefLen int
-	var thisString string
-
-	//	fmt.Println("Searching for exposed carets in the prefix code: ")
-	//	fmt.Println(p.String())
-
-	for k, v := range p.code {
-		prefLen = len(k)
-		if prefLen > 0 {
-			thisString = trimLastChar(k)
-			//			fmt.Println("key: " + k + "  trimmed: " + thisString)
-			mset[thisString] = mset[thisString] + strconv.Itoa(v)
-		}
+// installExpansion replaces the leaf prefix, labelled labelAtP, by the leaves
+// (in dictionary order) of the expansion making caret a caret.  The later
+// keys are reindexed (by len(leaves)-1, as we are adding len(leaves) new
+// strings but deleted one) by splicing the new leaves into the rope in place
+// of prefix; the map catches up lazily.
+func (p *prefixCode) installExpansion(prefix, caret string, labelAtP int, leaves []string) {
+	p.deleteLeaf(prefix)
+	for jj, leaf := range leaves {
+		p.setLeaf(leaf, labelAtP+jj)
 	}
-	//	fmt.Println("the shortened words are: ")
-	//	fmt.Println(mset)
-	alphaSize := len(p.alphabet)
-	for k, v := range mset {
-		if len(v) == alphaSize {
-			caretRoots = append(caretRoots, k)
-			//			fmt.Println("Added " + k + "to caretRoots.")
+	p.replaceLeafInOrder(prefix, leaves)
+	p.caretExpanded(prefix, caret)
+	p.leafExpanded(prefix, leaves)
+}
+
+func (p *prefixCode) ExposedCarets() (caretRoots []string) {
+	if nil == p.cache.carets {
+		carets := make([]string, 0, len(p.exposed))
+		for caret := range p.exposed {
+			carets = append(carets, caret)
 		}
+		p.sortWords(carets)
+		p.cache.carets = carets
 	}
-	sort.Strings(caretRoots)
+	caretRoots = make([]string, len(p.cache.carets))
+	copy(caretRoots, p.cache.carets)
 	return
 }
 
-func (p prefixCode) GetPrefixOf(s string) string {
-	for k := range p.code {
-		if strings.HasPrefix(s, k) {
-			return k
+// GetPrefixOf returns the leaf of p which is a prefix of s, or "" if there is
+// none, walking the trie so only the path to s is visited.  The leaf
+// EmptyString of the trivial code is a prefix of every word.  For a prefix code
+// the match is unique; if the code is broken (say, after SetCode) the
+// shortest match is returned, see also GetLongestPrefixOf.
+func (p *prefixCode) GetPrefixOf(s string) string {
+	k, _ := p.prefixLeaf(s)
+	return k
+}
+
+// GetAllCodePrefixesOf returns every leaf of p which is a prefix of s, shortest
+// first.  For a prefix code there is at most one, but broken codes (say,
+// after SetCode) may give several.  The leaf EmptyString of the trivial code is
+// a prefix of every word.
+func (p *prefixCode) GetAllCodePrefixesOf(s string) []string {
+	var prefixes []string
+	if _, ok := p.code[p.emptyWord()]; ok {
+		prefixes = append(prefixes, p.emptyWord())
+	}
+	for ii := range s {
+		if _, ok := p.code[s[:ii]]; ok && ii > 0 {
+			prefixes = append(prefixes, s[:ii])
 		}
 	}
-	return ""
+	if _, ok := p.code[s]; ok && "" != s {
+		prefixes = append(prefixes, s)
+	}
+	return prefixes
+}
+
+// GetLongestPrefixOf returns the longest leaf of p which is a prefix of s, and
+// whether there is one at all.  For a prefix code this is the unique match.
+func (p *prefixCode) GetLongestPrefixOf(s string) (string, bool) {
+	prefixes := p.GetAllCodePrefixesOf(s)
+	if 0 == len(prefixes) {
+		return "", false
+	}
+	return prefixes[len(prefixes)-1], true
 }
 
 // Join finds smallest prefix code so that each leaf is deeper/equal
 // to leaves of both prefix codes and returns a pointer to this constructed code.
 // TODO: needs testing coverage
-func (p prefixCode) Join(q PrefCode) (*prefixCode, error) {
-	jpc, err := NewPrefCodeAlphaRunes(p.alphabet)
+func (p *prefixCode) Join(q PrefCode) (*prefixCode, error) {
+	jpc, err := p.trivialLike()
 
 	if err != nil {
 		return jpc, err
@@ -590,8 +854,8 @@ func (p prefixCode) Join(q PrefCode) (*prefixCode, error) {
 
 // Iterates from left-right through the prefx codes, choosing the shallower
 // element of any comparable pair too build a new prefix code.  Replaces the first with this one.
-func (p prefixCode) Meet(q PrefCode) (*prefixCode, error) {
-	jpc, err := NewPrefCodeAlphaRunes(p.alphabet)
+func (p *prefixCode) Meet(q PrefCode) (*prefixCode, error) {
+	jpc, err := p.trivialLike()
 
 	if err != nil {
 		return jpc, err
@@ -618,9 +882,9 @@ func (p prefixCode) Meet(q PrefCode) (*prefixCode, error) {
 
 	for _, v := range expansionsP {
 		for _, w := range expansionsQ {
-			maxLen = int(math.Min(float64(len(v)), float64(len(w))))
 			vRunes = []rune(v)
 			wRunes = []rune(w)
+			maxLen = int(math.Min(float64(len(vRunes)), float64(len(wRunes))))
 			for ii := 0; ii < maxLen; ii++ {
 				if vRunes[ii] == wRunes[ii] {
 					commonWord = commonWord + string(vRunes[ii])
@@ -628,10 +892,9 @@ func (p prefixCode) Meet(q PrefCode) (*prefixCode, error) {
 				}
 				break
 			}
-			if len(commonWord) > 0 {
-				allCommonExpansions[commonWord] = true
-				commonWord = ""
-			}
+			// an empty common word is the root, a caret of both codes.
+			allCommonExpansions[commonWord] = true
+			commonWord = ""
 		}
 	}
 	for k := range allCommonExpansions {
@@ -641,7 +904,11 @@ func (p prefixCode) Meet(q PrefCode) (*prefixCode, error) {
 }
 
 // CodeToSlice returns a * to slice consisting of the codestrings of p
-func (p prefixCode) CodeToSlice() *[]string {
+//
+// Deprecated: the slice comes padded with len(p.code) empty strings ahead of
+// the leaves, which are in no particular order.  Use SortedLeaves, or Entries
+// for the labels too.
+func (p *prefixCode) CodeToSlice() *[]string {
 	codes := make([]string, len(p.code))
 	for k := range p.code {
 		codes = append(codes, k)
@@ -649,6 +916,42 @@ func (p prefixCode) CodeToSlice() *[]string {
 	return &codes
 }
 
+// clone returns a deep copy of p, so the copy can be mutated freely.  The
+// hooks are not copied, as the observers of p watch p and not its copies.
+func (p *prefixCode) clone() *prefixCode {
+	p.syncLabels()
+	var c prefixCode
+	c.alphabet = p.Alphabet()
+	if nil != p.rank {
+		c.rank = rankLetters(c.alphabet)
+	}
+	c.empty = p.empty
+	if nil != p.sparse {
+		c.sparse = &sparseIndex{next: p.sparse.next}
+	}
+	c.code = make(map[string]int, len(p.code))
+	for k, v := range p.code {
+		c.code[k] = v
+	}
+	c.reindex()
+	if 0 < len(p.meta) {
+		c.meta = make(map[string]any, len(p.meta))
+		for k, v := range p.meta {
+			c.meta[k] = v
+		}
+	}
+	c.metaPolicy = p.metaPolicy
+	if nil != p.weights {
+		c.weights = make(map[string]*big.Rat, len(p.weights))
+		for k, w := range p.weights {
+			c.weights[k] = w
+		}
+	}
+	c.weightSplit = p.weightSplit
+	c.checked = p.checked
+	return &c
+}
+
 // Helper functions many just found and mildly edited from standard websites.
 
 // StringToRuneSlice converts a string to a slice of runes.
@@ -660,6 +963,14 @@ func StringToRuneSlice(s string) []rune {
 	return r
 }
 
+// wordLen returns the length of a word in runes, EmptyString having length 0.
+func wordLen(w string) int {
+	if EmptyString == w {
+		return 0
+	}
+	return utf8.RuneCountInString(w)
+}
+
 // trimLastChar consumes the last digit of string.
 func trimLastChar(s string) string {
 	r, size := utf8.DecodeLastRuneInString(s)
@@ -1,6 +1,7 @@
 package prefcode
 
 import (
+	"errors"
 	"strconv"
 	"strings"
 	"testing"
@@ -208,4 +209,189 @@ func Test(t *testing.T) {
 			assertCorrectMessage(t, got, want)
 		})
 
+	// NewUniformCode builds the full tree of a given depth.
+	t.Run("Checking NewUniformCode.",
+		func(t *testing.T) {
+			uniCode, err := NewUniformCode([]rune("01"), 2)
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "NewUniformCode in test checking NewUniformCode.")
+			}
+			assertCorrectMessage(t, uniCode.String(), "[00 0], [01 1], [10 2], [11 3]")
+
+			trivial, err := NewUniformCode([]rune("abc"), 0)
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "NewUniformCode at depth 0.")
+			}
+			assertCorrectMessage(t, trivial.String(), "[𝛆 0]")
+
+			_, err = NewUniformCode([]rune("01"), -1)
+			if nil == err {
+				assertCorrectMessage(t, "No error for ", "NewUniformCode at negative depth.")
+			}
+
+			repeated, err := NewUniformCode([]rune("aab"), 1)
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "NewUniformCode over repeated letters.")
+			}
+			assertCorrectMessage(t, repeated.String(), "[a 0], [b 1]")
+			if err := repeated.(*prefixCode).CheckInvariants(); nil != err {
+				assertCorrectMessage(t, err.Error(), "<nil>")
+			}
+		})
+
+	// GetLongestPrefixOf and GetAllCodePrefixesOf, also on a broken code.
+	t.Run("Checking GetLongestPrefixOf.",
+		func(t *testing.T) {
+			baseCode, err := NewPrefCode()
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking GetLongestPrefixOf.")
+			}
+			got, ok := baseCode.GetLongestPrefixOf("0110")
+			assertCorrectMessage(t, got+" "+strconv.FormatBool(ok), EmptyString+" true")
+
+			baseCode.ExpandAt("1001")
+			got, ok = baseCode.GetLongestPrefixOf("100111")
+			assertCorrectMessage(t, got+" "+strconv.FormatBool(ok), "10011 true")
+			got, ok = baseCode.GetLongestPrefixOf("100")
+			assertCorrectMessage(t, got+" "+strconv.FormatBool(ok), " false")
+
+			// break the code by hand: now 1, 10 and 101 are all leaves.
+			broken := baseCode.Code()
+			broken["1"] = 6
+			broken["10"] = 7
+			baseCode.SetCode(broken)
+			assertCorrectMessage(t, strings.Join(baseCode.GetAllCodePrefixesOf("1011"), ","), "1,10,101")
+			got, _ = baseCode.GetLongestPrefixOf("1011")
+			assertCorrectMessage(t, got, "101")
+		})
+
+	// WriteTo writes what String returns, and reports a failing writer.
+	t.Run("Checking WriteTo.",
+		func(t *testing.T) {
+			baseCode, err := NewPrefCode()
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking WriteTo.")
+			}
+			for ii := 0; ii < 400; ii++ {
+				baseCode.ExpandAt(strings.Repeat("1", ii))
+			}
+			var b strings.Builder
+			n, err := baseCode.WriteTo(&b)
+			assertCorrectMessage(t, b.String(), baseCode.String())
+			assertCorrectMessage(t, strconv.FormatInt(n, 10), strconv.Itoa(b.Len()))
+			assertCorrectMessage(t, strconv.FormatBool(nil == err), "true")
+
+			_, err = baseCode.WriteTo(failingWriter{})
+			assertCorrectMessage(t, strconv.FormatBool(nil == err), "false")
+		})
+
+	// ComposePerm composes on the right and refuses bad permutations.
+	t.Run("Checking ComposePerm.",
+		func(t *testing.T) {
+			baseCode, err := NewPrefCode()
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking ComposePerm.")
+			}
+			baseCode.ExpandAt("10")
+			first, _ := ParsePerm("(0 1 2)", 4)
+			second, _ := ParsePerm("(2 3)", 4)
+			baseCode.ComposePerm(first)
+			baseCode.ComposePerm(second)
+			assertCorrectMessage(t, baseCode.Permutation().String(), first.Compose(second).String())
+			assertCorrectMessage(t, baseCode.String(), "[0 1], [100 3], [101 0], [11 2]")
+
+			for _, bad := range []Perm{{0: 0, 1: 1, 2: 2}, {0: 1, 1: 1, 2: 2, 3: 3}, {0: 0, 1: 1, 2: 2, 4: 3}} {
+				err = baseCode.ComposePerm(bad)
+				assertCorrectMessage(t, strconv.FormatBool(nil == err), "false")
+			}
+			assertCorrectMessage(t, baseCode.String(), "[0 1], [100 3], [101 0], [11 2]")
+		})
+
+	// ExpandAt over multi-byte alphabets, every word up to length three.
+	t.Run("Checking ExpandAt over multi-byte alphabets.",
+		func(t *testing.T) {
+			for _, alpha := range []string{"日本語", "😀😃😄😁", "a日😀"} {
+				letters := []rune(alpha)
+				words := []string{""}
+				for level := 0; level < 3; level++ {
+					for _, w := range words {
+						if len([]rune(w)) != level {
+							continue
+						}
+						for _, r := range letters {
+							words = append(words, w+string(r))
+						}
+					}
+				}
+				for _, start := range []string{EmptyString, "", string(letters[1])} {
+					for _, w := range words {
+						baseCode, _ := NewPrefCodeAlphaString(alpha)
+						baseCode.ExpandAt(start)
+						baseCode.ExpandAt(w)
+
+						leaves := make([]string, 0, baseCode.Size())
+						for leaf := range baseCode.Code() {
+							leaves = append(leaves, leaf)
+						}
+						complete, _ := IsCompletePrefixSet(leaves, letters)
+						assertCorrectMessage(t, strconv.FormatBool(complete), "true")
+						assertCorrectMessage(t, strconv.FormatBool(baseCode.IsInternal(w)), "true")
+					}
+				}
+			}
+		})
+	// A vine thousands of letters deep: expanding, reducing, walking and
+	// printing must neither recurse letter by letter nor sort or hash the
+	// long leaves over and over.  (The leaves of a vine of depth d add up to
+	// d*d/2 letters, so a map-backed code cannot go much deeper; see
+	// TestCompact for depth 100000.)
+	t.Run("Checking very deep codes.",
+		func(t *testing.T) {
+			const depth = 4000
+			deep := strings.Repeat("1", depth)
+			baseCode, err := NewPrefCode()
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking very deep codes.")
+			}
+			compact, _ := NewCompactPrefCode([]rune("01"))
+			baseCode.ExpandAt(deep)
+			compact.ExpandAt(deep)
+			assertCorrectMessage(t, strconv.Itoa(baseCode.Size()), strconv.Itoa(depth+2))
+			assertCorrectMessage(t, strconv.Itoa(baseCode.LabelAtLeaf(deep+"0")), strconv.Itoa(depth))
+			assertCorrectMessage(t, strconv.Itoa(baseCode.MaxDepth()), strconv.Itoa(depth+1))
+			assertCorrectMessage(t, strconv.Itoa(len(baseCode.InternalNodes())), strconv.Itoa(depth+1))
+			assertCorrectMessage(t, strings.Join(baseCode.ExposedCarets(), ""), deep)
+			assertCorrectMessage(t, baseCode.String(), compact.String())
+			if err := baseCode.CheckInvariants(); nil != err {
+				assertCorrectMessage(t, err.Error(), "<nil>")
+			}
+
+			nodes := 0
+			baseCode.WalkDFS(func(string, bool, int) error {
+				nodes++
+				return nil
+			})
+			assertCorrectMessage(t, strconv.Itoa(nodes), strconv.Itoa(2*depth+3))
+			persistent, _ := Persist(baseCode)
+			persistent, _ = persistent.ExpandAt(deep + "0")
+			baseCode.ExpandAt(deep + "0")
+			assertCorrectMessage(t, persistent.String(), baseCode.String())
+
+			half := deep[:depth/2]
+			baseCode.ReduceAt(half)
+			compact.ReduceAt(half)
+			compact.ExpandAt(deep + "0")
+			compact.ReduceAt(half)
+			assertCorrectMessage(t, baseCode.String(), compact.String())
+			assertCorrectMessage(t, strconv.Itoa(baseCode.Size()), strconv.Itoa(depth/2+1))
+			baseCode.ReduceAt("")
+			assertCorrectMessage(t, baseCode.String(), "[𝛆 0]")
+		})
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("Write failed")
 }
@@ -0,0 +1,39 @@
+package prefcode
+
+import (
+	"math/rand"
+	"reflect"
+)
+
+// QuickCode wraps a PrefCode so that it implements testing/quick.Generator.
+// Property tests can then take QuickCode arguments and get random binary
+// prefix codes with random permutations:
+//
+//	quick.Check(func(q QuickCode) bool { return q.Size() > 0 }, nil)
+type QuickCode struct {
+	PrefCode
+}
+
+// Generate returns a random QuickCode over the alphabet "01" with at most size
+// carets.  The shape is grown by expanding uniformly chosen leaves, then the
+// labels are shuffled by a uniformly random permutation.
+func (QuickCode) Generate(rand *rand.Rand, size int) reflect.Value {
+	pc, _ := NewPrefCode()
+
+	carets := 0
+	if size > 0 {
+		carets = rand.Intn(size + 1)
+	}
+	for ii := 0; ii < carets; ii++ {
+		leaves := pc.sortedKeys()
+		pc.ExpandAt(leaves[rand.Intn(len(leaves))])
+	}
+
+	perm := make(Perm, pc.Size())
+	for k, v := range rand.Perm(pc.Size()) {
+		perm[k] = v
+	}
+	pc.ApplyPerm(perm)
+
+	return reflect.ValueOf(QuickCode{pc})
+}
@@ -0,0 +1,44 @@
+package prefcode
+
+import (
+	"math"
+	"testing"
+	"testing/quick"
+)
+
+func TestQuickCode(t *testing.T) {
+
+	// complete binary codes have Kraft sum 1.
+	t.Run("Checking QuickCode completeness.", func(t *testing.T) {
+		kraft := func(q QuickCode) bool {
+			sum := 0.0
+			for leaf := range q.Code() {
+				if EmptyString == leaf {
+					return 1 == q.Size()
+				}
+				sum += math.Pow(2, -float64(len(leaf)))
+			}
+			return 1 == sum
+		}
+		if err := quick.Check(kraft, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	// labels are a permutation of 0 ... n-1.
+	t.Run("Checking QuickCode labels.", func(t *testing.T) {
+		isPerm := func(q QuickCode) bool {
+			seen := make(map[int]bool, q.Size())
+			for _, v := range q.Code() {
+				if v < 0 || v >= q.Size() || seen[v] {
+					return false
+				}
+				seen[v] = true
+			}
+			return true
+		}
+		if err := quick.Check(isPerm, nil); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package prefcode
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// RandomDFS returns the DFS sequence of a tree with the given number of
+// carets over an alphabet of alphabetSize letters, every such tree equally
+// likely.  It shuffles the carets and the (alphabetSize-1)*carets+1 leaves
+// uniformly and then, by the cycle lemma, exactly one rotation of the
+// shuffle is a DFS sequence: the one starting just after the first place
+// the running tally is at its least.  Each tree thus comes from as many
+// shuffles as there are symbols, so the choice is uniform.  It panics if
+// alphabetSize is below 1 or carets is negative.
+func RandomDFS(rng *rand.Rand, alphabetSize, carets int) string {
+	if alphabetSize < 1 || carets < 0 {
+		panic("RandomDFS needs at least one letter and no negative carets, not " + strconv.Itoa(alphabetSize) + " and " + strconv.Itoa(carets))
+	}
+	leaves := (alphabetSize-1)*carets + 1
+	seq := make([]byte, carets+leaves)
+	for ii := range seq {
+		seq[ii] = '0'
+		if ii < carets {
+			seq[ii] = '1'
+		}
+	}
+	rng.Shuffle(len(seq), func(i, j int) { seq[i], seq[j] = seq[j], seq[i] })
+
+	// a caret adds alphabetSize-1 to the tally and a leaf takes 1 off.
+	tally, least, at := 0, 0, 0
+	for ii, b := range seq {
+		if '1' == b {
+			tally += alphabetSize - 1
+		} else {
+			tally--
+		}
+		if tally < least {
+			least, at = tally, ii+1
+		}
+	}
+	return string(seq[at:]) + string(seq[:at])
+}
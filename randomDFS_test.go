@@ -0,0 +1,52 @@
+package prefcode
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestRandomDFS(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking random DFS sequences are trees.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		assertCorrectMessage(t, RandomDFS(rng, 2, 0), "0")
+		for _, size := range []int{2, 3, 5} {
+			for carets := 1; carets < 40; carets++ {
+				dfs := RandomDFS(rng, size, carets)
+				if !ValidDFSForPrefC(size, dfs) {
+					t.Errorf("RandomDFS(%d, %d) gave %q", size, carets, dfs)
+				}
+			}
+		}
+	})
+
+	t.Run("Checking random DFS sequences are uniform.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(2))
+		counts := make(map[string]int)
+		for ii := 0; ii < 14000; ii++ {
+			counts[RandomDFS(rng, 2, 4)]++
+		}
+		// the 14 binary trees with 4 carets should each come about 1000 times.
+		assertCorrectMessage(t, fmt.Sprint(len(counts)), "14")
+		for dfs, n := range counts {
+			if n < 850 || n > 1150 {
+				t.Errorf("%s came %d times in 14000", dfs, n)
+			}
+		}
+	})
+
+	t.Run("Checking bad sizes panic.", func(t *testing.T) {
+		defer func() {
+			assertCorrectMessage(t, fmt.Sprint(recover()), "RandomDFS needs at least one letter and no negative carets, not 2 and -1")
+		}()
+		RandomDFS(rand.New(rand.NewSource(3)), 2, -1)
+	})
+}
@@ -0,0 +1,97 @@
+package prefcode
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"unicode"
+)
+
+// ReadDFS reads a DFS sequence of 1s and 0s from r, white space ignored, and
+// returns the code over alphabet with that tree, children in natural rune
+// order, labelled in dictionary order.  The sequence is read a symbol at a
+// time and the code built as its leaves come, so only the code, not the
+// sequence, is ever held in memory.  The sequence ends with r; anything but
+// white space after the tree is complete is an error.
+func ReadDFS(alphabet []rune, r io.Reader) (PrefCode, error) {
+	pc, err := NewPrefCodeAlphaString(string(alphabet))
+	if nil != err {
+		return nil, err
+	}
+	code := make(map[string]int)
+	d := newDFSDecoder(pc.alphabet, func(leaf string) { code[leaf] = len(code) })
+	in := bufio.NewReader(r)
+	for {
+		c, _, err := in.ReadRune()
+		if io.EOF == err {
+			break
+		}
+		if nil != err {
+			return nil, err
+		}
+		switch {
+		case '0' == c || '1' == c:
+			if err := d.push('1' == c); nil != err {
+				return nil, errors.New("DFS sequence " + err.Error())
+			}
+		case !unicode.IsSpace(c):
+			return nil, errors.New("Unexpected " + strconv.QuoteRune(c) + " in DFS sequence at " + strconv.Itoa(d.pos))
+		}
+	}
+	if err := d.end(); nil != err {
+		return nil, errors.New("DFS sequence " + err.Error())
+	}
+	pc.code = code
+	pc.reindex()
+	return pc, nil
+}
+
+// dfsDecoder follows a DFS sequence over the sorted letters a symbol at a
+// time, passing each leaf to emit as it is reached, in dictionary order.
+type dfsDecoder struct {
+	letters []rune
+	emit    func(leaf string)
+	word    []rune
+	next    []int // next[d] is the index of the next child at depth d+1
+	pos     int   // the number of symbols pushed
+	done    bool  // the tree is complete
+}
+
+func newDFSDecoder(letters []rune, emit func(leaf string)) *dfsDecoder {
+	return &dfsDecoder{letters: letters, emit: emit}
+}
+
+// push takes the next symbol, a caret or a leaf.
+func (d *dfsDecoder) push(caret bool) error {
+	if d.done {
+		return errors.New("goes on past its tree at " + strconv.Itoa(d.pos))
+	}
+	d.pos++
+	if caret {
+		d.word = append(d.word, d.letters[0])
+		d.next = append(d.next, 1)
+		return nil
+	}
+	d.emit(wordString(d.word))
+	// climb until some caret still has children to come.
+	for 0 < len(d.next) && d.next[len(d.next)-1] == len(d.letters) {
+		d.word = d.word[:len(d.word)-1]
+		d.next = d.next[:len(d.next)-1]
+	}
+	if 0 == len(d.next) {
+		d.done = true
+		return nil
+	}
+	d.word[len(d.word)-1] = d.letters[d.next[len(d.next)-1]]
+	d.next[len(d.next)-1]++
+	return nil
+}
+
+// end returns an error unless the tree is complete.
+func (d *dfsDecoder) end() error {
+	if !d.done {
+		return errors.New("ends before its tree is complete")
+	}
+	return nil
+}
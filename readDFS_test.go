@@ -0,0 +1,59 @@
+package prefcode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadDFS(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking codes read from a stream.", func(t *testing.T) {
+		pc, err := ReadDFS([]rune("01"), strings.NewReader("11 01\n000\n"))
+		if nil != err {
+			t.Fatalf("ReadDFS: %v", err)
+		}
+		assertCorrectMessage(t, pc.String(), "[00 0], [010 1], [011 2], [1 3]")
+
+		pc, err = ReadDFS([]rune("abc"), iotest.OneByteReader(strings.NewReader("0")))
+		if nil != err {
+			t.Fatalf("ReadDFS of the trivial code: %v", err)
+		}
+		assertCorrectMessage(t, pc.String(), "[𝛆 0]")
+	})
+
+	t.Run("Checking a long stream builds a deep code.", func(t *testing.T) {
+		vine := io.MultiReader(strings.NewReader(strings.Repeat("10", 1000)), strings.NewReader("0"))
+		pc, err := ReadDFS([]rune("01"), vine)
+		if nil != err {
+			t.Fatalf("ReadDFS of a vine: %v", err)
+		}
+		assertCorrectMessage(t, fmt.Sprint(pc.Size(), pc.MaxDepth(), pc.IsRightVine()), "1001 1000 true")
+		assertCorrectMessage(t, DFSFormat{}.Format(pc), strings.Repeat("10", 1000)+"0")
+	})
+
+	t.Run("Checking bad streams are rejected.", func(t *testing.T) {
+		for _, c := range []struct {
+			in   io.Reader
+			want string
+		}{
+			{strings.NewReader("1 x00"), "Unexpected 'x' in DFS sequence at 1"},
+			{strings.NewReader("10"), "DFS sequence ends before its tree is complete"},
+			{strings.NewReader("100 0"), "DFS sequence goes on past its tree at 3"},
+			{iotest.ErrReader(errors.New("Broken reader")), "Broken reader"},
+		} {
+			_, err := ReadDFS([]rune("01"), c.in)
+			assertCorrectMessage(t, fmt.Sprint(err), c.want)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package prefcode
+
+import (
+	"iter"
+	"sort"
+)
+
+// Neighbors yields the codes adjacent to p in the refinement graph, that is,
+// the codes one caret away from p, which pass filter.  First come the
+// expansions at each leaf (in dictionary order), then the reductions at each
+// exposed caret.  Labels follow ExpandAt and ReduceAt.  A nil filter accepts
+// everything.  p itself is never modified.
+func (p *prefixCode) Neighbors(filter func(PrefCode) bool) iter.Seq[PrefCode] {
+	return func(yield func(PrefCode) bool) {
+		for _, leaf := range p.sortedKeys() {
+			q := p.clone()
+			q.ExpandAt(leaf)
+			if (nil == filter || filter(q)) && !yield(q) {
+				return
+			}
+		}
+		for _, caret := range p.ExposedCarets() {
+			q := p.clone()
+			q.ReduceAt(caret)
+			if (nil == filter || filter(q)) && !yield(q) {
+				return
+			}
+		}
+	}
+}
+
+// Interval yields every code c over the common alphabet with lower ≤ c ≤ upper
+// in the refinement order, i.e. c refines lower and upper refines c.  Each code
+// carries its natural (dictionary order) labels.  Nothing is yielded if upper
+// does not refine lower or if the alphabets differ.
+func Interval(lower, upper PrefCode) iter.Seq[PrefCode] {
+	return SearchInterval(lower, upper, nil)
+}
+
+// SearchInterval yields the codes of Interval(lower, upper) which pass pred.
+// A nil pred accepts everything.  Stopping the iteration early stops the
+// enumeration itself, so huge intervals can be searched for a first hit.
+func SearchInterval(lower, upper PrefCode, pred func(PrefCode) bool) iter.Seq[PrefCode] {
+	return func(yield func(PrefCode) bool) {
+		alpha := lower.Alphabet()
+		if string(alpha) != string(upper.Alphabet()) {
+			return
+		}
+		inner := internalNodes(lower)
+		outer := internalNodes(upper)
+
+		// the optional carets: those of upper missing from lower.
+		var extra []string
+		for k := range inner {
+			if !outer[k] {
+				return // upper does not refine lower
+			}
+		}
+		for k := range outer {
+			if !inner[k] {
+				extra = append(extra, k)
+			}
+		}
+		// dictionary order puts every caret after its parent.
+		sort.Strings(extra)
+
+		chosen := make(map[string]bool, len(outer))
+		for k := range inner {
+			chosen[k] = true
+		}
+
+		// decide the extra carets in order: a caret can only be added
+		// if its parent is already there.
+		var walk func(ii int) bool
+		walk = func(ii int) bool {
+			if ii == len(extra) {
+				c := codeFromInternalNodes(alpha, isOrdered(lower), chosen)
+				if nil != pred && !pred(c) {
+					return true
+				}
+				return yield(c)
+			}
+			caret := extra[ii]
+			if !walk(ii + 1) {
+				return false
+			}
+			if "" != caret && !chosen[trimLastChar(caret)] {
+				return true
+			}
+			chosen[caret] = true
+			more := walk(ii + 1)
+			delete(chosen, caret)
+			return more
+		}
+		walk(0)
+	}
+}
+
+// codeFromInternalNodes builds the code over alpha with the given (prefix
+// closed) set of carets, with natural labels.  If ordered, its letters are
+// ordered as alpha lists them, see order.go.
+func codeFromInternalNodes(alpha []rune, ordered bool, nodes map[string]bool) *prefixCode {
+	var c prefixCode
+	c.alphabet = make([]rune, len(alpha))
+	copy(c.alphabet, alpha)
+	if ordered {
+		c.rank = rankLetters(c.alphabet)
+	}
+	c.code = make(map[string]int, len(nodes)*(len(alpha)-1)+1)
+	if 0 == len(nodes) {
+		c.code[EmptyString] = 0
+		c.reindex()
+		return &c
+	}
+
+	for node := range nodes {
+		for _, r := range alpha {
+			if !nodes[node+string(r)] {
+				c.code[node+string(r)] = 0
+			}
+		}
+	}
+	for k, leaf := range c.sortedKeys() {
+		c.code[leaf] = k
+	}
+	c.reindex()
+	return &c
+}
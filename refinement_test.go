@@ -0,0 +1,105 @@
+package prefcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRefinement(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking Neighbors.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking Neighbors.")
+		}
+		baseCode.ExpandAt("")
+
+		var got []string
+		for q := range baseCode.Neighbors(nil) {
+			got = append(got, q.String())
+		}
+		assertCorrectMessage(t, strings.Join(got, " | "), "[00 0], [01 1], [1 2] | [0 0], [10 1], [11 2] | [𝛆 0]")
+
+		// the neighbours are copies.
+		assertCorrectMessage(t, baseCode.String(), "[0 0], [1 1]")
+	})
+
+	t.Run("Checking Neighbors with filter and early stop.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking Neighbors filter.")
+		}
+		baseCode.ExpandAt("10")
+
+		bigger := func(q PrefCode) bool { return q.Size() > baseCode.Size() }
+		count := 0
+		for range baseCode.Neighbors(bigger) {
+			count++
+		}
+		assertCorrectMessage(t, strings.Repeat("x", count), "xxxx")
+
+		var first string
+		for q := range baseCode.Neighbors(nil) {
+			first = q.String()
+			break
+		}
+		assertCorrectMessage(t, first, "[00 0], [01 1], [100 2], [101 3], [11 4]")
+	})
+	t.Run("Checking Interval.", func(t *testing.T) {
+		lower, err := NewUniformCode([]rune("01"), 1)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewUniformCode in test checking Interval.")
+		}
+		upper, err := NewUniformCode([]rune("01"), 2)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewUniformCode in test checking Interval.")
+		}
+
+		var got []string
+		for c := range Interval(lower, upper) {
+			got = append(got, c.String())
+		}
+		assertCorrectMessage(t, strings.Join(got, " | "),
+			"[0 0], [1 1] | [0 0], [10 1], [11 2] | [00 0], [01 1], [1 2] | [00 0], [01 1], [10 2], [11 3]")
+
+		// wrong way round: upper does not refine lower.
+		count := 0
+		for range Interval(upper, lower) {
+			count++
+		}
+		assertCorrectMessage(t, strings.Repeat("x", count), "")
+	})
+
+	t.Run("Checking SearchInterval.", func(t *testing.T) {
+		lower, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking SearchInterval.")
+		}
+		upper, err := NewUniformCode([]rune("01"), 3)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewUniformCode in test checking SearchInterval.")
+		}
+
+		// five leaves means four carets: 6 such binary trees have depth at most 3.
+		fiveLeaves := func(c PrefCode) bool { return 5 == c.Size() }
+		count := 0
+		for range SearchInterval(lower, upper, fiveLeaves) {
+			count++
+		}
+		assertCorrectMessage(t, strings.Repeat("x", count), "xxxxxx")
+
+		var first string
+		for c := range SearchInterval(lower, upper, fiveLeaves) {
+			first = c.String()
+			break
+		}
+		assertCorrectMessage(t, first, "[0 0], [100 1], [101 2], [110 3], [111 4]")
+	})
+}
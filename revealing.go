@@ -0,0 +1,181 @@
+package prefcode
+
+import (
+	"sort"
+	"strings"
+)
+
+// For a pair with domain D and range R, the leaves of D which are carets of R
+// root the components of R−D, and the leaves of R which are carets of D root
+// those of D−R.  Starting at the root a of a component of R−D and following
+// the pair through leaves common to D and R (an iterated augmentation chain)
+// one ends at a leaf of R which is not a leaf of D; the component has an
+// attractor if that leaf lies below a.  Dually, following the pair backwards
+// from the root of a component of D−R, it has a repeller if the chain ends
+// below the root.  The pair is revealing (Brin) when every component has
+// one, and then the attractors and repellers show the periodic points of the
+// element, the first step of the conjugacy test in V.
+
+// Component is a component of R−D (Kind Attracting) or D−R (Kind Repelling)
+// of a tree pair.  End is the leaf the chain from Root ends at, after Period
+// steps, and Revealed says whether it lies below Root, in which case the
+// component has the periodic point Root followed by the rest of End repeated
+// forever.
+type Component struct {
+	Root     string
+	End      string
+	Period   int
+	Kind     FixedPointKind
+	Revealed bool
+}
+
+// PeriodicPoint returns the periodic point of a revealed component, as for
+// FixedPoints.  Its period under t is c.Period.
+func (c Component) PeriodicPoint() (prefix, cycle string) {
+	root := c.Root
+	if EmptyString == root {
+		root = ""
+	}
+	return root, strings.TrimPrefix(c.End, root)
+}
+
+// Components returns the components of R−D then D−R, each in dictionary
+// order of their roots.
+func (t TreePair) Components() []Component {
+	var components []Component
+	for _, leaf := range t.domain.sortedKeys() {
+		if t.rng.IsInternal(leaf) {
+			end, k := t.chain(leaf, true)
+			components = append(components, Component{Root: leaf, End: end, Period: k, Kind: Attracting, Revealed: isBelow(end, leaf)})
+		}
+	}
+	for _, leaf := range t.rng.sortedKeys() {
+		if t.domain.IsInternal(leaf) {
+			end, k := t.chain(leaf, false)
+			components = append(components, Component{Root: leaf, End: end, Period: k, Kind: Repelling, Revealed: isBelow(end, leaf)})
+		}
+	}
+	return components
+}
+
+// IsRevealing reports whether every component of t has its attractor or
+// repeller.
+func (t TreePair) IsRevealing() bool {
+	for _, c := range t.Components() {
+		if !c.Revealed {
+			return false
+		}
+	}
+	return true
+}
+
+// chain follows t forwards from the domain leaf x (backwards from the range
+// leaf x) while the words met are leaves of both codes, returning the last
+// and the number of steps.
+func (t TreePair) chain(x string, forwards bool) (string, int) {
+	from, to := t.domain, t.rng
+	if !forwards {
+		from, to = to, from
+	}
+	k := 0
+	for {
+		x = to.LeafAtLabel(from.LabelAtLeaf(x))
+		k++
+		if !from.IsLeaf(x) {
+			return x, k
+		}
+	}
+}
+
+// isBelow reports whether word lies strictly below node.
+func isBelow(word, node string) bool {
+	if EmptyString == node {
+		return EmptyString != word
+	}
+	return len(word) > len(node) && strings.HasPrefix(word, node)
+}
+
+// MakeRevealing returns a revealing pair for the element t, found by
+// expanding its reduced form.  While a component lacks its attractor, say,
+// the chain from its root ends at a root of D−R or in another component of
+// R−D.  In the first case the subtree of D there is grafted onto the leaf
+// before it on the chain (and so, through the pair, onto the end itself),
+// which moves the component of D−R back along the chain until it meets the
+// component of R−D and they cancel; in the second the root is expanded,
+// moving the component forward.  Repellers are dealt with dually.
+func (t TreePair) MakeRevealing() TreePair {
+	p := t.Reduce()
+	for {
+		c, ok := p.unrevealed()
+		if !ok {
+			return p
+		}
+		p.reveal(c)
+	}
+}
+
+// unrevealed returns the first component lacking its attractor or repeller.
+func (t TreePair) unrevealed() (Component, bool) {
+	for _, c := range t.Components() {
+		if !c.Revealed {
+			return c, true
+		}
+	}
+	return Component{}, false
+}
+
+// reveal makes one expansion of t in place towards revealing c.
+func (t TreePair) reveal(c Component) {
+	from, to := t.domain, t.rng
+	if Repelling == c.Kind {
+		from, to = to, from
+	}
+	// the leaf before the end of the chain, on the side of from.
+	last := c.Root
+	for ii := 1; ii < c.Period; ii++ {
+		last = to.LeafAtLabel(from.LabelAtLeaf(last))
+	}
+	if EmptyString == last {
+		last = ""
+	}
+	if from.IsInternal(c.End) {
+		for _, caret := range subtreeCarets(from, c.End) {
+			t.expandSide(from, last+caret)
+		}
+		return
+	}
+	t.expandSide(from, c.Root)
+}
+
+// expandSide expands t at the leaf of side, one of its codes.
+func (t TreePair) expandSide(side *prefixCode, leaf string) {
+	if "" == leaf {
+		leaf = EmptyString
+	}
+	if side == t.domain {
+		t.expandAt(leaf)
+		return
+	}
+	t.expandAt(t.domain.LeafAtLabel(t.rng.LabelAtLeaf(leaf)))
+}
+
+// subtreeCarets returns the carets of pc at or below node, as words relative
+// to node, shallowest first.
+func subtreeCarets(pc *prefixCode, node string) []string {
+	if EmptyString == node {
+		node = ""
+	}
+	var carets []string
+	for caret := range internalNodes(pc) {
+		if strings.HasPrefix(caret, node) {
+			carets = append(carets, caret[len(node):])
+		}
+	}
+	sort.Slice(carets, func(i, j int) bool {
+		if li, lj := wordLen(carets[i]), wordLen(carets[j]); li != lj {
+			return li < lj
+		}
+		return carets[i] < carets[j]
+	})
+	return carets
+}
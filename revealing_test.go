@@ -0,0 +1,64 @@
+package prefcode
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestRevealing(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	x0 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "11": 2}, map[string]int{"00": 0, "01": 1, "1": 2})
+
+	t.Run("Checking Components of a revealing pair.", func(t *testing.T) {
+		assertCorrectMessage(t, strconv.FormatBool(x0.IsRevealing()), "true")
+		components := x0.Components()
+		assertCorrectMessage(t, fmt.Sprint(components), "[{0 00 1 attracting true} {1 11 1 repelling true}]")
+		prefix, cycle := components[0].PeriodicPoint()
+		assertCorrectMessage(t, prefix+" "+cycle, "0 0")
+		assertCorrectMessage(t, x0.MakeRevealing().String(), x0.String())
+	})
+
+	t.Run("Checking MakeRevealing.", func(t *testing.T) {
+		// an element of order two whose reduced pair hides it.
+		p := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "11": 2}, map[string]int{"00": 2, "01": 1, "1": 0})
+		assertCorrectMessage(t, strconv.FormatBool(p.IsRevealing()), "false")
+		assertCorrectMessage(t, fmt.Sprint(p.Components()), "[{0 1 1 attracting false} {1 0 1 repelling false}]")
+		q := p.MakeRevealing()
+		assertCorrectMessage(t, q.String(), "[00 0], [01 1], [10 2], [11 3] -> [00 3], [01 2], [10 0], [11 1]")
+		assertCorrectMessage(t, strconv.FormatBool(q.IsRevealing() && q.Equals(p)), "true")
+	})
+
+	t.Run("Checking MakeRevealing on random elements.", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(5))
+		for trial := 0; trial < 200; trial++ {
+			alpha := []string{"01", "abc"}[trial%2]
+			d, _ := NewPrefCodeAlphaString(alpha)
+			r, _ := NewPrefCodeAlphaString(alpha)
+			for ii := 0; ii < 1+rnd.Intn(6); ii++ {
+				keys := d.sortedKeys()
+				d.ExpandAt(keys[rnd.Intn(len(keys))])
+				keys = r.sortedKeys()
+				r.ExpandAt(keys[rnd.Intn(len(keys))])
+			}
+			labels := make(map[string]int, r.Size())
+			for ii, jj := range rnd.Perm(r.Size()) {
+				labels[r.sortedKeys()[ii]] = jj
+			}
+			r.SetCode(labels)
+			p, _ := NewTreePair(d, r)
+			q := p.MakeRevealing()
+			if !q.IsRevealing() || !q.Equals(p) {
+				assertCorrectMessage(t, q.String(), "a revealing pair for "+p.String())
+			}
+		}
+	})
+}
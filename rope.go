@@ -0,0 +1,208 @@
+package prefcode
+
+import (
+	"math/rand/v2"
+	"sort"
+)
+
+// ropeNode is a node of an implicit treap holding the leaves of a code in
+// label order: the label of a leaf is the rank of its node.  Expanding or
+// reducing then only splices a few nodes in or out, in logarithmic time,
+// instead of rewriting every later label in the code map.  The map values
+// are brought back in step lazily (see syncLabels).
+type ropeNode struct {
+	leaf                string
+	prio                uint32
+	size                int
+	left, right, parent *ropeNode
+}
+
+func ropeSize(n *ropeNode) int {
+	if nil == n {
+		return 0
+	}
+	return n.size
+}
+
+// fix recomputes the size of n and reattaches its children.
+func (n *ropeNode) fix() {
+	n.size = 1 + ropeSize(n.left) + ropeSize(n.right)
+	if nil != n.left {
+		n.left.parent = n
+	}
+	if nil != n.right {
+		n.right.parent = n
+	}
+}
+
+// ropeMerge joins two ropes, all of a before all of b.
+func ropeMerge(a, b *ropeNode) *ropeNode {
+	switch {
+	case nil == a:
+		return b
+	case nil == b:
+		return a
+	case a.prio > b.prio:
+		a.right = ropeMerge(a.right, b)
+		a.fix()
+		return a
+	default:
+		b.left = ropeMerge(a, b.left)
+		b.fix()
+		return b
+	}
+}
+
+// ropeSplit cuts t into its first k nodes and the rest.
+func ropeSplit(t *ropeNode, k int) (*ropeNode, *ropeNode) {
+	if nil == t {
+		return nil, nil
+	}
+	if ropeSize(t.left) >= k {
+		l, r := ropeSplit(t.left, k)
+		t.left = r
+		t.fix()
+		if nil != l {
+			l.parent = nil
+		}
+		t.parent = nil
+		return l, t
+	}
+	l, r := ropeSplit(t.right, k-ropeSize(t.left)-1)
+	t.right = l
+	t.fix()
+	if nil != r {
+		r.parent = nil
+	}
+	t.parent = nil
+	return t, r
+}
+
+// rank returns the position of n in its rope.
+func (n *ropeNode) rank() int {
+	r := ropeSize(n.left)
+	for nil != n.parent {
+		if n == n.parent.right {
+			r += ropeSize(n.parent.left) + 1
+		}
+		n = n.parent
+	}
+	return r
+}
+
+// ropeSelect returns the node at position k of t, or nil if out of range.
+func ropeSelect(t *ropeNode, k int) *ropeNode {
+	for nil != t {
+		switch l := ropeSize(t.left); {
+		case k < l:
+			t = t.left
+		case k == l:
+			return t
+		default:
+			k -= l + 1
+			t = t.right
+		}
+	}
+	return nil
+}
+
+// ropeWalk calls fn on the nodes of t in order.
+func ropeWalk(t *ropeNode, fn func(n *ropeNode)) {
+	var stack []*ropeNode
+	for nil != t || 0 < len(stack) {
+		for nil != t {
+			stack = append(stack, t)
+			t = t.left
+		}
+		t = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		fn(t)
+		t = t.right
+	}
+}
+
+// newRopeNode returns a single node rope for leaf, registered in p.nodes.
+func (p *prefixCode) newRopeNode(leaf string) *ropeNode {
+	n := &ropeNode{leaf: leaf, prio: rand.Uint32(), size: 1}
+	p.nodes[leaf] = n
+	return n
+}
+
+// buildRope rebuilds the rope from the code map, ordering leaves by label
+// (then by word, should a careless SetCode have repeated labels).
+func (p *prefixCode) buildRope() {
+	leaves := make([]string, 0, len(p.code))
+	for leaf := range p.code {
+		leaves = append(leaves, leaf)
+	}
+	sort.Slice(leaves, func(i, j int) bool {
+		if p.code[leaves[i]] != p.code[leaves[j]] {
+			return p.code[leaves[i]] < p.code[leaves[j]]
+		}
+		return leaves[i] < leaves[j]
+	})
+
+	p.ropeFrom(leaves)
+}
+
+// ropeFrom builds the rope holding leaves, which are in label order.
+func (p *prefixCode) ropeFrom(leaves []string) {
+	p.nodes = make(map[string]*ropeNode, len(leaves))
+	p.order = nil
+	for _, leaf := range leaves {
+		p.order = ropeMerge(p.order, p.newRopeNode(leaf))
+	}
+	p.stale = false
+}
+
+// replaceLeafInOrder swaps the node of old for nodes of the given leaves, in
+// that order, and returns the label old had.
+func (p *prefixCode) replaceLeafInOrder(old string, leaves []string) int {
+	label := p.nodes[old].rank()
+	delete(p.nodes, old)
+	left, rest := ropeSplit(p.order, label)
+	_, right := ropeSplit(rest, 1)
+	for _, leaf := range leaves {
+		left = ropeMerge(left, p.newRopeNode(leaf))
+	}
+	p.order = ropeMerge(left, right)
+	p.stale = true
+	return label
+}
+
+// removeLeafFromOrder takes the node of leaf out of the rope.
+func (p *prefixCode) removeLeafFromOrder(leaf string) {
+	label := p.nodes[leaf].rank()
+	delete(p.nodes, leaf)
+	left, rest := ropeSplit(p.order, label)
+	_, right := ropeSplit(rest, 1)
+	p.order = ropeMerge(left, right)
+	p.stale = true
+}
+
+// insertLeafInOrder puts a node for leaf at position label of the rope.
+func (p *prefixCode) insertLeafInOrder(leaf string, label int) {
+	left, right := ropeSplit(p.order, label)
+	p.order = ropeMerge(ropeMerge(left, p.newRopeNode(leaf)), right)
+	p.stale = true
+}
+
+// syncLabels writes the labels held by the rope back into the code map and
+// the label index.  It is a no-op unless ExpandAt or ReduceAt ran since the
+// last call, and must precede any bulk read of the labels.
+func (p *prefixCode) syncLabels() {
+	if !p.stale {
+		return
+	}
+	if cap(p.leaves) < len(p.nodes) {
+		p.leaves = make([]string, len(p.nodes))
+	}
+	p.leaves = p.leaves[:len(p.nodes)]
+	label := 0
+	ropeWalk(p.order, func(n *ropeNode) {
+		p.code[n.leaf] = label
+		p.leaves[label] = n.leaf
+		label++
+	})
+	p.stale = false
+}
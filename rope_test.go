@@ -0,0 +1,70 @@
+package prefcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRope(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	// ReduceAt over leaves with scattered labels closes ranks in order.
+	t.Run("Checking ReduceAt with scattered labels.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking scattered ReduceAt.")
+		}
+		baseCode.ExpandAt("10")
+		baseCode.ApplyPerm(map[int]int{0: 1, 1: 0, 2: 3, 3: 2})
+		assertCorrectMessage(t, baseCode.String(), "[0 1], [100 0], [101 3], [11 2]")
+
+		baseCode.ReduceAt("1")
+		assertCorrectMessage(t, baseCode.String(), "[0 1], [1 0]")
+	})
+
+	// labels read between expansions (without syncing) must be right.
+	t.Run("Checking labels during bulk expansion.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking bulk expansion.")
+		}
+		for ii := 0; ii < 6; ii++ {
+			baseCode.ExpandAt(strings.Repeat("1", ii))
+			baseCode.ExpandAt(strings.Repeat("0", ii+1))
+		}
+		assertCorrectMessage(t, baseCode.LeafAtLabel(1), "0000001")
+		assertCorrectMessage(t, baseCode.LeafAtLabel(12), "111111")
+		if 6 != baseCode.LabelAtLeaf("01") {
+			assertCorrectMessage(t, "wrong label ", "at leaf 01")
+		}
+
+		want, _ := NewPrefCode()
+		for ii := 0; ii < 6; ii++ {
+			want.ExpandAt(strings.Repeat("1", ii))
+		}
+		for ii := 0; ii < 6; ii++ {
+			want.ExpandAt(strings.Repeat("0", ii+1))
+		}
+		assertCorrectMessage(t, baseCode.String(), want.String())
+	})
+}
+
+// BenchmarkBulkExpand grows a code breadth first, one caret at a time; every
+// expansion used to relabel the whole code.
+func BenchmarkBulkExpand(b *testing.B) {
+	for ii := 0; ii < b.N; ii++ {
+		pc, _ := NewPrefCode()
+		queue := []string{""}
+		for jj := 0; jj < 4000; jj++ {
+			pc.ExpandAt(queue[0])
+			queue = append(queue[1:], queue[0]+"0", queue[0]+"1")
+		}
+		pc.Code()
+	}
+}
@@ -0,0 +1,107 @@
+package prefcode
+
+import (
+	"errors"
+	"math/big"
+)
+
+// IsCyclicOrderPreserving reports whether t keeps the cyclic order of the
+// leaves, that is lies in T: its range leaves, in dictionary order, are a
+// rotation of the images of its domain leaves.
+func (t TreePair) IsCyclicOrderPreserving() bool {
+	_, ok := t.rotation()
+	return ok
+}
+
+// rotation returns j such that the ii-th domain leaf, in dictionary order,
+// goes to the (ii+j)-th range leaf mod the size, if there is one.
+func (t TreePair) rotation() (int, bool) {
+	domain, rng := t.domain.sortedKeys(), t.rng.sortedKeys()
+	m := len(domain)
+	j := 0
+	for j < m && t.rng.LabelAtLeaf(rng[j]) != t.domain.LabelAtLeaf(domain[0]) {
+		j++
+	}
+	for ii, leaf := range domain {
+		if t.rng.LabelAtLeaf(rng[(ii+j)%m]) != t.domain.LabelAtLeaf(leaf) {
+			return 0, false
+		}
+	}
+	return j, true
+}
+
+// RotationNumber returns the rotation number, in [0, 1), of the circle map
+// induced by t, which must lie in T.  Elements of T have rational rotation
+// numbers p/q, and q is the least power of t with a fixed point on the
+// circle; p then counts how often the lift of t wraps round on the orbit of
+// that point.  Powers are tried up to s^2 for s leaves in the reduced form.
+func (t TreePair) RotationNumber() (*big.Rat, error) {
+	r := t.Reduce()
+	j, ok := r.rotation()
+	if !ok {
+		return nil, errors.New("Tree pair is not cyclic order preserving")
+	}
+	limit := r.Size() * r.Size()
+	power := r
+	for q := 1; q <= limit; q++ {
+		if x, ok := power.circleFixedPoint(); ok {
+			// the lift may go once round on each step, so reduce mod 1.
+			return big.NewRat(int64(r.wraps(j, x, q)%q), int64(q)), nil
+		}
+		power, _ = power.Compose(r)
+	}
+	return nil, errors.New("No periodic orbit found up to period " + big.NewInt(int64(limit)).String())
+}
+
+// circleFixedPoint returns a point of [0,1) fixed by t, if there is one.  On
+// each domain leaf t is affine, x going to lo(r) + s(x - lo(d)), so the fixed
+// point, if any, solves a linear equation.
+func (t TreePair) circleFixedPoint() (*big.Rat, bool) {
+	for _, d := range t.domain.sortedKeys() {
+		loD, hiD := t.domain.LeafInterval(d)
+		loR, hiR := t.rng.LeafInterval(t.rng.LeafAtLabel(t.domain.LabelAtLeaf(d)))
+		s := new(big.Rat).Quo(new(big.Rat).Sub(hiR, loR), new(big.Rat).Sub(hiD, loD))
+		if 0 == s.Cmp(big.NewRat(1, 1)) {
+			if 0 == loD.Cmp(loR) {
+				return loD, true
+			}
+			continue
+		}
+		x := new(big.Rat).Sub(loR, new(big.Rat).Mul(s, loD))
+		x.Quo(x, new(big.Rat).Sub(big.NewRat(1, 1), s))
+		if loD.Cmp(x) <= 0 && x.Cmp(hiD) < 0 {
+			return x, true
+		}
+	}
+	return nil, false
+}
+
+// wraps runs q steps of the orbit of x under t, of rotation j, counting the
+// steps at which the lift passes 1: those from the domain leaves whose
+// images come round past the end of [0,1).
+func (t TreePair) wraps(j int, x *big.Rat, q int) int {
+	domain := t.domain.sortedKeys()
+	position := make(map[string]int, len(domain))
+	for ii, leaf := range domain {
+		position[leaf] = ii
+	}
+	p := 0
+	for step := 0; step < q; step++ {
+		d, _ := t.domain.LeafContaining(x)
+		if position[d]+j >= len(domain) {
+			p++
+		}
+		x = t.applyPoint(d, x)
+	}
+	return p
+}
+
+// applyPoint returns the image of the point x of the domain leaf d.
+func (t TreePair) applyPoint(d string, x *big.Rat) *big.Rat {
+	loD, hiD := t.domain.LeafInterval(d)
+	loR, hiR := t.rng.LeafInterval(t.rng.LeafAtLabel(t.domain.LabelAtLeaf(d)))
+	y := new(big.Rat).Sub(x, loD)
+	y.Mul(y, new(big.Rat).Sub(hiR, loR))
+	y.Quo(y, new(big.Rat).Sub(hiD, loD))
+	return y.Add(y, loR)
+}
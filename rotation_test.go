@@ -0,0 +1,65 @@
+package prefcode
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestRotation(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	x0 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "11": 2}, map[string]int{"00": 0, "01": 1, "1": 2})
+	// the rotation by a half, and the element of order three of T.
+	half := pairFromMaps(t, map[string]int{"0": 0, "1": 1}, map[string]int{"1": 0, "0": 1})
+	c := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "11": 2}, map[string]int{"10": 0, "11": 1, "0": 2})
+	// fixes 1/2, though its lift with F(0) in [0,1) goes once round.
+	round := pairFromMaps(t, map[string]int{"00": 0, "01": 1, "1": 2}, map[string]int{"0": 1, "10": 2, "11": 0})
+
+	t.Run("Checking IsCyclicOrderPreserving.", func(t *testing.T) {
+		assertCorrectMessage(t, strconv.FormatBool(x0.IsCyclicOrderPreserving()), "true")
+		assertCorrectMessage(t, strconv.FormatBool(c.IsCyclicOrderPreserving()), "true")
+		v := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "11": 2}, map[string]int{"0": 0, "11": 1, "10": 2})
+		assertCorrectMessage(t, strconv.FormatBool(v.IsCyclicOrderPreserving()), "false")
+		_, err := v.RotationNumber()
+		assertCorrectMessage(t, err.Error(), "Tree pair is not cyclic order preserving")
+	})
+
+	t.Run("Checking RotationNumber.", func(t *testing.T) {
+		for _, test := range []struct {
+			pair TreePair
+			want string
+		}{{x0, "0"}, {half, "1/2"}, {c, "1/3"}, {c.Inverse(), "2/3"}, {round, "0"}} {
+			rho, err := test.pair.RotationNumber()
+			if nil != err {
+				assertCorrectMessage(t, "Faied to ", "RotationNumber() of "+test.pair.String())
+				continue
+			}
+			assertCorrectMessage(t, rho.RatString(), test.want)
+		}
+
+		// conjugation keeps the rotation number.
+		conj, _ := x0.Inverse().Compose(c)
+		conj, _ = conj.Compose(x0)
+		rho, _ := conj.RotationNumber()
+		assertCorrectMessage(t, rho.RatString(), "1/3")
+
+		// 0, 1/4, 1/2 is an orbit going once round the circle.
+		mixed, _ := half.Compose(x0)
+		rho, err := mixed.RotationNumber()
+		assertCorrectMessage(t, rho.RatString()+" "+strconv.FormatBool(nil == err), "1/3 true")
+
+		// squaring doubles the rotation number mod 1.
+		square, _ := c.Compose(c)
+		rho, _ = square.RotationNumber()
+		assertCorrectMessage(t, rho.RatString(), "2/3")
+		square, _ = square.Compose(square)
+		rho, _ = square.RotationNumber()
+		assertCorrectMessage(t, rho.RatString(), "1/3")
+	})
+}
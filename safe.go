@@ -0,0 +1,611 @@
+package prefcode
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"iter"
+	"sync"
+)
+
+// SafePrefCode guards a PrefCode with a sync.RWMutex so that it may be shared
+// between goroutines: reads hold the lock together, mutations alone.  The
+// reads of a prefixCode fill its caches lazily, which would race, so after
+// each mutation, and on wrapping, SafePrefCode fills them while it still
+// holds the lock alone, and reads then only read.
+//
+// Codes passed in, such as the q of Equals, are read without the lock of the
+// SafePrefCode.  A SafePrefCode passed in, the receiver included, is copied
+// under its own lock before the receiver locks, so no goroutine holds one
+// lock while waiting for another, or for the same lock twice.
+// The functions and iterators handed back by View, Neighbors, AlphabetOrbit
+// and SplitFunc take the read lock themselves; callbacks and loop bodies run
+// under it, so must not mutate the SafePrefCode.
+type SafePrefCode struct {
+	mu sync.RWMutex
+	pc PrefCode
+}
+
+var _ PrefCode = (*SafePrefCode)(nil)
+
+// NewSafePrefCode wraps pc, which should not be used directly afterwards.
+func NewSafePrefCode(pc PrefCode) *SafePrefCode {
+	sc := &SafePrefCode{pc: pc}
+	sc.settle()
+	return sc
+}
+
+// settle fills the caches of a prefixCode, see warm.
+func (sc *SafePrefCode) settle() {
+	if p, ok := sc.pc.(*prefixCode); ok {
+		p.warm()
+	}
+}
+
+// detach returns q, or a copy of q if q is a SafePrefCode, taken under its
+// own lock and released before the caller takes any.
+func detach(q PrefCode) PrefCode {
+	sq, ok := q.(*SafePrefCode)
+	if !ok {
+		return q
+	}
+	sq.mu.RLock()
+	defer sq.mu.RUnlock()
+	switch c := sq.pc.(type) {
+	case *prefixCode:
+		return c.clone()
+	case *CompactPrefCode:
+		return NewCompactFrom(c)
+	}
+	return pairCode(sq.pc.Alphabet(), sq.pc)
+}
+
+// unlock settles the code and releases the lock held for a mutation.
+func (sc *SafePrefCode) unlock() {
+	sc.settle()
+	sc.mu.Unlock()
+}
+
+// View returns a view of a copy of the code, as the code may change under a
+// view of the code itself.
+func (sc *SafePrefCode) View() CodeView {
+	return mapView(sc.Code())
+}
+
+func (sc *SafePrefCode) Neighbors(filter func(PrefCode) bool) iter.Seq[PrefCode] {
+	return func(yield func(PrefCode) bool) {
+		sc.mu.RLock()
+		defer sc.mu.RUnlock()
+		for q := range sc.pc.Neighbors(filter) {
+			if !yield(q) {
+				return
+			}
+		}
+	}
+}
+
+func (sc *SafePrefCode) AlphabetOrbit() iter.Seq2[Perm, PrefCode] {
+	return func(yield func(Perm, PrefCode) bool) {
+		sc.mu.RLock()
+		defer sc.mu.RUnlock()
+		for sigma, q := range sc.pc.AlphabetOrbit() {
+			if !yield(sigma, q) {
+				return
+			}
+		}
+	}
+}
+
+func (sc *SafePrefCode) SplitFunc() bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		sc.mu.RLock()
+		defer sc.mu.RUnlock()
+		return sc.pc.SplitFunc()(data, atEOF)
+	}
+}
+
+func (sc *SafePrefCode) Alphabet() []rune {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.Alphabet()
+}
+
+func (sc *SafePrefCode) SetAlphabet(alpha []rune) {
+	sc.mu.Lock()
+	defer sc.unlock()
+	sc.pc.SetAlphabet(alpha)
+}
+
+func (sc *SafePrefCode) SetCode(code map[string]int) {
+	sc.mu.Lock()
+	defer sc.unlock()
+	sc.pc.SetCode(code)
+}
+
+func (sc *SafePrefCode) Code() map[string]int {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.Code()
+}
+
+// Equals compares the strings of the codes, as prefixCode does, reading that
+// of q first.
+func (sc *SafePrefCode) Equals(q PrefCode) bool {
+	if q == sc {
+		return true
+	}
+	want := q.String()
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.String() == want
+}
+
+func (sc *SafePrefCode) ReduceAt(s string) bool {
+	sc.mu.Lock()
+	defer sc.unlock()
+	return sc.pc.ReduceAt(s)
+}
+
+func (sc *SafePrefCode) ExpandAt(s string) bool {
+	sc.mu.Lock()
+	defer sc.unlock()
+	return sc.pc.ExpandAt(s)
+}
+
+func (sc *SafePrefCode) ExpandToContain(word string) (int, error) {
+	sc.mu.Lock()
+	defer sc.unlock()
+	return sc.pc.ExpandToContain(word)
+}
+
+func (sc *SafePrefCode) ExpandAtAll(words []string) error {
+	sc.mu.Lock()
+	defer sc.unlock()
+	return sc.pc.ExpandAtAll(words)
+}
+
+func (sc *SafePrefCode) ReduceAtAll(words []string) error {
+	sc.mu.Lock()
+	defer sc.unlock()
+	return sc.pc.ReduceAtAll(words)
+}
+
+func (sc *SafePrefCode) ExpandToDepth(d int) {
+	sc.mu.Lock()
+	defer sc.unlock()
+	sc.pc.ExpandToDepth(d)
+}
+
+func (sc *SafePrefCode) GraftAt(leaf string, sub PrefCode) error {
+	sub = detach(sub)
+	sc.mu.Lock()
+	defer sc.unlock()
+	return sc.pc.GraftAt(leaf, sub)
+}
+
+func (sc *SafePrefCode) SubcodeAt(prefix string) (PrefCode, error) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.SubcodeAt(prefix)
+}
+
+func (sc *SafePrefCode) Product(q PrefCode) (PrefCode, error) {
+	q = detach(q)
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.Product(q)
+}
+
+func (sc *SafePrefCode) RelabelAlphabet(mapping map[rune]rune) (PrefCode, error) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.RelabelAlphabet(mapping)
+}
+
+func (sc *SafePrefCode) EmbedInAlphabet(bigger []rune) (PrefCode, error) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.EmbedInAlphabet(bigger)
+}
+
+func (sc *SafePrefCode) SameShape(q PrefCode) bool {
+	q = detach(q)
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.SameShape(q)
+}
+
+func (sc *SafePrefCode) ShapeBijection(q PrefCode) (map[rune]rune, bool) {
+	q = detach(q)
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.ShapeBijection(q)
+}
+
+func (sc *SafePrefCode) CanonicalizeUnderAlphabetSymmetry() (PrefCode, Perm) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.CanonicalizeUnderAlphabetSymmetry()
+}
+
+func (sc *SafePrefCode) ApplyPerm(perm Perm) bool {
+	sc.mu.Lock()
+	defer sc.unlock()
+	return sc.pc.ApplyPerm(perm)
+}
+
+func (sc *SafePrefCode) ComposePerm(perm Perm) error {
+	sc.mu.Lock()
+	defer sc.unlock()
+	return sc.pc.ComposePerm(perm)
+}
+
+func (sc *SafePrefCode) SwapPermAtKeys(a, b string) error {
+	sc.mu.Lock()
+	defer sc.unlock()
+	return sc.pc.SwapPermAtKeys(a, b)
+}
+
+func (sc *SafePrefCode) SwapLabels(pairs [][2]string) error {
+	sc.mu.Lock()
+	defer sc.unlock()
+	return sc.pc.SwapLabels(pairs)
+}
+
+func (sc *SafePrefCode) CycleLabels(leaves []string) error {
+	sc.mu.Lock()
+	defer sc.unlock()
+	return sc.pc.CycleLabels(leaves)
+}
+
+func (sc *SafePrefCode) Permutation() Perm {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.Permutation()
+}
+
+func (sc *SafePrefCode) Join(q PrefCode) (*prefixCode, error) {
+	q = detach(q)
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.Join(q)
+}
+
+func (sc *SafePrefCode) Meet(q PrefCode) (*prefixCode, error) {
+	q = detach(q)
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.Meet(q)
+}
+
+func (sc *SafePrefCode) ExposedCarets() []string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.ExposedCarets()
+}
+
+func (sc *SafePrefCode) NumExposedCarets() int {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.NumExposedCarets()
+}
+
+func (sc *SafePrefCode) ForEachExposedCaret(fn func(caret string) bool) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	sc.pc.ForEachExposedCaret(fn)
+}
+
+func (sc *SafePrefCode) LabelAtLeaf(leaf string) int {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.LabelAtLeaf(leaf)
+}
+
+func (sc *SafePrefCode) LeafAtLabel(label int) string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.LeafAtLabel(label)
+}
+
+func (sc *SafePrefCode) LabelsToLeaves() []string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.LabelsToLeaves()
+}
+
+func (sc *SafePrefCode) RelabelLexicographic() {
+	sc.mu.Lock()
+	defer sc.unlock()
+	sc.pc.RelabelLexicographic()
+}
+
+func (sc *SafePrefCode) RelabelBy(less func(a, b string) bool) {
+	sc.mu.Lock()
+	defer sc.unlock()
+	sc.pc.RelabelBy(less)
+}
+
+func (sc *SafePrefCode) InvertLabels() {
+	sc.mu.Lock()
+	defer sc.unlock()
+	sc.pc.InvertLabels()
+}
+
+func (sc *SafePrefCode) Transact(fn func(tx Tx) error) error {
+	sc.mu.Lock()
+	defer sc.unlock()
+	return sc.pc.Transact(fn)
+}
+
+func (sc *SafePrefCode) Size() int {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.Size()
+}
+
+func (sc *SafePrefCode) String() string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.String()
+}
+
+func (sc *SafePrefCode) WriteTo(w io.Writer) (int64, error) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.WriteTo(w)
+}
+
+func (sc *SafePrefCode) GetPrefixOf(s string) string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.GetPrefixOf(s)
+}
+
+func (sc *SafePrefCode) GetLongestPrefixOf(s string) (string, bool) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.GetLongestPrefixOf(s)
+}
+
+func (sc *SafePrefCode) GetAllCodePrefixesOf(s string) []string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.GetAllCodePrefixesOf(s)
+}
+
+func (sc *SafePrefCode) SortedLeaves() []string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.SortedLeaves()
+}
+
+func (sc *SafePrefCode) Entries() []LeafEntry {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.Entries()
+}
+
+func (sc *SafePrefCode) Page(afterLeaf string, limit int) []LeafEntry {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.Page(afterLeaf, limit)
+}
+
+// Deprecated: use SortedLeaves or Entries, as for prefixCode.
+func (sc *SafePrefCode) CodeToSlice() *[]string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.CodeToSlice()
+}
+
+func (sc *SafePrefCode) Tokenize(s string) ([]string, error) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.Tokenize(s)
+}
+
+func (sc *SafePrefCode) AverageLength(probs []float64) float64 {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.AverageLength(probs)
+}
+
+func (sc *SafePrefCode) Entropy(probs []float64) float64 {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.Entropy(probs)
+}
+
+func (sc *SafePrefCode) OptimalityGap(probs []float64) float64 {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.OptimalityGap(probs)
+}
+
+func (sc *SafePrefCode) DepthOf(leaf string) int {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.DepthOf(leaf)
+}
+
+func (sc *SafePrefCode) MaxDepth() int {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.MaxDepth()
+}
+
+func (sc *SafePrefCode) MinDepth() int {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.MinDepth()
+}
+
+func (sc *SafePrefCode) IsUniformDepth() bool {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.IsUniformDepth()
+}
+
+func (sc *SafePrefCode) DepthHistogram() map[int]int {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.DepthHistogram()
+}
+
+func (sc *SafePrefCode) MeanDepth() float64 {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.MeanDepth()
+}
+
+func (sc *SafePrefCode) DepthVariance() float64 {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.DepthVariance()
+}
+
+func (sc *SafePrefCode) InternalNodes() []string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.InternalNodes()
+}
+
+// WalkDFS holds the read lock for the whole walk, so fn must not mutate sc.
+func (sc *SafePrefCode) WalkDFS(fn func(node string, isLeaf bool, label int) error) error {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.WalkDFS(fn)
+}
+
+// WalkBFS holds the read lock as WalkDFS does.
+func (sc *SafePrefCode) WalkBFS(fn func(node string, isLeaf bool, label int) error) error {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.WalkBFS(fn)
+}
+
+// WalkPruned holds the read lock as WalkDFS does.
+func (sc *SafePrefCode) WalkPruned(prune func(prefix string) bool, visit func(leaf string, label int)) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	sc.pc.WalkPruned(prune, visit)
+}
+
+func (sc *SafePrefCode) Serialize() string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.Serialize()
+}
+
+func (sc *SafePrefCode) ToSExpr() string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.ToSExpr()
+}
+
+// StreamLeaves holds the read lock until the stream is closed, so the reader
+// must not mutate sc before then.
+func (sc *SafePrefCode) StreamLeaves(ctx context.Context) <-chan LeafEntry {
+	sc.mu.RLock()
+	in := sc.pc.StreamLeaves(ctx)
+	out := make(chan LeafEntry)
+	go func() {
+		defer close(out)
+		defer sc.mu.RUnlock()
+		for e := range in {
+			select {
+			case out <- e:
+			case <-ctx.Done():
+				for range in {
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (sc *SafePrefCode) NumCarets() int {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.NumCarets()
+}
+
+func (sc *SafePrefCode) ParentOf(word string) string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.ParentOf(word)
+}
+
+func (sc *SafePrefCode) ChildrenOf(word string) []string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.ChildrenOf(word)
+}
+
+func (sc *SafePrefCode) Siblings(leaf string) []string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.Siblings(leaf)
+}
+
+func (sc *SafePrefCode) IsLeaf(word string) bool {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.IsLeaf(word)
+}
+
+func (sc *SafePrefCode) IsInternal(word string) bool {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.IsInternal(word)
+}
+
+func (sc *SafePrefCode) IsBelowCode(word string) bool {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.IsBelowCode(word)
+}
+
+func (sc *SafePrefCode) LeavesInRange(lo, hi string) []string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.LeavesInRange(lo, hi)
+}
+
+func (sc *SafePrefCode) LongestCommonPrefix() string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.LongestCommonPrefix()
+}
+
+func (sc *SafePrefCode) SpineTo(leaf string) []string {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.SpineTo(leaf)
+}
+
+func (sc *SafePrefCode) IsRightVine() bool {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.IsRightVine()
+}
+
+func (sc *SafePrefCode) IsLeftVine() bool {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.IsLeftVine()
+}
+
+func (sc *SafePrefCode) IsFullTree() bool {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.IsFullTree()
+}
+
+func (sc *SafePrefCode) CaretTypeCounts() CaretCounts {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	return sc.pc.CaretTypeCounts()
+}
@@ -0,0 +1,103 @@
+package prefcode
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSafe(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking SafePrefCode passes calls on.", func(t *testing.T) {
+		pc, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking SafePrefCode.")
+		}
+		sc := NewSafePrefCode(pc)
+		assertCorrectMessage(t, strconv.FormatBool(sc.ExpandAt("10")), "true")
+		sc.SwapPermAtKeys("0", "11")
+		assertCorrectMessage(t, sc.String(), "[0 3], [100 1], [101 2], [11 0]")
+		assertCorrectMessage(t, strconv.Itoa(sc.View().Len()), "4")
+		count := 0
+		for range sc.Neighbors(nil) {
+			count++
+		}
+		assertCorrectMessage(t, strconv.Itoa(count), "5")
+		assertCorrectMessage(t, strconv.FormatBool(sc.Equals(pc)), "true")
+	})
+
+	// run with -race: readers and writers side by side.
+	t.Run("Checking concurrent use.", func(t *testing.T) {
+		pc, _ := NewUniformCode([]rune("01"), 4)
+		sc := NewSafePrefCode(pc)
+		var wg sync.WaitGroup
+		for ii := 0; ii < 4; ii++ {
+			wg.Add(2)
+			go func(ii int) {
+				defer wg.Done()
+				for jj := 0; jj < 50; jj++ {
+					word := strconv.FormatInt(int64(ii*50+jj), 2)
+					sc.ExpandAt(word)
+					sc.ReduceAt(word)
+					sc.SwapPermAtKeys(sc.LeafAtLabel(0), sc.LeafAtLabel(1))
+				}
+			}(ii)
+			go func() {
+				defer wg.Done()
+				for jj := 0; jj < 50; jj++ {
+					_ = sc.String()
+					sc.Permutation()
+					sc.ExposedCarets()
+					sc.LabelAtLeaf("0000")
+					sc.Code()
+				}
+			}()
+		}
+		wg.Wait()
+		assertCorrectMessage(t, strconv.Itoa(len(sc.Permutation())), strconv.Itoa(sc.Size()))
+	})
+
+	// with writers waiting, a second read lock, or two codes locked in
+	// opposite orders, would deadlock.
+	t.Run("Checking codes read each other without deadlock.", func(t *testing.T) {
+		pa, _ := NewUniformCode([]rune("01"), 3)
+		pb, _ := NewUniformCode([]rune("01"), 2)
+		a, b := NewSafePrefCode(pa), NewSafePrefCode(pb)
+		assertCorrectMessage(t, strconv.FormatBool(a.Equals(a)), "true")
+		var wg sync.WaitGroup
+		for _, pair := range [][2]*SafePrefCode{{a, b}, {b, a}, {a, a}} {
+			wg.Add(2)
+			go func(x, y *SafePrefCode) {
+				defer wg.Done()
+				for jj := 0; jj < 200; jj++ {
+					x.Equals(y)
+					x.Join(y)
+					x.Meet(y)
+					x.SameShape(y)
+				}
+			}(pair[0], pair[1])
+			go func(x *SafePrefCode) {
+				defer wg.Done()
+				for jj := 0; jj < 200; jj++ {
+					x.ExpandAt("0000")
+					x.ReduceAt("0000")
+				}
+			}(pair[0])
+		}
+		wg.Wait()
+		joined, err := a.Join(b)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "Join() in test checking deadlock.")
+		}
+		assertCorrectMessage(t, joined.String(), pa.String())
+		assertCorrectMessage(t, strconv.FormatBool(nil == a.GraftAt("111", a)), "true")
+		assertCorrectMessage(t, strconv.Itoa(a.Size()), "17")
+	})
+}
@@ -0,0 +1,104 @@
+package prefcode
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// The canonical line for a labelled code is its DFS sequence, children in
+// natural rune order as for CompactPrefCode.DFS, then " ; ", then the
+// permutation taking the index of each leaf in dictionary order to its label,
+// in the cycle notation of Perm.String.  The trivial code is "0 ; ()" and the
+// code 0:1, 1:0 over "01" is "100 ; (0 1)".  The alphabet is not part of the
+// line; Deserialize is told it.  A code ordering its letters otherwise (see
+// order.go) adds " ; " and its letters in their order, quoted, so over "zab"
+// the code with a caret at "z" is `1001000 ; (0 3 2 1 4) ; "zab"`: the DFS
+// sequence and the permutation stay in rune order.
+
+// Serialize returns the canonical line of p, from which Deserialize rebuilds
+// the same leaves with the same labels and letter order.
+func (p *prefixCode) Serialize() string {
+	line := NewCompactFrom(p).Serialize()
+	if p.IsOrdered() {
+		line += " ; " + strconv.Quote(string(p.alphabet))
+	}
+	return line
+}
+
+func (c *CompactPrefCode) Serialize() string {
+	return c.DFS() + " ; " + c.Permutation().String()
+}
+
+// Deserialize reads a code over the letters of alphabet from the canonical
+// line written by Serialize.  White space is ignored, except within the
+// quoted letter order.
+func Deserialize(alphabet, s string) (PrefCode, error) {
+	pc, err := NewPrefCodeAlphaString(alphabet)
+	if nil != err {
+		return nil, err
+	}
+	letters := pc.alphabet
+	dfs, rest, found := strings.Cut(s, ";")
+	if !found {
+		return nil, errors.New("Expected DFS ; permutation but found " + strconv.Quote(s))
+	}
+	cycles, order, ordered := strings.Cut(rest, ";")
+	if ordered {
+		if pc, err = orderedFrom(letters, order); nil != err {
+			return nil, err
+		}
+	}
+	dfs, err = DFSFormat{}.Normalize(strings.TrimSpace(dfs))
+	if nil != err {
+		return nil, err
+	}
+	leaves, err := dfsLeaves(letters, dfs)
+	if nil != err {
+		return nil, err
+	}
+	perm, err := ParsePerm(cycles, len(leaves))
+	if nil != err {
+		return nil, err
+	}
+	pc.code = make(map[string]int, len(leaves))
+	for ii, leaf := range leaves {
+		pc.code[leaf] = perm[ii]
+	}
+	pc.reindex()
+	return pc, nil
+}
+
+// orderedFrom returns the trivial code ordering the sorted letters as the
+// quoted order lists them.
+func orderedFrom(letters []rune, order string) (*prefixCode, error) {
+	order = strings.TrimSpace(order)
+	listed, err := strconv.Unquote(order)
+	if nil != err {
+		return nil, errors.New("Expected a quoted letter order but found " + strconv.Quote(order))
+	}
+	if string(MakeAlphabet(listed)) != string(letters) || len([]rune(listed)) != len(letters) {
+		return nil, errors.New("Letter order " + order + " does not list the alphabet " + strconv.Quote(string(letters)))
+	}
+	return NewPrefCodeOrdered([]rune(listed))
+}
+
+// dfsLeaves returns the leaves, in dictionary order, of the tree over the
+// sorted letters whose DFS sequence is dfs, or an error saying where dfs is
+// not one.  Unlike ValidDFSForPrefC it accepts "0", the trivial code.
+func dfsLeaves(letters []rune, dfs string) ([]string, error) {
+	var leaves []string
+	d := newDFSDecoder(letters, func(leaf string) { leaves = append(leaves, leaf) })
+	for _, r := range dfs {
+		if '0' != r && '1' != r {
+			return nil, errors.New("Unexpected " + strconv.QuoteRune(r) + " in DFS sequence " + strconv.Quote(dfs))
+		}
+		if err := d.push('1' == r); nil != err {
+			return nil, errors.New("DFS sequence " + strconv.Quote(dfs) + " " + err.Error())
+		}
+	}
+	if err := d.end(); nil != err {
+		return nil, errors.New("DFS sequence " + strconv.Quote(dfs) + " " + err.Error())
+	}
+	return leaves, nil
+}
@@ -0,0 +1,95 @@
+package prefcode
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSerialize(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking the canonical line of a labelled code.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking Serialize.")
+		}
+		assertCorrectMessage(t, baseCode.Serialize(), "0 ; ()")
+		baseCode.ExpandAt("0")
+		baseCode.ExpandAt("01")
+		baseCode.SwapPermAtKeys("00", "1")
+		assertCorrectMessage(t, baseCode.Serialize(), "1101000 ; (0 3)")
+		assertCorrectMessage(t, NewCompactFrom(baseCode).Serialize(), "1101000 ; (0 3)")
+		assertCorrectMessage(t, NewSafePrefCode(baseCode).Serialize(), "1101000 ; (0 3)")
+	})
+
+	t.Run("Checking codes round-trip through Deserialize.", func(t *testing.T) {
+		baseCode, err := NewPrefCodeAlphaString("abc")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString() in test checking Deserialize.")
+		}
+		baseCode.ExpandAt("b")
+		baseCode.ExpandAt("bc")
+		baseCode.SwapPermAtKeys("a", "bcb")
+		line := baseCode.Serialize()
+		assertCorrectMessage(t, line, "1010010000 ; (0 4)")
+
+		got, err := Deserialize("cba", "  1010010000;(0 4) ")
+		if nil != err {
+			t.Fatalf("Deserialize: %v", err)
+		}
+		assertCorrectMessage(t, got.String(), baseCode.String())
+		assertCorrectMessage(t, got.Serialize(), line)
+
+		got, err = Deserialize("01", "0 ; ()")
+		if nil != err {
+			t.Fatalf("Deserialize of the trivial code: %v", err)
+		}
+		assertCorrectMessage(t, got.String(), "[𝛆 0]")
+	})
+
+	t.Run("Checking ordered codes round-trip through Deserialize.", func(t *testing.T) {
+		ordered, err := NewPrefCodeOrdered([]rune("zab"))
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeOrdered() in test checking Deserialize.")
+		}
+		ordered.ExpandAt("z")
+		line := ordered.Serialize()
+		assertCorrectMessage(t, line, `1001000 ; (0 3 2 1 4) ; "zab"`)
+
+		got, err := Deserialize("abz", line)
+		if nil != err {
+			t.Fatalf("Deserialize of an ordered code: %v", err)
+		}
+		assertCorrectMessage(t, got.String(), "[zz 0], [za 1], [zb 2], [a 3], [b 4]")
+		assertCorrectMessage(t, strconv.FormatBool(got.Equals(ordered)), "true")
+		assertCorrectMessage(t, strconv.FormatBool(got.(*prefixCode).IsOrdered()), "true")
+		assertCorrectMessage(t, got.Serialize(), line)
+	})
+
+	t.Run("Checking Deserialize rejects bad lines.", func(t *testing.T) {
+		for _, c := range []struct{ alphabet, line, want string }{
+			{"01", "100", `Expected DFS ; permutation but found "100"`},
+			{"01", "10 ; ()", `DFS sequence "10" ends before its tree is complete`},
+			{"01", "1000 ; ()", `DFS sequence "1000" goes on past its tree at 3`},
+			{"01", "1x0 ; ()", `Unexpected 'x' in DFS sequence "1x0"`},
+			{"01", "100 ; (0 2)", "Point 2 out of range"},
+			{"", "0 ; ()", "Empty Alphabet forbidden"},
+			{"ab", "0 ; () ; ba", "Expected a quoted letter order but found \"ba\""},
+			{"ab", `0 ; () ; "bz"`, `Letter order "bz" does not list the alphabet "ab"`},
+			{"ab", `0 ; () ; "bab"`, `Letter order "bab" does not list the alphabet "ab"`},
+		} {
+			_, err := Deserialize(c.alphabet, c.line)
+			if nil == err {
+				t.Errorf("Deserialize(%q, %q) gave no error", c.alphabet, c.line)
+				continue
+			}
+			assertCorrectMessage(t, err.Error(), c.want)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package prefcode
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ToSExpr returns the tree of p as an S-expression: a leaf is its label and
+// a caret the list of its children in natural rune order, so the code 00:0,
+// 01:2, 1:1 over "01" is "((0 2) 1)" and the trivial code is "0".
+func (p *prefixCode) ToSExpr() string {
+	return NewCompactFrom(p).ToSExpr()
+}
+
+func (c *CompactPrefCode) ToSExpr() string {
+	arity := len(c.letters)
+	var b strings.Builder
+	var left []int // left[d] counts the children still to come at depth d+1
+	leaf := 0
+	for pos := 0; pos < c.dfs.n; pos++ {
+		if 0 < len(left) && left[len(left)-1] < arity {
+			b.WriteByte(' ')
+		}
+		if c.dfs.get(pos) {
+			b.WriteByte('(')
+			left = append(left, arity)
+			continue
+		}
+		b.WriteString(strconv.Itoa(int(c.perm[leaf])))
+		leaf++
+		// close the carets whose last child this was.
+		for 0 < len(left) {
+			left[len(left)-1]--
+			if 0 < left[len(left)-1] {
+				break
+			}
+			left = left[:len(left)-1]
+			b.WriteByte(')')
+		}
+	}
+	return b.String()
+}
+
+// FromSExpr returns the code over alphabet written as s by ToSExpr.  Any
+// white space may separate the parts, so fixtures can be laid out by hand.
+// Each list must have a child for every letter and the labels must be a
+// permutation.
+func FromSExpr(alphabet []rune, s string) (PrefCode, error) {
+	letters := MakeAlphabet(string(alphabet))
+	code := make(map[string]int)
+	var word []rune // the word of the innermost open list
+	var seen []int  // seen[d] counts the children so far of the list open at depth d
+	done := false
+	// start returns the word of the node starting now.
+	start := func() (string, error) {
+		if done {
+			return "", errors.New("S-expression " + strconv.Quote(s) + " goes on past its tree")
+		}
+		if 0 == len(seen) {
+			return "", nil
+		}
+		if seen[len(seen)-1] == len(letters) {
+			return "", errors.New("Caret " + strconv.Quote(string(word)) + " has more than " + strconv.Itoa(len(letters)) + " children")
+		}
+		seen[len(seen)-1]++
+		return string(word) + string(letters[seen[len(seen)-1]-1]), nil
+	}
+	for rest := strings.TrimLeftFunc(s, unicode.IsSpace); "" != rest; rest = strings.TrimLeftFunc(rest, unicode.IsSpace) {
+		switch rest[0] {
+		case '(':
+			w, err := start()
+			if nil != err {
+				return nil, err
+			}
+			word = []rune(w)
+			seen = append(seen, 0)
+			rest = rest[1:]
+		case ')':
+			if 0 == len(seen) {
+				return nil, errors.New("Unbalanced ) in S-expression " + strconv.Quote(s))
+			}
+			if n := seen[len(seen)-1]; n != len(letters) {
+				return nil, errors.New("Caret " + strconv.Quote(string(word)) + " has " + strconv.Itoa(n) + " children, not " + strconv.Itoa(len(letters)))
+			}
+			seen = seen[:len(seen)-1]
+			if 0 < len(word) {
+				word = word[:len(word)-1]
+			}
+			done = 0 == len(seen)
+			rest = rest[1:]
+		default:
+			end := strings.IndexFunc(rest, func(r rune) bool { return '(' == r || ')' == r || unicode.IsSpace(r) })
+			if end < 0 {
+				end = len(rest)
+			}
+			label, err := strconv.Atoi(rest[:end])
+			if nil != err {
+				return nil, errors.New("Expected a label in S-expression but found " + strconv.Quote(rest[:end]))
+			}
+			w, err := start()
+			if nil != err {
+				return nil, err
+			}
+			code[w] = label
+			done = 0 == len(seen)
+			rest = rest[end:]
+		}
+	}
+	if !done {
+		return nil, errors.New("S-expression " + strconv.Quote(s) + " ends before its tree is complete")
+	}
+	return NewPrefCodeFromMap(letters, code)
+}
@@ -0,0 +1,76 @@
+package prefcode
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSExpr(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking codes written as S-expressions.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking ToSExpr.")
+		}
+		assertCorrectMessage(t, baseCode.ToSExpr(), "0")
+		baseCode.ExpandAt("0")
+		baseCode.ExpandAt("01")
+		baseCode.SwapPermAtKeys("00", "1")
+		assertCorrectMessage(t, baseCode.ToSExpr(), "((3 (1 2)) 0)")
+		assertCorrectMessage(t, NewCompactFrom(baseCode).ToSExpr(), "((3 (1 2)) 0)")
+		assertCorrectMessage(t, NewSafePrefCode(baseCode).ToSExpr(), "((3 (1 2)) 0)")
+
+		ternary, err := NewPrefCodeAlphaString("abc")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString() in test checking ToSExpr.")
+		}
+		ternary.ExpandAt("c")
+		assertCorrectMessage(t, ternary.ToSExpr(), "(0 1 (2 3 4))")
+	})
+
+	t.Run("Checking S-expressions read back.", func(t *testing.T) {
+		pc, err := FromSExpr([]rune("01"), `
+			(
+			  (3
+			    (1 2))
+			  0)`)
+		if nil != err {
+			t.Fatalf("FromSExpr: %v", err)
+		}
+		assertCorrectMessage(t, pc.String(), "[00 3], [010 1], [011 2], [1 0]")
+
+		pc, err = FromSExpr([]rune("cba"), "(0 1 (2 3 4))")
+		if nil != err {
+			t.Fatalf("FromSExpr: %v", err)
+		}
+		assertCorrectMessage(t, pc.ToSExpr(), "(0 1 (2 3 4))")
+
+		pc, err = FromSExpr([]rune("01"), " 0 ")
+		if nil != err {
+			t.Fatalf("FromSExpr of the trivial code: %v", err)
+		}
+		assertCorrectMessage(t, pc.String(), "[𝛆 0]")
+	})
+
+	t.Run("Checking bad S-expressions are rejected.", func(t *testing.T) {
+		for _, c := range []struct{ s, want string }{
+			{"((0 1) 2", `S-expression "((0 1) 2" ends before its tree is complete`},
+			{"(0 1) 2", `S-expression "(0 1) 2" goes on past its tree`},
+			{"(0 1))", `Unbalanced ) in S-expression "(0 1))"`},
+			{"(0 (1 2 3))", `Caret "1" has more than 2 children`},
+			{"(0 (1))", `Caret "1" has 1 children, not 2`},
+			{"(0 x)", `Expected a label in S-expression but found "x"`},
+			{"(0 (1 5))", `Labels are not a permutation of 0 ... 2 at "11"`},
+		} {
+			_, err := FromSExpr([]rune("01"), c.s)
+			assertCorrectMessage(t, fmt.Sprint(err), c.want)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package prefcode
+
+import "strings"
+
+// The shape predicates below use the usual Thompson group conventions: the
+// left edge of the tree is the path from the root through the first (least)
+// letter of the alphabet, the right edge the path through the last letter.
+// For the binary alphabet "01" these are the words 0...0 and 1...1.
+
+// CaretCounts tallies the carets of a code by their position in the tree.
+// Left carets lie on the left edge (the root counts as a left caret), right
+// carets on the right edge below the root, and all others are interior.
+type CaretCounts struct {
+	Left     int
+	Right    int
+	Interior int
+}
+
+// CaretTypeCounts returns the number of left, right and interior carets.
+func (p *prefixCode) CaretTypeCounts() CaretCounts {
+	var counts CaretCounts
+	first, last := p.edgeLetters()
+	for caret := range internalNodes(p) {
+		switch {
+		case isPowerOf(caret, first):
+			counts.Left++
+		case isPowerOf(caret, last):
+			counts.Right++
+		default:
+			counts.Interior++
+		}
+	}
+	return counts
+}
+
+// IsRightVine reports whether every caret lies on the right edge of the tree.
+// The trivial code is (vacuously) both a left and a right vine.
+func (p *prefixCode) IsRightVine() bool {
+	_, last := p.edgeLetters()
+	for caret := range internalNodes(p) {
+		if !isPowerOf(caret, last) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsLeftVine reports whether every caret lies on the left edge of the tree.
+func (p *prefixCode) IsLeftVine() bool {
+	first, _ := p.edgeLetters()
+	for caret := range internalNodes(p) {
+		if !isPowerOf(caret, first) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsFullTree reports whether the tree is the full n-ary tree of some depth,
+// i.e. every leaf has the same depth.
+func (p *prefixCode) IsFullTree() bool {
+	return p.IsUniformDepth()
+}
+
+// edgeLetters returns the least and greatest letters in the order of p.
+func (p *prefixCode) edgeLetters() (first, last rune) {
+	letters := p.letters()
+	return letters[0], letters[len(letters)-1]
+}
+
+// isPowerOf reports whether word consists of the letter r only (the empty
+// word included).
+func isPowerOf(word string, r rune) bool {
+	return "" == strings.Trim(word, string(r))
+}
+
+// SameShape reports whether p and q, over alphabets of the same size, have
+// the same ordered tree, ignoring labels: whether spelling each word of p
+// with the letter of q in the same place of the letter order gives the
+// leaves of q.
+func (p *prefixCode) SameShape(q PrefCode) bool {
+	_, ok := shapeBijection(p, q)
+	return ok
+}
+
+func (c *CompactPrefCode) SameShape(q PrefCode) bool {
+	_, ok := shapeBijection(c, q)
+	return ok
+}
+
+// ShapeBijection returns the letter bijection taking the tree of p to that of
+// q, if they have the same shape.
+func (p *prefixCode) ShapeBijection(q PrefCode) (map[rune]rune, bool) {
+	return shapeBijection(p, q)
+}
+
+func (c *CompactPrefCode) ShapeBijection(q PrefCode) (map[rune]rune, bool) {
+	return shapeBijection(c, q)
+}
+
+func shapeBijection(p, q PrefCode) (map[rune]rune, bool) {
+	from, to := letterOrder(p), letterOrder(q)
+	if len(from) != len(to) || p.Size() != q.Size() {
+		return nil, false
+	}
+	mapping := make(map[rune]rune, len(from))
+	for ii, r := range from {
+		mapping[r] = to[ii]
+	}
+	qCode := q.Code()
+	for leaf := range p.Code() {
+		word := []rune(leaf)
+		if EmptyString != leaf {
+			for jj, r := range word {
+				word[jj] = mapping[r]
+			}
+		}
+		if _, ok := qCode[string(word)]; !ok {
+			return nil, false
+		}
+	}
+	return mapping, true
+}
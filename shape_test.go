@@ -0,0 +1,90 @@
+package prefcode
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestShape(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking vines.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking vines.")
+		}
+		vines := func() string {
+			return strconv.FormatBool(baseCode.IsLeftVine()) + " " + strconv.FormatBool(baseCode.IsRightVine())
+		}
+		assertCorrectMessage(t, vines(), "true true")
+		baseCode.ExpandAt("11")
+		assertCorrectMessage(t, vines(), "false true")
+		baseCode.ExpandAt("0")
+		assertCorrectMessage(t, vines(), "false false")
+		baseCode.ReduceAt("1")
+		assertCorrectMessage(t, vines(), "true false")
+	})
+
+	t.Run("Checking IsFullTree and CaretTypeCounts.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking caret types.")
+		}
+		baseCode.ExpandAt("0")
+		baseCode.ExpandAt("1")
+		assertCorrectMessage(t, strconv.FormatBool(baseCode.IsFullTree()), "true")
+		assertCorrectMessage(t, fmt.Sprintf("%+v", baseCode.CaretTypeCounts()), "{Left:2 Right:1 Interior:0}")
+
+		baseCode.ExpandAt("011")
+		assertCorrectMessage(t, strconv.FormatBool(baseCode.IsFullTree()), "false")
+		assertCorrectMessage(t, fmt.Sprintf("%+v", baseCode.CaretTypeCounts()), "{Left:2 Right:1 Interior:2}")
+	})
+
+	t.Run("Checking edges under an ordered alphabet.", func(t *testing.T) {
+		baseCode, err := NewPrefCodeOrdered([]rune("zab"))
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeOrdered() in test checking edges.")
+		}
+		baseCode.ExpandAt(EmptyString)
+		baseCode.ExpandAt("z")
+		assertCorrectMessage(t, strconv.FormatBool(baseCode.IsLeftVine()), "true")
+		assertCorrectMessage(t, strconv.FormatBool(baseCode.IsRightVine()), "false")
+		baseCode.ExpandAt("b")
+		baseCode.ExpandAt("a")
+		assertCorrectMessage(t, fmt.Sprintf("%+v", baseCode.CaretTypeCounts()), "{Left:2 Right:1 Interior:1}")
+	})
+
+	t.Run("Checking SameShape and ShapeBijection.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking SameShape.")
+		}
+		baseCode.ExpandAt("01")
+		other, _ := NewPrefCodeAlphaString("ab")
+		compact, _ := NewCompactPrefCode([]rune("ab"))
+		for _, q := range []PrefCode{other, compact} {
+			assertCorrectMessage(t, strconv.FormatBool(baseCode.SameShape(q)), "false")
+			q.ExpandAt("ab")
+			q.SwapPermAtKeys("b", "aa")
+			mapping, ok := baseCode.ShapeBijection(q)
+			assertCorrectMessage(t, strconv.FormatBool(ok), "true")
+			assertCorrectMessage(t, fmt.Sprint(mapping), "map[48:97 49:98]")
+			assertCorrectMessage(t, strconv.FormatBool(q.SameShape(baseCode)), "true")
+		}
+
+		ordered, _ := NewPrefCodeOrdered([]rune("ba"))
+		ordered.ExpandAt("ba")
+		assertCorrectMessage(t, strconv.FormatBool(baseCode.SameShape(ordered)), "true")
+		assertCorrectMessage(t, strconv.FormatBool(other.SameShape(ordered)), "true")
+
+		three, _ := NewPrefCodeAlphaString("abc")
+		assertCorrectMessage(t, strconv.FormatBool(three.SameShape(other)), "false")
+	})
+}
@@ -0,0 +1,36 @@
+package prefcode
+
+import (
+	"math/big"
+)
+
+// Slopes returns, for each domain leaf, the slope n^(|d|-|r|) with which t
+// maps the interval of the leaf d onto that of its range leaf r (see
+// LeafInterval).  For elements of F, and its n-ary analogues, these are the
+// derivatives of the induced piecewise-linear homeomorphism of [0,1].
+func (t TreePair) Slopes() map[string]*big.Rat {
+	n := big.NewInt(int64(len(t.domain.alphabet)))
+	slopes := make(map[string]*big.Rat, t.Size())
+	for leaf, label := range t.domain.Code() {
+		exp := wordLen(leaf) - wordLen(t.rng.LeafAtLabel(label))
+		power := new(big.Int).Exp(n, big.NewInt(int64(max(exp, -exp))), nil)
+		if exp < 0 {
+			slopes[leaf] = new(big.Rat).SetFrac(big.NewInt(1), power)
+		} else {
+			slopes[leaf] = new(big.Rat).SetInt(power)
+		}
+	}
+	return slopes
+}
+
+// IsOrderPreserving reports whether t keeps dictionary order, that is lies in
+// F: its range leaves come in the same order as their domain leaves.
+func (t TreePair) IsOrderPreserving() bool {
+	domain := t.domain.sortedKeys()
+	for k, leaf := range t.rng.sortedKeys() {
+		if t.rng.LabelAtLeaf(leaf) != t.domain.LabelAtLeaf(domain[k]) {
+			return false
+		}
+	}
+	return true
+}
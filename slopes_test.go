@@ -0,0 +1,48 @@
+package prefcode
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+func TestSlopes(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	x0 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "11": 2}, map[string]int{"00": 0, "01": 1, "1": 2})
+
+	t.Run("Checking Slopes.", func(t *testing.T) {
+		slopes := x0.Slopes()
+		for leaf, want := range map[string]string{"0": "1/2", "10": "1", "11": "2"} {
+			assertCorrectMessage(t, slopes[leaf].RatString(), want)
+		}
+		id, _ := IdentityPair([]rune("01"))
+		assertCorrectMessage(t, id.Slopes()[EmptyString].RatString(), "1")
+
+		// slopes multiply along a composition, by the chain rule.
+		sq, _ := x0.Compose(x0)
+		domain := x0.Domain()
+		for leaf, slope := range sq.Slopes() {
+			mid, _ := x0.Apply(leaf)
+			chain := new(big.Rat).Mul(slopes[domain.GetPrefixOf(leaf)], slopes[domain.GetPrefixOf(mid)])
+			assertCorrectMessage(t, leaf+" "+slope.RatString(), leaf+" "+chain.RatString())
+		}
+	})
+
+	t.Run("Checking IsOrderPreserving.", func(t *testing.T) {
+		assertCorrectMessage(t, strconv.FormatBool(x0.IsOrderPreserving()), "true")
+		assertCorrectMessage(t, strconv.FormatBool(x0.Inverse().IsOrderPreserving()), "true")
+		swap := pairFromMaps(t, map[string]int{"0": 0, "1": 1}, map[string]int{"0": 1, "1": 0})
+		assertCorrectMessage(t, strconv.FormatBool(swap.IsOrderPreserving()), "false")
+		// labels need not be in dictionary order, only matching.
+		relabelled := pairFromMaps(t, map[string]int{"0": 2, "10": 0, "11": 1}, map[string]int{"00": 2, "01": 0, "1": 1})
+		assertCorrectMessage(t, strconv.FormatBool(relabelled.IsOrderPreserving()), "true")
+		assertCorrectMessage(t, strconv.FormatBool(relabelled.Equals(x0)), "true")
+	})
+}
@@ -0,0 +1,115 @@
+package prefcode
+
+import "sort"
+
+// sparseIndex backs the sparse label mode of a prefixCode, in which labels
+// are any unique ints rather than exactly 0 ... n-1.  Labels then stay put:
+// ExpandAt gives the new leaves fresh labels from a counter, ReduceAt gives
+// the new leaf the least label it replaces, and no other label moves.  The
+// rope is not kept in this mode, as ranks are not labels.
+type sparseIndex struct {
+	next   int            // the next fresh label
+	leaves map[int]string // leaves[label] is the leaf carrying label
+}
+
+// UseSparseLabels puts p in sparse label mode.  The labels are kept as they
+// are.
+func (p *prefixCode) UseSparseLabels() {
+	if nil != p.sparse {
+		return
+	}
+	p.syncLabels()
+	p.sparse = &sparseIndex{}
+	p.sparse.index(p.code)
+	p.order, p.nodes, p.leaves = nil, nil, nil
+}
+
+// SparseLabels reports whether p is in sparse label mode.
+func (p *prefixCode) SparseLabels() bool {
+	return nil != p.sparse
+}
+
+// NormalizeLabels squashes the labels to 0 ... n-1, keeping their order
+// (leaves with equal labels, possible after a careless SetCode, go in
+// dictionary order), and puts p back in the usual dense mode.
+func (p *prefixCode) NormalizeLabels() {
+	p.sparse = nil
+	p.buildRope()
+	p.stale = true
+	p.syncLabels()
+	p.labelsChanged()
+	p.relabelled()
+}
+
+// index rebuilds the label to leaf index from code, and moves the counter
+// past every label in use.
+func (si *sparseIndex) index(code map[string]int) {
+	si.leaves = make(map[int]string, len(code))
+	for leaf, label := range code {
+		si.leaves[label] = leaf
+		if label >= si.next {
+			si.next = label + 1
+		}
+	}
+}
+
+// sparseExpandAt is ExpandAt in sparse label mode.
+func (p *prefixCode) sparseExpandAt(s string) bool {
+	s = p.path(s)
+	prefix, ok := p.prefixLeaf(s)
+	if !ok {
+		return false
+	}
+	leaves := p.expansionLeaves(prefix, s)
+	delete(p.sparse.leaves, p.code[prefix])
+	p.deleteLeaf(prefix)
+	for _, leaf := range leaves {
+		p.setLeaf(leaf, p.sparse.next)
+		p.sparse.leaves[p.sparse.next] = leaf
+		p.sparse.next++
+	}
+	p.caretExpanded(prefix, s)
+	p.leafExpanded(prefix, leaves)
+	return true
+}
+
+// sparseReduceAt is ReduceAt in sparse label mode.
+func (p *prefixCode) sparseReduceAt(s string) bool {
+	word := p.wordAt(p.path(s))
+	node := p.findNode(word)
+	if nil == node {
+		return false
+	}
+	p.treeChanged()
+	below := p.leavesBelow(node, word)
+	least := p.code[below[0]]
+	for _, leaf := range below {
+		if p.code[leaf] < least {
+			least = p.code[leaf]
+		}
+		delete(p.sparse.leaves, p.code[leaf])
+		delete(p.code, leaf)
+	}
+	node.children = nil
+	node.leaf = true
+	p.caretReduced(word, below)
+	p.leavesReduced(word, below)
+	p.code[word] = least
+	p.sparse.leaves[least] = word
+	p.reduced(word, below)
+	return true
+}
+
+// sparseLabelsToLeaves lists the leaves in increasing order of label.
+func (p *prefixCode) sparseLabelsToLeaves() []string {
+	labels := make([]int, 0, len(p.sparse.leaves))
+	for label := range p.sparse.leaves {
+		labels = append(labels, label)
+	}
+	sort.Ints(labels)
+	leaves := make([]string, len(labels))
+	for ii, label := range labels {
+		leaves[ii] = p.sparse.leaves[label]
+	}
+	return leaves
+}
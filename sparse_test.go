@@ -0,0 +1,104 @@
+package prefcode
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSparse(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking labels stay put in sparse mode.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking sparse labels.")
+		}
+		baseCode.UseSparseLabels()
+		assertCorrectMessage(t, strconv.FormatBool(baseCode.SparseLabels()), "true")
+		baseCode.ExpandAt(EmptyString)
+		assertCorrectMessage(t, baseCode.String(), "[0 1], [1 2]")
+		baseCode.ExpandAt("10")
+		assertCorrectMessage(t, baseCode.String(), "[0 1], [100 3], [101 4], [11 5]")
+		assertCorrectMessage(t, baseCode.LeafAtLabel(3), "100")
+		assertCorrectMessage(t, baseCode.LeafAtLabel(2), "")
+		assertCorrectMessage(t, strconv.Itoa(baseCode.LabelAtLeaf("11")), "5")
+		assertCorrectMessage(t, strings.Join(baseCode.LabelsToLeaves(), " "), "0 100 101 11")
+
+		baseCode.SwapPermAtKeys("0", "101")
+		assertCorrectMessage(t, baseCode.LeafAtLabel(1), "101")
+		baseCode.ReduceAt("10")
+		assertCorrectMessage(t, baseCode.String(), "[0 4], [10 1], [11 5]")
+		baseCode.ApplyPerm(map[int]int{1: 10, 4: 40, 5: 50})
+		assertCorrectMessage(t, baseCode.String(), "[0 40], [10 10], [11 50]")
+		baseCode.ExpandAt("0")
+		assertCorrectMessage(t, baseCode.String(), "[00 51], [01 52], [10 10], [11 50]")
+
+		baseCode.NormalizeLabels()
+		assertCorrectMessage(t, strconv.FormatBool(baseCode.SparseLabels()), "false")
+		assertCorrectMessage(t, baseCode.String(), "[00 2], [01 3], [10 0], [11 1]")
+		baseCode.ExpandAt("10")
+		assertCorrectMessage(t, baseCode.String(), "[00 3], [01 4], [100 0], [101 1], [11 2]")
+
+		baseCode.UseSparseLabels()
+		baseCode.ReduceAt(EmptyString)
+		assertCorrectMessage(t, baseCode.String(), "[𝛆 0]")
+	})
+
+	t.Run("Checking SetCode with arbitrary labels.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking SetCode with sparse labels.")
+		}
+		baseCode.UseSparseLabels()
+		baseCode.SetCode(map[string]int{"0": -7, "1": 100})
+		baseCode.ExpandAt("1")
+		assertCorrectMessage(t, baseCode.String(), "[0 -7], [10 101], [11 102]")
+		clone := baseCode.clone()
+		clone.ExpandAt("0")
+		assertCorrectMessage(t, clone.String(), "[00 103], [01 104], [10 101], [11 102]")
+	})
+
+	// the leaves in sparse mode, ordered by label, must match dense mode.
+	t.Run("Checking sparse and dense modes agree on order.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(19))
+		dense, _ := NewPrefCodeAlphaString("abc")
+		sparse, _ := NewPrefCodeAlphaString("abc")
+		sparse.UseSparseLabels()
+		for step := 0; step < 200; step++ {
+			leaves := dense.sortedKeys()
+			leaf := leaves[rng.Intn(len(leaves))]
+			if EmptyString == leaf || 0 < rng.Intn(3) {
+				if EmptyString == leaf {
+					leaf = ""
+				}
+				word := leaf + "abc"[rng.Intn(3):][:1]
+				dense.ExpandAt(word)
+				sparse.ExpandAt(word)
+			} else {
+				dense.ReduceAt(trimLastChar(leaf))
+				sparse.ReduceAt(trimLastChar(leaf))
+			}
+			got := sparse.Code()
+			keys := make([]string, 0, len(got))
+			for k := range got {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			assertCorrectMessage(t, strings.Join(keys, " "), strings.Join(dense.sortedKeys(), " "))
+		}
+		sparse.NormalizeLabels()
+		assertCorrectMessage(t, strconv.Itoa(sparse.Size()), strconv.Itoa(dense.Size()))
+		for label := 0; label < sparse.Size(); label++ {
+			assertCorrectMessage(t, strconv.Itoa(sparse.LabelAtLeaf(sparse.LeafAtLabel(label))), strconv.Itoa(label))
+		}
+	})
+}
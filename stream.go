@@ -0,0 +1,47 @@
+package prefcode
+
+import "context"
+
+// LeafEntry is a leaf of a code with its label.
+type LeafEntry struct {
+	Leaf  string
+	Label int
+}
+
+// streamBuffer is how many entries a stream may run ahead of its reader.
+const streamBuffer = 64
+
+// StreamLeaves sends the leaves of p with their labels, in dictionary order,
+// on the channel it returns, closing it after the last or once ctx is done.
+// The leaves are read off the tree as they are sent, so even a huge code is
+// never copied or sorted.  p must not change until the channel is closed; a
+// reader stopping early should cancel ctx, or the sender is left blocked.
+func (p *prefixCode) StreamLeaves(ctx context.Context) <-chan LeafEntry {
+	p.syncLabels()
+	return streamLeaves(ctx, p.WalkDFS)
+}
+
+// StreamLeaves walks the DFS sequence of c, as StreamLeaves of PrefCode.
+func (c *CompactPrefCode) StreamLeaves(ctx context.Context) <-chan LeafEntry {
+	return streamLeaves(ctx, c.WalkDFS)
+}
+
+// streamLeaves sends the leaves met by walk until it ends or ctx is done.
+func streamLeaves(ctx context.Context, walk func(fn func(node string, isLeaf bool, label int) error) error) <-chan LeafEntry {
+	ch := make(chan LeafEntry, streamBuffer)
+	go func() {
+		defer close(ch)
+		walk(func(node string, isLeaf bool, label int) error {
+			if !isLeaf {
+				return nil
+			}
+			select {
+			case ch <- LeafEntry{Leaf: node, Label: label}:
+				return nil
+			case <-ctx.Done():
+				return ErrStopWalk
+			}
+		})
+	}()
+	return ch
+}
@@ -0,0 +1,52 @@
+package prefcode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStreamLeaves(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking StreamLeaves sends the leaves in order.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		wrapped, _ := NewPrefCode()
+		for _, code := range []PrefCode{pc, compact, NewSafePrefCode(wrapped)} {
+			code.ExpandAt("10")
+			code.SwapPermAtKeys("0", "11")
+			var got []string
+			for e := range code.StreamLeaves(context.Background()) {
+				got = append(got, fmt.Sprint(e.Leaf, " ", e.Label))
+			}
+			assertCorrectMessage(t, strings.Join(got, ", "), "0 3, 100 1, 101 2, 11 0")
+		}
+	})
+
+	t.Run("Checking StreamLeaves stops when cancelled.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		pc.ExpandToDepth(10)
+		safe := NewSafePrefCode(pc)
+		for _, code := range []PrefCode{pc, safe} {
+			ctx, cancel := context.WithCancel(context.Background())
+			stream := code.StreamLeaves(ctx)
+			first := <-stream
+			cancel()
+			read := 1
+			for range stream {
+				read++
+			}
+			assertCorrectMessage(t, first.Leaf, "0000000000")
+			assertCorrectMessage(t, fmt.Sprint(read < code.Size()), "true")
+		}
+		assertCorrectMessage(t, fmt.Sprint(safe.ReduceAt("")), "true")
+	})
+}
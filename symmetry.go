@@ -0,0 +1,129 @@
+package prefcode
+
+import "iter"
+
+// The permutations of the letters act on codes: sigma, a permutation of the
+// places 0 ... n-1 of the letter order, spells each word with letter ii
+// replaced by letter sigma(ii), the leaves keeping their labels.  Codes in
+// one orbit have the same tree up to reordering the children of each caret.
+
+// AlphabetOrbit yields the codes in the orbit of p under the permutations of
+// its letters, each once, with the first permutation (in lexicographic order
+// of the images 0 ... n-1) taking p to it.  There are up to n! of them.
+func (p *prefixCode) AlphabetOrbit() iter.Seq2[Perm, PrefCode] {
+	return alphabetOrbit(p, p.IsOrdered())
+}
+
+func (c *CompactPrefCode) AlphabetOrbit() iter.Seq2[Perm, PrefCode] {
+	return alphabetOrbit(c, false)
+}
+
+// CanonicalizeUnderAlphabetSymmetry returns the least code in the orbit of p,
+// see AlphabetOrbit, with a permutation of the letters taking p to it.
+// Codes are compared by their leaves in dictionary order, then by the labels
+// of those leaves, so two codes are in one orbit exactly when their canonical
+// forms are Equal.
+func (p *prefixCode) CanonicalizeUnderAlphabetSymmetry() (PrefCode, Perm) {
+	return canonicalizeUnderAlphabetSymmetry(p, p.IsOrdered())
+}
+
+func (c *CompactPrefCode) CanonicalizeUnderAlphabetSymmetry() (PrefCode, Perm) {
+	return canonicalizeUnderAlphabetSymmetry(c, false)
+}
+
+func alphabetOrbit(pc PrefCode, ordered bool) iter.Seq2[Perm, PrefCode] {
+	return func(yield func(Perm, PrefCode) bool) {
+		letters := letterOrder(pc)
+		seen := make(map[string]bool)
+		permutations(len(letters), func(sigma Perm) bool {
+			mapping := make(map[rune]rune, len(letters))
+			for ii, r := range letters {
+				mapping[r] = letters[sigma[ii]]
+			}
+			// every image is over the letters in the order of pc, so
+			// codeLess compares them alike.
+			var image *prefixCode
+			var err error
+			if ordered {
+				image, err = NewPrefCodeOrdered(letters)
+			} else {
+				image, err = NewPrefCodeAlphaRunes(letters)
+			}
+			if nil != err {
+				return false
+			}
+			respell(image, pc, mapping)
+			if seen[image.String()] {
+				return true
+			}
+			seen[image.String()] = true
+			return yield(sigma, image)
+		})
+	}
+}
+
+func canonicalizeUnderAlphabetSymmetry(pc PrefCode, ordered bool) (PrefCode, Perm) {
+	var least *prefixCode
+	var perm Perm
+	for sigma, image := range alphabetOrbit(pc, ordered) {
+		if c := image.(*prefixCode); nil == least || codeLess(c, least) {
+			least, perm = c, sigma
+		}
+	}
+	return least, perm
+}
+
+// codeLess reports whether a comes before b, two codes over the same letters
+// in the same order: the first leaf, in dictionary order, at which they
+// differ decides, and failing that the first label.
+func codeLess(a, b *prefixCode) bool {
+	ka, kb := a.sortedKeys(), b.sortedKeys()
+	for ii := 0; ii < len(ka) && ii < len(kb); ii++ {
+		if ka[ii] != kb[ii] {
+			return a.wordLess(ka[ii], kb[ii])
+		}
+	}
+	if len(ka) != len(kb) {
+		return len(ka) < len(kb)
+	}
+	la, lb := a.sortedLabels(), b.sortedLabels()
+	for ii := range la {
+		if la[ii] != lb[ii] {
+			return la[ii] < lb[ii]
+		}
+	}
+	return false
+}
+
+// permutations calls visit on each permutation of 0 ... n-1, in
+// lexicographic order of their images, until visit returns false.  The Perm
+// is fresh on each call.
+func permutations(n int, visit func(Perm) bool) {
+	images := make([]int, 0, n)
+	used := make([]bool, n)
+	var extend func() bool
+	extend = func() bool {
+		if len(images) == n {
+			sigma := make(Perm, n)
+			for ii, v := range images {
+				sigma[ii] = v
+			}
+			return visit(sigma)
+		}
+		for v := 0; v < n; v++ {
+			if used[v] {
+				continue
+			}
+			used[v] = true
+			images = append(images, v)
+			ok := extend()
+			images = images[:len(images)-1]
+			used[v] = false
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+	extend()
+}
@@ -0,0 +1,74 @@
+package prefcode
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSymmetry(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking AlphabetOrbit.", func(t *testing.T) {
+		pc, err := NewPrefCodeAlphaString("012")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString() in test checking AlphabetOrbit.")
+		}
+		count := 0
+		for range pc.AlphabetOrbit() {
+			count++
+		}
+		assertCorrectMessage(t, strconv.Itoa(count), "1")
+
+		pc.ExpandAt("")
+		count = 0
+		for sigma, image := range pc.AlphabetOrbit() {
+			if 1 == count {
+				assertCorrectMessage(t, sigma.String(), "(1 2)")
+				assertCorrectMessage(t, image.String(), "[0 0], [1 2], [2 1]")
+			}
+			count++
+		}
+		assertCorrectMessage(t, strconv.Itoa(count), "6")
+	})
+
+	t.Run("Checking CanonicalizeUnderAlphabetSymmetry.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		for _, code := range []PrefCode{pc, compact} {
+			code.ExpandAt("0")
+			canonical, sigma := code.CanonicalizeUnderAlphabetSymmetry()
+			assertCorrectMessage(t, canonical.String(), "[0 2], [10 1], [11 0]")
+			assertCorrectMessage(t, sigma.String(), "(0 1)")
+
+			other, _ := NewPrefCode()
+			other.ExpandAt("1")
+			other.ApplyPerm(Perm{0: 2, 1: 1, 2: 0})
+			least, _ := other.CanonicalizeUnderAlphabetSymmetry()
+			assertCorrectMessage(t, strconv.FormatBool(least.Equals(canonical)), "true")
+		}
+	})
+
+	t.Run("Checking canonical forms of ordered codes.", func(t *testing.T) {
+		ordered, err := NewPrefCodeOrdered([]rune("zab"))
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeOrdered() in test checking canonical forms.")
+		}
+		ordered.ExpandAt("z")
+		ordered.ExpandAt("za")
+		canonical, _ := ordered.CanonicalizeUnderAlphabetSymmetry()
+		members := 0
+		for _, image := range ordered.AlphabetOrbit() {
+			members++
+			assertCorrectMessage(t, string(image.Alphabet()), "zab")
+			least, _ := image.CanonicalizeUnderAlphabetSymmetry()
+			assertCorrectMessage(t, strconv.FormatBool(least.Equals(canonical)), "true")
+		}
+		assertCorrectMessage(t, strconv.Itoa(members), "6")
+	})
+}
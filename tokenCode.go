@@ -0,0 +1,160 @@
+package prefcode
+
+import (
+	"io"
+	"strings"
+)
+
+// TokenCode is a code over a TokenAlphabet read and written in its tokens:
+// every word passed in or handed back is a string of tokens, so callers never
+// see the letters standing for them.  A word which is not a string of tokens
+// is treated as a word over foreign letters.  Runes gives the underlying code
+// for the rest of the package.
+type TokenCode struct {
+	ta *TokenAlphabet
+	pc *prefixCode
+}
+
+// NewTokenCode returns the trivial code over the tokens of ta.
+func (ta *TokenAlphabet) NewTokenCode() (*TokenCode, error) {
+	pc, err := ta.NewPrefCode()
+	if nil != err {
+		return nil, err
+	}
+	return &TokenCode{ta: ta, pc: pc}, nil
+}
+
+// Alphabet returns the token alphabet of tc.
+func (tc *TokenCode) Alphabet() *TokenAlphabet {
+	return tc.ta
+}
+
+// Runes returns the code under tc, over the letters of the tokens.  It is
+// shared, not copied, so changes to it show in tc.
+func (tc *TokenCode) Runes() PrefCode {
+	return tc.pc
+}
+
+// encode returns the word of the code spelt by the tokens s.
+func (tc *TokenCode) encode(s string) (string, bool) {
+	w, err := tc.ta.Encode(s)
+	return w, nil == err
+}
+
+// spell returns the tokens spelling w, a word of the code, so over ta.
+func (tc *TokenCode) spell(w string) string {
+	s, _ := tc.ta.Decode(w)
+	return s
+}
+
+func (tc *TokenCode) spellAll(words []string) []string {
+	for ii, w := range words {
+		words[ii] = tc.spell(w)
+	}
+	return words
+}
+
+func (tc *TokenCode) Size() int {
+	return tc.pc.Size()
+}
+
+func (tc *TokenCode) ExpandAt(s string) bool {
+	w, ok := tc.encode(s)
+	return ok && tc.pc.ExpandAt(w)
+}
+
+func (tc *TokenCode) ReduceAt(s string) bool {
+	w, ok := tc.encode(s)
+	return ok && tc.pc.ReduceAt(w)
+}
+
+func (tc *TokenCode) IsLeaf(word string) bool {
+	w, ok := tc.encode(word)
+	return ok && tc.pc.IsLeaf(w)
+}
+
+func (tc *TokenCode) IsInternal(word string) bool {
+	w, ok := tc.encode(word)
+	return ok && tc.pc.IsInternal(w)
+}
+
+func (tc *TokenCode) IsBelowCode(word string) bool {
+	w, ok := tc.encode(word)
+	return ok && tc.pc.IsBelowCode(w)
+}
+
+func (tc *TokenCode) LabelAtLeaf(leaf string) int {
+	w, ok := tc.encode(leaf)
+	if !ok {
+		return FAILURE
+	}
+	return tc.pc.LabelAtLeaf(w)
+}
+
+func (tc *TokenCode) LeafAtLabel(label int) string {
+	return tc.spell(tc.pc.LeafAtLabel(label))
+}
+
+// GetPrefixOf returns the leaf which is a prefix of s, or "" if there is none.
+func (tc *TokenCode) GetPrefixOf(s string) string {
+	w, ok := tc.encode(s)
+	if !ok {
+		return ""
+	}
+	return tc.spell(tc.pc.GetPrefixOf(w))
+}
+
+func (tc *TokenCode) ExposedCarets() []string {
+	return tc.spellAll(tc.pc.ExposedCarets())
+}
+
+// SortedLeaves returns the leaves in dictionary order, the tokens ordered as
+// ta lists them.
+func (tc *TokenCode) SortedLeaves() []string {
+	return tc.spellAll(tc.pc.SortedLeaves())
+}
+
+func (tc *TokenCode) LabelsToLeaves() []string {
+	return tc.spellAll(tc.pc.LabelsToLeaves())
+}
+
+func (tc *TokenCode) Code() map[string]int {
+	code := make(map[string]int, tc.pc.Size())
+	for leaf, ii := range tc.pc.Code() {
+		code[tc.spell(leaf)] = ii
+	}
+	return code
+}
+
+func (tc *TokenCode) Permutation() Perm {
+	return tc.pc.Permutation()
+}
+
+func (tc *TokenCode) ApplyPerm(perm Perm) bool {
+	return tc.pc.ApplyPerm(perm)
+}
+
+func (tc *TokenCode) SwapPermAtKeys(a, b string) error {
+	wa, _ := tc.encode(a)
+	wb, _ := tc.encode(b)
+	return tc.pc.SwapPermAtKeys(wa, wb)
+}
+
+// String lists the leaves, in tokens, with their labels as prefixCode does.
+func (tc *TokenCode) String() string {
+	var b strings.Builder
+	tc.WriteTo(&b)
+	return b.String()
+}
+
+func (tc *TokenCode) WriteTo(w io.Writer) (int64, error) {
+	out := entryWriter{w: w}
+	for _, e := range tc.pc.Entries() {
+		out.entry(tc.spell(e.Leaf), e.Label)
+	}
+	return out.flush()
+}
+
+func (tc *TokenCode) Equals(q *TokenCode) bool {
+	return tc.String() == q.String()
+}
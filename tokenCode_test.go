@@ -0,0 +1,51 @@
+package prefcode
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTokenCode(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking a code read and written in tokens.", func(t *testing.T) {
+		ta, _ := NewTokenAlphabet([]string{"red", "green", "blue"})
+		tc, err := ta.NewTokenCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewTokenCode() in test checking TokenCode.")
+		}
+		assertCorrectMessage(t, tc.String(), "[𝛆 0]")
+		assertCorrectMessage(t, strconv.FormatBool(tc.ExpandAt("green")), "true")
+		assertCorrectMessage(t, tc.String(), "[red 0], [greenred 1], [greengreen 2], [greenblue 3], [blue 4]")
+		assertCorrectMessage(t, strings.Join(tc.SortedLeaves(), " "), "red greenred greengreen greenblue blue")
+		assertCorrectMessage(t, strings.Join(tc.ExposedCarets(), " "), "green")
+
+		assertCorrectMessage(t, strconv.FormatBool(tc.IsLeaf("greenblue")), "true")
+		assertCorrectMessage(t, strconv.FormatBool(tc.IsInternal("green")), "true")
+		assertCorrectMessage(t, strconv.FormatBool(tc.IsBelowCode("bluered")), "true")
+		assertCorrectMessage(t, strconv.FormatBool(tc.IsLeaf("gre")), "false")
+		assertCorrectMessage(t, strconv.Itoa(tc.LabelAtLeaf("greengreen")), "2")
+		assertCorrectMessage(t, strconv.Itoa(tc.LabelAtLeaf("purple")), "-1")
+		assertCorrectMessage(t, tc.LeafAtLabel(3), "greenblue")
+		assertCorrectMessage(t, tc.GetPrefixOf("greenredblue"), "greenred")
+		assertCorrectMessage(t, strconv.Itoa(tc.Code()["blue"]), "4")
+
+		if err := tc.SwapPermAtKeys("red", "blue"); nil != err {
+			assertCorrectMessage(t, "Faied to ", "SwapPermAtKeys() in test checking TokenCode.")
+		}
+		assertCorrectMessage(t, strings.Join(tc.LabelsToLeaves(), " "), "blue greenred greengreen greenblue red")
+		assertCorrectMessage(t, strconv.FormatBool(tc.ReduceAt("green")), "true")
+		assertCorrectMessage(t, tc.String(), "[red 2], [green 1], [blue 0]")
+		assertCorrectMessage(t, strconv.FormatBool(tc.ExpandAt("purple")), "false")
+
+		// the code underneath is over the letters of the tokens.
+		assertCorrectMessage(t, strconv.Itoa(tc.Runes().NumCarets()), "1")
+	})
+}
@@ -0,0 +1,154 @@
+package prefcode
+
+import (
+	"errors"
+	"strings"
+)
+
+// tokenBase is the first rune standing for a token, the start of the Private
+// Use Area, so the letters never collide with text or EmptyString.
+const tokenBase = '\uE000'
+
+// maxTokens is the size of the Private Use Area of the Basic Multilingual
+// Plane.
+const maxTokens = 6400
+
+// TokenAlphabet spells the letters of a code as string tokens, say
+// {"00", "01", "10", "11"} or named symbols.  Codes stay over runes: token ii
+// is the letter tokenBase+ii, so everything in the package works unchanged on
+// Encoded words, and Decode spells the results back.  A TokenCode does both,
+// taking and giving words in tokens only.  The tokens must be
+// prefix-free, so that a string splits into tokens in at most one way and a
+// word is a prefix of another exactly when its tokens are.
+//
+// A grapheme alphabet, see graphemes.go, cuts strings into clusters instead.
+type TokenAlphabet struct {
+	tokens    []string
+	index     map[string]int
+	graphemes bool // tokens are grapheme clusters, not prefix-free
+}
+
+// NewTokenAlphabet returns the alphabet of tokens, in the order given.
+func NewTokenAlphabet(tokens []string) (*TokenAlphabet, error) {
+	if len(tokens) < 1 {
+		return nil, errors.New("Empty Alphabet forbidden")
+	}
+	if len(tokens) > maxTokens {
+		return nil, errors.New("Too many tokens in alphabet")
+	}
+	ta := &TokenAlphabet{tokens: make([]string, len(tokens)), index: make(map[string]int, len(tokens))}
+	copy(ta.tokens, tokens)
+	for ii, tok := range ta.tokens {
+		if "" == tok {
+			return nil, errors.New("Empty token in alphabet")
+		}
+		if _, ok := ta.index[tok]; ok {
+			return nil, errors.New("Repeated token " + tok + " in alphabet")
+		}
+		ta.index[tok] = ii
+	}
+	for _, a := range ta.tokens {
+		for _, b := range ta.tokens {
+			if a != b && strings.HasPrefix(b, a) {
+				return nil, errors.New("Token " + a + " is a prefix of token " + b)
+			}
+		}
+	}
+	return ta, nil
+}
+
+// Tokens returns a copy of the tokens.
+func (ta *TokenAlphabet) Tokens() []string {
+	tokens := make([]string, len(ta.tokens))
+	copy(tokens, ta.tokens)
+	return tokens
+}
+
+// Runes returns the letters standing for the tokens, in the same order.
+func (ta *TokenAlphabet) Runes() []rune {
+	alpha := make([]rune, len(ta.tokens))
+	for ii := range alpha {
+		alpha[ii] = tokenBase + rune(ii)
+	}
+	return alpha
+}
+
+// NewPrefCode returns the trivial code over the letters of ta.
+func (ta *TokenAlphabet) NewPrefCode() (*prefixCode, error) {
+	return NewPrefCodeOrdered(ta.Runes())
+}
+
+// Split returns the tokens spelling s, or an error if s is not a string of
+// tokens.  EmptyString splits into no tokens.
+func (ta *TokenAlphabet) Split(s string) ([]string, error) {
+	var tokens []string
+	if EmptyString == s {
+		return tokens, nil
+	}
+	if ta.graphemes {
+		return ta.splitGraphemes(s)
+	}
+	for rest := s; "" != rest; {
+		found := false
+		for _, tok := range ta.tokens {
+			if strings.HasPrefix(rest, tok) {
+				tokens = append(tokens, tok)
+				rest = rest[len(tok):]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("No token at the start of " + rest)
+		}
+	}
+	return tokens, nil
+}
+
+// Encode returns the word of the code spelt by the string of tokens s.
+// EmptyString and "" are returned as they are.
+func (ta *TokenAlphabet) Encode(s string) (string, error) {
+	tokens, err := ta.Split(s)
+	if nil != err {
+		return "", err
+	}
+	if 0 == len(tokens) {
+		return s, nil
+	}
+	var b strings.Builder
+	for _, tok := range tokens {
+		b.WriteRune(tokenBase + rune(ta.index[tok]))
+	}
+	return b.String(), nil
+}
+
+// Decode returns the string of tokens spelling the word w of a code over
+// ta.  EmptyString and "" are returned as they are.
+func (ta *TokenAlphabet) Decode(w string) (string, error) {
+	if EmptyString == w {
+		return w, nil
+	}
+	var b strings.Builder
+	for _, r := range w {
+		ii := int(r - tokenBase)
+		if ii < 0 || ii >= len(ta.tokens) {
+			return "", errors.New("Letter " + string(r) + " is not a token letter")
+		}
+		b.WriteString(ta.tokens[ii])
+	}
+	return b.String(), nil
+}
+
+// Code returns the code of pc, which is over ta, with the leaves spelt in
+// tokens.
+func (ta *TokenAlphabet) Code(pc PrefCode) (map[string]int, error) {
+	code := make(map[string]int, pc.Size())
+	for leaf, ii := range pc.Code() {
+		s, err := ta.Decode(leaf)
+		if nil != err {
+			return nil, err
+		}
+		code[s] = ii
+	}
+	return code, nil
+}
@@ -0,0 +1,68 @@
+package prefcode
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTokens(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking NewTokenAlphabet.", func(t *testing.T) {
+		_, err := NewTokenAlphabet(nil)
+		assertCorrectMessage(t, err.Error(), "Empty Alphabet forbidden")
+		_, err = NewTokenAlphabet([]string{"a", ""})
+		assertCorrectMessage(t, err.Error(), "Empty token in alphabet")
+		_, err = NewTokenAlphabet([]string{"red", "red"})
+		assertCorrectMessage(t, err.Error(), "Repeated token red in alphabet")
+		_, err = NewTokenAlphabet([]string{"1", "10"})
+		assertCorrectMessage(t, err.Error(), "Token 1 is a prefix of token 10")
+	})
+
+	t.Run("Checking Split, Encode and Decode.", func(t *testing.T) {
+		ta, err := NewTokenAlphabet([]string{"red", "green", "blue"})
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewTokenAlphabet() in test checking Split.")
+		}
+		tokens, _ := ta.Split("blueredred")
+		assertCorrectMessage(t, strings.Join(tokens, ","), "blue,red,red")
+		_, err = ta.Split("bluere")
+		assertCorrectMessage(t, err.Error(), "No token at the start of re")
+
+		w, _ := ta.Encode("bluered")
+		assertCorrectMessage(t, strconv.Itoa(len([]rune(w))), "2")
+		s, _ := ta.Decode(w)
+		assertCorrectMessage(t, s, "bluered")
+		_, err = ta.Decode("x")
+		assertCorrectMessage(t, err.Error(), "Letter x is not a token letter")
+		s, _ = ta.Decode(EmptyString)
+		assertCorrectMessage(t, s, EmptyString)
+	})
+
+	t.Run("Checking codes over tokens.", func(t *testing.T) {
+		ta, _ := NewTokenAlphabet([]string{"11", "10", "01", "00"})
+		pc, err := ta.NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking codes over tokens.")
+		}
+		w, _ := ta.Encode("01")
+		pc.ExpandAt(w)
+		code, _ := ta.Code(pc)
+		leaves := make([]string, len(code))
+		for leaf, ii := range code {
+			leaves[ii] = leaf
+		}
+		assertCorrectMessage(t, strings.Join(leaves, ","), "11,10,0111,0110,0101,0100,00")
+
+		w, _ = ta.Encode("011011")
+		s, _ := ta.Decode(pc.GetPrefixOf(w))
+		assertCorrectMessage(t, s, "0110")
+	})
+}
@@ -0,0 +1,52 @@
+package prefcode
+
+// Tx is the code as seen inside Transact: the edits made through it are
+// kept only if the transaction succeeds.
+type Tx interface {
+	ExpandAt(s string) bool
+	ReduceAt(s string) bool
+	ApplyPerm(perm Perm) bool
+	SwapPermAtKeys(a, b string) error
+	LabelAtLeaf(leaf string) int
+	LeafAtLabel(label int) string
+	String() string
+}
+
+// Transact runs fn on p and keeps its edits if fn returns nil.  If fn returns
+// an error, or panics, p is put back as it was first, and the error returned
+// or the panic carried on, so a multi-step edit never leaves p half done.
+//
+// The hooks of p are called only if the transaction succeeds, once fn has
+// returned, so they see the code as fn left it.
+func (p *prefixCode) Transact(fn func(tx Tx) error) error {
+	observers := p.hooks
+	var pending []func()
+	p.hooks = observers.deferTo(&pending)
+	defer func() { p.hooks = observers }()
+	if err := transact(p, fn, snapshot(p)); nil != err {
+		return err
+	}
+	p.hooks = observers
+	for _, call := range pending {
+		call()
+	}
+	return nil
+}
+
+func (c *CompactPrefCode) Transact(fn func(tx Tx) error) error {
+	return transact(c, fn, snapshot(c))
+}
+
+func transact(tx Tx, fn func(tx Tx) error, rollback func()) (err error) {
+	done := false
+	defer func() {
+		if !done {
+			rollback()
+		}
+	}()
+	if err = fn(tx); nil != err {
+		return err
+	}
+	done = true
+	return nil
+}
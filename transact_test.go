@@ -0,0 +1,58 @@
+package prefcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestTransact(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking Transact commits and rolls back.", func(t *testing.T) {
+		pc, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking Transact.")
+		}
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		for _, code := range []PrefCode{pc, compact} {
+			code.ExpandAt("1")
+			err = code.Transact(func(tx Tx) error {
+				tx.ExpandAt("0")
+				tx.ApplyPerm(Perm{0: 3, 1: 2, 2: 1, 3: 0})
+				return nil
+			})
+			assertCorrectMessage(t, fmt.Sprint(err), "<nil>")
+			assertCorrectMessage(t, code.String(), "[00 3], [01 2], [10 1], [11 0]")
+
+			err = code.Transact(func(tx Tx) error {
+				tx.ReduceAt("0")
+				if nil != tx.SwapPermAtKeys("0", "111") {
+					return errors.New("No leaf 111")
+				}
+				return nil
+			})
+			assertCorrectMessage(t, fmt.Sprint(err), "No leaf 111")
+			assertCorrectMessage(t, code.String(), "[00 3], [01 2], [10 1], [11 0]")
+
+			func() {
+				defer func() {
+					assertCorrectMessage(t, fmt.Sprint(recover()), "boom")
+				}()
+				code.Transact(func(tx Tx) error {
+					tx.ReduceAt("")
+					panic("boom")
+				})
+			}()
+			assertCorrectMessage(t, code.String(), "[00 3], [01 2], [10 1], [11 0]")
+			code.ExpandAt("11")
+			assertCorrectMessage(t, code.String(), "[00 4], [01 3], [10 2], [110 0], [111 1]")
+		}
+	})
+}
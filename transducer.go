@@ -0,0 +1,70 @@
+package prefcode
+
+import (
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// Transducer returns a function wrapping a stream of letters so that reading
+// from the wrapper gives the image of the stream under t: the domain leaf at
+// the head of the stream is replaced by its range leaf and the tail passed
+// through untouched.  Only as much of the stream is read as needed, so
+// endless streams (points of Cantor space) are fine.  A stream ending before
+// it reaches a domain leaf gives io.ErrUnexpectedEOF.
+func (t TreePair) Transducer() func(io.RuneReader) io.RuneReader {
+	return func(in io.RuneReader) io.RuneReader {
+		return &transducer{pair: t, in: in}
+	}
+}
+
+type transducer struct {
+	pair    TreePair
+	in      io.RuneReader
+	head    []rune // what is left of the range leaf to emit
+	started bool
+	err     error
+}
+
+func (x *transducer) ReadRune() (r rune, size int, err error) {
+	if !x.started {
+		x.started = true
+		x.err = x.readHead()
+	}
+	if nil != x.err {
+		return 0, 0, x.err
+	}
+	if 0 < len(x.head) {
+		r, x.head = x.head[0], x.head[1:]
+		return r, utf8.RuneLen(r), nil
+	}
+	return x.in.ReadRune()
+}
+
+// readHead reads the domain leaf at the head of the stream, walking the
+// domain trie, and queues its range leaf.
+func (x *transducer) readHead() error {
+	var leaf []rune
+	for node := x.pair.domain.trie; !node.leaf; {
+		r, _, err := x.in.ReadRune()
+		if io.EOF == err {
+			return io.ErrUnexpectedEOF
+		}
+		if nil != err {
+			return err
+		}
+		leaf = append(leaf, r)
+		if node = node.children[r]; nil == node {
+			return errors.New("Letter " + string(r) + " is not in the alphabet")
+		}
+	}
+	word := string(leaf)
+	if 0 == len(leaf) {
+		word = EmptyString
+	}
+	image := x.pair.rng.LeafAtLabel(x.pair.domain.LabelAtLeaf(word))
+	if EmptyString != image {
+		x.head = []rune(image)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package prefcode
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// repeat is an endless stream of copies of word.
+type repeat struct {
+	word []rune
+	at   int
+}
+
+func (r *repeat) ReadRune() (rune, int, error) {
+	c := r.word[r.at%len(r.word)]
+	r.at++
+	return c, 1, nil
+}
+
+func TestTransducer(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	readAll := func(in io.RuneReader, n int) (string, error) {
+		var sb strings.Builder
+		for ii := 0; ii < n; ii++ {
+			r, _, err := in.ReadRune()
+			if nil != err {
+				return sb.String(), err
+			}
+			sb.WriteRune(r)
+		}
+		return sb.String(), nil
+	}
+
+	x0 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "11": 2}, map[string]int{"00": 0, "01": 1, "1": 2})
+
+	t.Run("Checking finite streams agree with Apply.", func(t *testing.T) {
+		apply := x0.Transducer()
+		for _, w := range []string{"0", "0110", "10", "101", "1111"} {
+			got, err := readAll(apply(strings.NewReader(w)), 10)
+			want, _ := x0.Apply(w)
+			assertCorrectMessage(t, got, want)
+			assertCorrectMessage(t, err.Error(), io.EOF.Error())
+		}
+		_, err := readAll(apply(strings.NewReader("1")), 10)
+		assertCorrectMessage(t, err.Error(), io.ErrUnexpectedEOF.Error())
+		_, err = readAll(apply(strings.NewReader("12")), 10)
+		assertCorrectMessage(t, err.Error(), "Letter 2 is not in the alphabet")
+	})
+
+	t.Run("Checking an endless stream.", func(t *testing.T) {
+		got, err := readAll(x0.Transducer()(&repeat{word: []rune("10")}), 8)
+		assertCorrectMessage(t, got, "01101010")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "read an endless stream.")
+		}
+		inv := x0.Inverse().Transducer()
+		got, _ = readAll(inv(x0.Transducer()(&repeat{word: []rune("110")})), 9)
+		assertCorrectMessage(t, got, "110110110")
+	})
+}
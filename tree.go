@@ -0,0 +1,174 @@
+package prefcode
+
+import "strings"
+
+// InternalNodes returns the carets of the code, that is every proper prefix of
+// a leaf, in dictionary order.  The root caret is the empty string "" and is
+// present unless the code is trivial.
+func (p *prefixCode) InternalNodes() []string {
+	nodes := make([]string, 0, len(p.code))
+	for node := range internalNodes(p) {
+		nodes = append(nodes, node)
+	}
+	p.sortWords(nodes)
+	return nodes
+}
+
+// NumCarets returns the number of carets (internal nodes) of the code.
+func (p *prefixCode) NumCarets() int {
+	if len(p.alphabet) > 1 {
+		return (len(p.code) - 1) / (len(p.alphabet) - 1)
+	}
+	return len(internalNodes(p))
+}
+
+// ParentOf returns word with its last letter (rune) removed.  Words of length
+// one have the root "" as parent, and the root is taken to be its own parent.
+func (p *prefixCode) ParentOf(word string) string {
+	if p.isEmptyWord(word) {
+		return ""
+	}
+	return trimLastChar(word)
+}
+
+// ChildrenOf returns the children of the caret at word, in alphabet order, or
+// nil if word is not a caret of the code (e.g., it is a leaf).
+func (p *prefixCode) ChildrenOf(word string) []string {
+	if !p.IsInternal(word) {
+		return nil
+	}
+	children := make([]string, len(p.alphabet))
+	for ii, r := range p.alphabet {
+		children[ii] = word + string(r)
+	}
+	return children
+}
+
+// Siblings returns the other children of the parent of leaf, in alphabet
+// order, or nil if leaf is not a leaf of the code or is the root.
+func (p *prefixCode) Siblings(leaf string) []string {
+	if _, ok := p.code[leaf]; !ok || p.emptyWord() == leaf {
+		return nil
+	}
+	var siblings []string
+	for _, child := range p.ChildrenOf(p.ParentOf(leaf)) {
+		if child != leaf {
+			siblings = append(siblings, child)
+		}
+	}
+	return siblings
+}
+
+// LongestCommonPrefix returns the longest word that is a prefix of every leaf.
+// For a complete code this is the root "" unless the code has a single leaf
+// (the trivial code, or a vine over a one letter alphabet).
+func (p *prefixCode) LongestCommonPrefix() string {
+	first := true
+	var lcp []rune
+	for leaf := range p.code {
+		if p.emptyWord() == leaf {
+			return ""
+		}
+		if first {
+			lcp = []rune(leaf)
+			first = false
+			continue
+		}
+		ii := 0
+		for _, r := range leaf {
+			if ii == len(lcp) || r != lcp[ii] {
+				break
+			}
+			ii++
+		}
+		lcp = lcp[:ii]
+	}
+	return string(lcp)
+}
+
+// SpineTo returns the carets on the path from the root "" down to leaf, root
+// first, or nil if leaf is not a leaf of the code.
+func (p *prefixCode) SpineTo(leaf string) []string {
+	if _, ok := p.code[leaf]; !ok {
+		return nil
+	}
+	spine := make([]string, 0, len(leaf))
+	if p.emptyWord() == leaf {
+		return spine
+	}
+	for ii := range leaf {
+		spine = append(spine, leaf[:ii])
+	}
+	return spine
+}
+
+// The predicates below classify an arbitrary word relative to the code.  A
+// word over the alphabet is exactly one of: a leaf, a caret (proper prefix of
+// a leaf), or below the code (a leaf is a proper prefix of it).  Words using
+// letters outside the alphabet are none of these.  The root may be written ""
+// or as the code names the empty word: EmptyString unless the alphabet policy
+// chose another name, see alphabetPolicy.go.
+
+// IsLeaf reports whether word is a leaf of the code.
+func (p *prefixCode) IsLeaf(word string) bool {
+	if "" == word {
+		word = p.emptyWord()
+	}
+	_, ok := p.code[word]
+	return ok
+}
+
+// IsInternal reports whether word is a caret of the code, i.e. a proper prefix
+// of some leaf.
+func (p *prefixCode) IsInternal(word string) bool {
+	word = p.path(word)
+	if !p.overAlphabet(word) {
+		return false
+	}
+	node := p.trie.find(word)
+	return nil != node && !node.leaf
+}
+
+// IsBelowCode reports whether some leaf is a proper prefix of word, so that
+// word lies strictly deeper than the code.
+func (p *prefixCode) IsBelowCode(word string) bool {
+	if p.isEmptyWord(word) || !p.overAlphabet(word) {
+		return false
+	}
+	leaf, ok := p.prefixLeaf(word)
+	return ok && leaf != word
+}
+
+// overAlphabet reports whether every letter of word is in the alphabet.
+func (p *prefixCode) overAlphabet(word string) bool {
+	for _, r := range word {
+		if !strings.ContainsRune(string(p.alphabet), r) {
+			return false
+		}
+	}
+	return true
+}
+
+// internalNodes returns the set of carets (proper prefixes of leaves) of pc.
+// The root is the empty string "", present unless pc is the trivial code.
+func internalNodes(pc PrefCode) map[string]bool {
+	empty := EmptyString
+	if p, ok := pc.(*prefixCode); ok {
+		empty = p.emptyWord()
+	}
+	nodes := make(map[string]bool)
+	for leaf := range pc.Code() {
+		if empty == leaf {
+			continue
+		}
+		// climb only until a caret already found, so each is hashed once
+		// rather than once per leaf below it.
+		for w := trimLastChar(leaf); !nodes[w]; w = trimLastChar(w) {
+			nodes[w] = true
+			if "" == w {
+				break
+			}
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,274 @@
+package prefcode
+
+import (
+	"errors"
+	"sort"
+)
+
+// TreePair is an element of the Higman–Thompson group V (alphabet size n): a
+// domain and a range code with the same number of leaves, the domain leaf
+// labelled ii going to the range leaf labelled ii.  On words it acts by
+// prefix replacement, see Apply.  The restriction to pairs whose labels are
+// both in dictionary order gives Thompson's F, and to cyclic shifts of it T.
+//
+// TreePair is a value: no method changes the codes of its receiver, and the
+// operations return fresh pairs.
+type TreePair struct {
+	domain *prefixCode
+	rng    *prefixCode
+}
+
+// NewTreePair returns the pair (domain, rng), after checking the two codes
+// have the same alphabet and size and that each is labelled by 0 ... n-1.
+// The codes are copied.
+func NewTreePair(domain, rng PrefCode) (TreePair, error) {
+	if string(MakeAlphabet(string(domain.Alphabet()))) != string(MakeAlphabet(string(rng.Alphabet()))) {
+		return TreePair{}, errors.New("Domain and range have different alphabets")
+	}
+	if domain.Size() != rng.Size() {
+		return TreePair{}, errors.New("Domain and range have different sizes")
+	}
+	for _, pc := range []PrefCode{domain, rng} {
+		seen := make([]bool, pc.Size())
+		for leaf, ii := range pc.Code() {
+			if ii < 0 || ii >= len(seen) || seen[ii] {
+				return TreePair{}, errors.New("Label of " + leaf + " is out of range or repeated")
+			}
+			seen[ii] = true
+		}
+	}
+	alpha := MakeAlphabet(string(domain.Alphabet()))
+	return TreePair{domain: pairCode(alpha, domain), rng: pairCode(alpha, rng)}, nil
+}
+
+// IdentityPair returns the identity of V over alpha, both codes trivial.  The
+// letters are sorted as for NewTreePair, so the letter order given is ignored.
+func IdentityPair(alpha []rune) (TreePair, error) {
+	pc, err := NewPrefCodeAlphaRunes(MakeAlphabet(string(alpha)))
+	if nil != err {
+		return TreePair{}, err
+	}
+	return TreePair{domain: pc, rng: pc.clone()}, nil
+}
+
+// pairCode copies pc over alpha, with dense labels.
+func pairCode(alpha []rune, pc PrefCode) *prefixCode {
+	c := &prefixCode{alphabet: alpha, code: make(map[string]int, pc.Size())}
+	for leaf, ii := range pc.Code() {
+		c.code[leaf] = ii
+	}
+	c.reindex()
+	return c
+}
+
+// Domain returns a copy of the domain code.
+func (t TreePair) Domain() PrefCode {
+	return t.domain.clone()
+}
+
+// Range returns a copy of the range code.
+func (t TreePair) Range() PrefCode {
+	return t.rng.clone()
+}
+
+func (t TreePair) Alphabet() []rune {
+	return t.domain.Alphabet()
+}
+
+// Size returns the number of leaves of either code.
+func (t TreePair) Size() int {
+	return t.domain.Size()
+}
+
+// Apply returns the image of the word w: the domain leaf which is a prefix of
+// w is replaced by its range leaf.  It reports false if w is too short to
+// have a domain leaf as prefix.  The empty word is "".
+func (t TreePair) Apply(w string) (string, bool) {
+	d := t.domain.GetPrefixOf(w)
+	if "" == d {
+		return "", false
+	}
+	tail := w
+	if EmptyString != d {
+		tail = w[len(d):]
+	}
+	r := t.rng.LeafAtLabel(t.domain.LabelAtLeaf(d))
+	if EmptyString == r {
+		r = ""
+	}
+	return r + tail, true
+}
+
+// Inverse returns the inverse pair, range and domain swapped.
+func (t TreePair) Inverse() TreePair {
+	return TreePair{domain: t.rng.clone(), rng: t.domain.clone()}
+}
+
+// Compose returns t followed by q, so that Apply of the result is q.Apply
+// after t.Apply, in reduced form.  The range of t and the domain of q are
+// first expanded to their join.
+func (t TreePair) Compose(q TreePair) (TreePair, error) {
+	if string(t.domain.alphabet) != string(q.domain.alphabet) {
+		return TreePair{}, errors.New("Tree pairs have different alphabets")
+	}
+	a, b := t.clone(), q.clone()
+	refineTogether(a, b, func(d string) { a.expandAt(d) }, func(d string) { b.expandAt(d) })
+	return composeRefined(a, b).Reduce(), nil
+}
+
+// refineTogether expands a and b until the range of a equals the domain of b,
+// both becoming their join, by calling expandA and expandB on the domain
+// leaves to expand.  Carets are visited shallowest first, so each is a leaf
+// or a caret by the time it is reached.
+func refineTogether(a, b TreePair, expandA, expandB func(d string)) {
+	nodes := internalNodes(a.rng)
+	for node := range internalNodes(b.domain) {
+		nodes[node] = true
+	}
+	carets := make([]string, 0, len(nodes))
+	for node := range nodes {
+		carets = append(carets, node)
+	}
+	sort.Slice(carets, func(i, j int) bool {
+		if li, lj := wordLen(carets[i]), wordLen(carets[j]); li != lj {
+			return li < lj
+		}
+		return carets[i] < carets[j]
+	})
+	for _, caret := range carets {
+		leaf := caret
+		if "" == leaf {
+			leaf = EmptyString
+		}
+		if a.rng.IsLeaf(leaf) {
+			expandA(a.domain.LeafAtLabel(a.rng.LabelAtLeaf(leaf)))
+		}
+		if b.domain.IsLeaf(leaf) {
+			expandB(leaf)
+		}
+	}
+}
+
+// composeRefined returns a followed by b, where the range of a is the domain
+// of b.  The result keeps the domain labels of a.
+func composeRefined(a, b TreePair) TreePair {
+	c := TreePair{domain: a.domain.clone(), rng: &prefixCode{alphabet: a.domain.Alphabet(), code: make(map[string]int, a.Size())}}
+	for ii := 0; ii < a.Size(); ii++ {
+		mid := a.rng.LeafAtLabel(ii)
+		c.rng.code[b.rng.LeafAtLabel(b.domain.LabelAtLeaf(mid))] = ii
+	}
+	c.rng.reindex()
+	return c
+}
+
+// Expand returns the pair with the domain leaf d expanded, and its image
+// with it, so the result is the same element.  It reports false, returning
+// t, if d is not a domain leaf.
+func (t TreePair) Expand(d string) (TreePair, bool) {
+	if !t.domain.IsLeaf(d) {
+		return t, false
+	}
+	c := t.clone()
+	c.expandAt(d)
+	return c, true
+}
+
+// expandAt expands the domain leaf d and its range leaf in place, returning
+// the label d had.  Both expansions label the new leaves from that label on
+// in the same order and shift the later labels alike, so the pairing of
+// labels still describes the same element.
+func (t TreePair) expandAt(d string) int {
+	label := t.domain.LabelAtLeaf(d)
+	t.rng.ExpandAt(t.rng.LeafAtLabel(label))
+	t.domain.ExpandAt(d)
+	return label
+}
+
+// Reduce returns the reduced form of t: while some exposed caret of the
+// domain has its children sent, in order, to the children of a caret of the
+// range, both carets are collapsed.  The domain is then labelled in
+// dictionary order, so equal elements have equal reduced forms.
+func (t TreePair) Reduce() TreePair {
+	c := t.clone()
+	c.reduceWhere(func([]int) bool { return true })
+	c.normalize()
+	return c
+}
+
+// reduceWhere collapses reducible carets in place while merge allows it.
+// merge is given the labels of the children, in alphabet order, and should
+// update anything riding on the labels if it returns true; the children
+// become one leaf labelled with the least of them, the other labels closing
+// ranks.
+func (t TreePair) reduceWhere(merge func(labels []int) bool) {
+	for reduced := true; reduced; {
+		reduced = false
+		for _, caret := range t.domain.ExposedCarets() {
+			rc, labels, ok := t.matchingCaret(caret)
+			if !ok || !merge(labels) {
+				continue
+			}
+			t.domain.ReduceAt(caret)
+			t.rng.ReduceAt(rc)
+			reduced = true
+			break
+		}
+	}
+}
+
+// matchingCaret returns the caret of the range whose children are the images
+// of the children of the exposed domain caret, in order, if there is one,
+// with the labels of those children.
+func (t TreePair) matchingCaret(caret string) (string, []int, bool) {
+	children := t.domain.ChildrenOf(caret)
+	labels := make([]int, len(children))
+	for ii, child := range children {
+		labels[ii] = t.domain.LabelAtLeaf(child)
+	}
+	first := t.rng.LeafAtLabel(labels[0])
+	if EmptyString == first {
+		return "", nil, false
+	}
+	rc := t.rng.ParentOf(first)
+	for ii, child := range t.rng.ChildrenOf(rc) {
+		if t.rng.LabelAtLeaf(child) != labels[ii] {
+			return "", nil, false
+		}
+	}
+	return rc, labels, true
+}
+
+// normalize relabels t in place so the domain labels are in dictionary order,
+// returning the permutation applied to the labels.
+func (t TreePair) normalize() Perm {
+	perm := make(Perm, t.Size())
+	for k, leaf := range t.domain.sortedKeys() {
+		perm[t.domain.LabelAtLeaf(leaf)] = k
+	}
+	t.domain.ApplyPerm(perm)
+	t.rng.ApplyPerm(perm)
+	return perm
+}
+
+// Equals reports whether t and q are the same element, comparing reduced
+// forms.
+func (t TreePair) Equals(q TreePair) bool {
+	return t.Reduce().String() == q.Reduce().String()
+}
+
+// IsIdentity reports whether t acts trivially.
+func (t TreePair) IsIdentity() bool {
+	return 1 == t.Reduce().Size()
+}
+
+// String prints the domain, then the range, as their codes.
+func (t TreePair) String() string {
+	if nil == t.domain {
+		return "<nil> -> <nil>"
+	}
+	return t.domain.String() + " -> " + t.rng.String()
+}
+
+func (t TreePair) clone() TreePair {
+	return TreePair{domain: t.domain.clone(), rng: t.rng.clone()}
+}
@@ -0,0 +1,116 @@
+package prefcode
+
+import (
+	"strconv"
+	"testing"
+)
+
+// pairFromMaps builds a binary tree pair from two labelled codes.
+func pairFromMaps(t *testing.T, domain, rng map[string]int) TreePair {
+	t.Helper()
+	d, _ := NewPrefCode()
+	d.SetCode(domain)
+	r, _ := NewPrefCode()
+	r.SetCode(rng)
+	pair, err := NewTreePair(d, r)
+	if nil != err {
+		t.Fatalf("NewTreePair() failed: %v", err)
+	}
+	return pair
+}
+
+func TestTreePair(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	x0 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "11": 2}, map[string]int{"00": 0, "01": 1, "1": 2})
+	x1 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "110": 2, "111": 3}, map[string]int{"0": 0, "100": 1, "101": 2, "11": 3})
+
+	t.Run("Checking NewTreePair validation.", func(t *testing.T) {
+		d, _ := NewPrefCode()
+		d.ExpandAt("1")
+		r, _ := NewPrefCode()
+		r.ExpandAt("0")
+		if _, err := NewTreePair(d, r); nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewTreePair() on matching codes.")
+		}
+		r.ExpandAt("00")
+		_, err := NewTreePair(d, r)
+		assertCorrectMessage(t, strconv.FormatBool(nil == err), "false")
+		r.ReduceAt("00")
+		r.SetCode(map[string]int{"00": 0, "01": 0, "1": 2})
+		_, err = NewTreePair(d, r)
+		assertCorrectMessage(t, strconv.FormatBool(nil == err), "false")
+		a, _ := NewPrefCodeAlphaString("ab")
+		a.ExpandAt("")
+		b, _ := NewPrefCode()
+		b.ExpandAt("")
+		_, err = NewTreePair(a, b)
+		assertCorrectMessage(t, strconv.FormatBool(nil == err), "false")
+	})
+
+	t.Run("Checking Apply.", func(t *testing.T) {
+		w, ok := x0.Apply("0101")
+		assertCorrectMessage(t, w+" "+strconv.FormatBool(ok), "00101 true")
+		w, _ = x0.Apply("10")
+		assertCorrectMessage(t, w, "01")
+		w, _ = x0.Apply("111")
+		assertCorrectMessage(t, w, "11")
+		_, ok = x0.Apply("1")
+		assertCorrectMessage(t, strconv.FormatBool(ok), "false")
+		id, _ := IdentityPair([]rune("01"))
+		w, _ = id.Apply("0110")
+		assertCorrectMessage(t, w, "0110")
+		id, _ = IdentityPair([]rune("10"))
+		assertCorrectMessage(t, string(id.Alphabet()), "01")
+		back, err := id.Compose(x0)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "Compose() the identity over \"10\" with x0.")
+		}
+		assertCorrectMessage(t, strconv.FormatBool(back.Equals(x0)), "true")
+	})
+
+	t.Run("Checking Compose, Inverse and Reduce.", func(t *testing.T) {
+		sq, err := x0.Compose(x0)
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "Compose() x0 with itself.")
+		}
+		assertCorrectMessage(t, sq.String(), "[0 0], [10 1], [110 2], [111 3] -> [000 0], [001 1], [01 2], [1 3]")
+		for _, w := range []string{"0", "10101", "110", "1111"} {
+			once, _ := x0.Apply(w)
+			twice, _ := x0.Apply(once)
+			got, _ := sq.Apply(w)
+			assertCorrectMessage(t, got, twice)
+		}
+		id, _ := x0.Compose(x0.Inverse())
+		assertCorrectMessage(t, strconv.FormatBool(id.IsIdentity()), "true")
+		assertCorrectMessage(t, id.String(), "[𝛆 0] -> [𝛆 0]")
+		assertCorrectMessage(t, strconv.FormatBool(x0.IsIdentity()), "false")
+
+		// x2 is x1 conjugated by x0: x0, then x1, then x0 inverse.
+		lhs, _ := x0.Compose(x1)
+		lhs, _ = lhs.Compose(x0.Inverse())
+		x2 := pairFromMaps(t, map[string]int{"0": 0, "10": 1, "110": 2, "1110": 3, "1111": 4}, map[string]int{"0": 0, "10": 1, "1100": 2, "1101": 3, "111": 4})
+		assertCorrectMessage(t, strconv.FormatBool(lhs.Equals(x2)), "true")
+		assertCorrectMessage(t, strconv.FormatBool(x1.Equals(x2)), "false")
+	})
+
+	t.Run("Checking Expand and Reduce keep the element.", func(t *testing.T) {
+		big, ok := x0.Expand("10")
+		assertCorrectMessage(t, strconv.FormatBool(ok), "true")
+		assertCorrectMessage(t, big.String(), "[0 0], [100 1], [101 2], [11 3] -> [00 0], [010 1], [011 2], [1 3]")
+		assertCorrectMessage(t, x0.String(), "[0 0], [10 1], [11 2] -> [00 0], [01 1], [1 2]")
+		assertCorrectMessage(t, big.Reduce().String(), x0.String())
+		_, ok = x0.Expand("1")
+		assertCorrectMessage(t, strconv.FormatBool(ok), "false")
+
+		// a transposition in V reduces with its domain in dictionary order.
+		swap := pairFromMaps(t, map[string]int{"00": 2, "01": 0, "1": 1}, map[string]int{"10": 2, "11": 0, "0": 1})
+		assertCorrectMessage(t, swap.Reduce().String(), "[0 0], [1 1] -> [0 1], [1 0]")
+	})
+}
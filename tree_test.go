@@ -0,0 +1,99 @@
+package prefcode
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking InternalNodes and NumCarets.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking InternalNodes.")
+		}
+		assertCorrectMessage(t, strings.Join(baseCode.InternalNodes(), ","), "")
+		assertCorrectMessage(t, strconv.Itoa(baseCode.NumCarets()), "0")
+
+		baseCode.ExpandAt("1001")
+		assertCorrectMessage(t, strings.Join(baseCode.InternalNodes(), ","), ",1,10,100,1001")
+		assertCorrectMessage(t, strconv.Itoa(baseCode.NumCarets()), "5")
+	})
+
+	t.Run("Checking NumCarets over unary alphabet.", func(t *testing.T) {
+		unary, err := NewPrefCodeAlphaString("a")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString in test checking unary NumCarets.")
+		}
+		unary.ExpandAt("aa")
+		assertCorrectMessage(t, unary.String(), "[aaa 0]")
+		assertCorrectMessage(t, strconv.Itoa(unary.NumCarets()), "3")
+	})
+	t.Run("Checking ParentOf, ChildrenOf and Siblings.", func(t *testing.T) {
+		baseCode, err := NewPrefCodeAlphaString("日本語")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString in test checking navigation.")
+		}
+		assertCorrectMessage(t, strings.Join(baseCode.ChildrenOf(""), ","), "")
+		baseCode.ExpandAt("")
+		baseCode.ExpandAt("本")
+
+		assertCorrectMessage(t, baseCode.ParentOf("本語"), "本")
+		assertCorrectMessage(t, baseCode.ParentOf("本"), "")
+		assertCorrectMessage(t, strings.Join(baseCode.ChildrenOf(""), ","), "日,本,語")
+		assertCorrectMessage(t, strings.Join(baseCode.ChildrenOf("本"), ","), "本日,本本,本語")
+		assertCorrectMessage(t, strings.Join(baseCode.ChildrenOf("日"), ","), "")
+		assertCorrectMessage(t, strings.Join(baseCode.Siblings("本本"), ","), "本日,本語")
+		assertCorrectMessage(t, strings.Join(baseCode.Siblings("語"), ","), "日,本")
+		assertCorrectMessage(t, strings.Join(baseCode.Siblings("本"), ","), "")
+	})
+	t.Run("Checking IsLeaf, IsInternal and IsBelowCode.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking word classification.")
+		}
+		classify := func(w string) string {
+			return strconv.FormatBool(baseCode.IsLeaf(w)) + " " +
+				strconv.FormatBool(baseCode.IsInternal(w)) + " " +
+				strconv.FormatBool(baseCode.IsBelowCode(w))
+		}
+		assertCorrectMessage(t, classify(""), "true false false")
+		assertCorrectMessage(t, classify("01"), "false false true")
+
+		baseCode.ExpandAt("10")
+		assertCorrectMessage(t, classify(""), "false true false")
+		assertCorrectMessage(t, classify("1"), "false true false")
+		assertCorrectMessage(t, classify("101"), "true false false")
+		assertCorrectMessage(t, classify("0110"), "false false true")
+		assertCorrectMessage(t, classify("1x"), "false false false")
+	})
+	t.Run("Checking LongestCommonPrefix and SpineTo.", func(t *testing.T) {
+		baseCode, err := NewPrefCodeAlphaString("日本")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString in test checking SpineTo.")
+		}
+		baseCode.ExpandAt("")
+		baseCode.ExpandAt("本")
+		assertCorrectMessage(t, baseCode.LongestCommonPrefix(), "")
+		assertCorrectMessage(t, strings.Join(baseCode.SpineTo("本日"), ","), ",本")
+		assertCorrectMessage(t, strings.Join(baseCode.SpineTo("日"), ","), "")
+		if nil != baseCode.SpineTo("本") {
+			assertCorrectMessage(t, "non nil SpineTo for ", "a caret.")
+		}
+
+		unary, err := NewPrefCodeAlphaString("a")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString in test checking LongestCommonPrefix.")
+		}
+		unary.ExpandAt("aa")
+		assertCorrectMessage(t, unary.LongestCommonPrefix(), "aaa")
+	})
+}
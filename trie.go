@@ -0,0 +1,153 @@
+package prefcode
+
+// trieNode is a node of the trie (the tree itself) mirroring the leaves of a
+// prefixCode.  It lets prefix searches and subtree collection walk a single
+// path, in time proportional to the word length, rather than scan every key
+// of the code map.  The map stays the home of the labels.
+//
+// The trie knows words only as paths from its root, which is "": the name of
+// the empty word, EmptyString unless the code says otherwise (see
+// alphabetPolicy.go), is left to the prefixCode methods at the end of this
+// file.  So the letter 𝛆 of an alphabet allowing it is a path like any other.
+type trieNode struct {
+	children map[rune]*trieNode
+	leaf     bool
+}
+
+// insert marks the path word as a leaf, creating the path to it as needed.
+func (t *trieNode) insert(word string) {
+	node := t
+	for _, r := range word {
+		child, ok := node.children[r]
+		if !ok {
+			if nil == node.children {
+				node.children = make(map[rune]*trieNode)
+			}
+			child = &trieNode{}
+			node.children[r] = child
+		}
+		node = child
+	}
+	node.leaf = true
+}
+
+// find returns the node at the path word, or nil if word is not on the trie.
+func (t *trieNode) find(word string) *trieNode {
+	node := t
+	for _, r := range word {
+		node = node.children[r]
+		if nil == node {
+			return nil
+		}
+	}
+	return node
+}
+
+// prefixLeaf returns the path of the leaf on the path s, that is the leaf
+// which is a prefix of s, if any.  The root leaf is reported as "".
+func (t *trieNode) prefixLeaf(s string) (string, bool) {
+	if t.leaf {
+		return "", true
+	}
+	node := t
+	for ii, r := range s {
+		node = node.children[r]
+		if nil == node {
+			return "", false
+		}
+		if node.leaf {
+			return s[:ii+len(string(r))], true
+		}
+	}
+	return "", false
+}
+
+// collect appends to leaves the paths of all the leaves at or below t, where
+// t sits at the path word.  It keeps its own stack rather than recursing, as
+// codes may be very deep.
+func (t *trieNode) collect(word string, leaves []string) []string {
+	stack := []trieEntry{{t, word}}
+	for 0 < len(stack) {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if e.t.leaf {
+			leaves = append(leaves, e.word)
+			continue
+		}
+		for r, child := range e.t.children {
+			stack = append(stack, trieEntry{child, e.word + string(r)})
+		}
+	}
+	return leaves
+}
+
+// trieEntry is a trie node with its word, for walks keeping their own stack
+// or queue.
+type trieEntry struct {
+	t    *trieNode
+	word string
+}
+
+// path returns word as a path down the trie of p: "" for the empty word.
+func (p *prefixCode) path(word string) string {
+	if p.isEmptyWord(word) {
+		return ""
+	}
+	return word
+}
+
+// wordAt returns the word at the path, naming the empty word as p does.
+func (p *prefixCode) wordAt(path string) string {
+	if "" == path {
+		return p.emptyWord()
+	}
+	return path
+}
+
+// newTrie rebuilds the trie of p from the keys of the code map.
+func (p *prefixCode) newTrie() {
+	p.trie = &trieNode{}
+	for leaf := range p.code {
+		p.trie.insert(p.path(leaf))
+	}
+}
+
+// findNode returns the trie node at word, or nil if word is not on the trie.
+func (p *prefixCode) findNode(word string) *trieNode {
+	return p.trie.find(p.path(word))
+}
+
+// prefixLeaf returns the leaf of p which is a prefix of s, if any.
+func (p *prefixCode) prefixLeaf(s string) (string, bool) {
+	leaf, ok := p.trie.prefixLeaf(p.path(s))
+	if !ok {
+		return "", false
+	}
+	return p.wordAt(leaf), true
+}
+
+// leavesBelow returns the leaves at or below word, whose trie node is node.
+func (p *prefixCode) leavesBelow(node *trieNode, word string) []string {
+	leaves := node.collect(p.path(word), nil)
+	if 1 == len(leaves) {
+		leaves[0] = p.wordAt(leaves[0])
+	}
+	return leaves
+}
+
+// setLeaf puts word in the code with label, keeping the trie in step.
+func (p *prefixCode) setLeaf(word string, label int) {
+	p.treeChanged()
+	p.code[word] = label
+	p.trie.insert(p.path(word))
+}
+
+// deleteLeaf removes the leaf word from the code and the trie.  Its trie node
+// stays in place, ready to become a caret.
+func (p *prefixCode) deleteLeaf(word string) {
+	p.treeChanged()
+	delete(p.code, word)
+	if node := p.findNode(word); nil != node {
+		node.leaf = false
+	}
+}
@@ -0,0 +1,58 @@
+package prefcode
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestTrie(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	// the trie must follow the code map through expansions and reductions.
+	t.Run("Checking trie follows ExpandAt and ReduceAt.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking trie.")
+		}
+		trieLeaves := func() string {
+			leaves := baseCode.leavesBelow(baseCode.trie, "")
+			sort.Strings(leaves)
+			return strings.Join(leaves, ",")
+		}
+		assertCorrectMessage(t, trieLeaves(), EmptyString)
+		assertCorrectMessage(t, baseCode.GetPrefixOf("0101"), EmptyString)
+
+		baseCode.ExpandAt("1001")
+		assertCorrectMessage(t, trieLeaves(), "0,1000,10010,10011,101,11")
+		assertCorrectMessage(t, baseCode.GetPrefixOf("100111"), "10011")
+		assertCorrectMessage(t, baseCode.GetPrefixOf("100"), "")
+
+		baseCode.ReduceAt("100")
+		assertCorrectMessage(t, trieLeaves(), "0,100,101,11")
+		assertCorrectMessage(t, baseCode.GetPrefixOf("100111"), "100")
+
+		baseCode.ReduceAt("")
+		assertCorrectMessage(t, trieLeaves(), EmptyString)
+	})
+
+	t.Run("Checking trie follows SetCode and clone.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking trie SetCode.")
+		}
+		baseCode.SetCode(map[string]int{"0": 1, "10": 0, "11": 2})
+		assertCorrectMessage(t, baseCode.GetPrefixOf("110"), "11")
+
+		c := baseCode.clone()
+		c.ExpandAt("0")
+		assertCorrectMessage(t, c.GetPrefixOf("01"), "01")
+		assertCorrectMessage(t, baseCode.GetPrefixOf("01"), "0")
+	})
+}
@@ -0,0 +1,69 @@
+package prefcode
+
+import "iter"
+
+// CodeView is a read-only view of the leaves of a code and their labels.  A
+// view of a prefixCode reads the code itself, without the copy Code makes, so
+// it sees later changes; it is not safe to use while the code is changed.
+type CodeView interface {
+	Len() int
+	Label(leaf string) (int, bool)
+	Leaves() iter.Seq2[string, int]
+}
+
+// View returns a read-only view of p.
+func (p *prefixCode) View() CodeView {
+	return codeView{p}
+}
+
+// View returns a view of a copy of the code of c, which has no map to share.
+func (c *CompactPrefCode) View() CodeView {
+	return mapView(c.Code())
+}
+
+type codeView struct {
+	p *prefixCode
+}
+
+func (v codeView) Len() int {
+	return len(v.p.code)
+}
+
+func (v codeView) Label(leaf string) (int, bool) {
+	v.p.syncLabels()
+	ii, ok := v.p.code[leaf]
+	return ii, ok
+}
+
+// Leaves yields the leaves with their labels, in no particular order.
+func (v codeView) Leaves() iter.Seq2[string, int] {
+	return func(yield func(string, int) bool) {
+		v.p.syncLabels()
+		for leaf, ii := range v.p.code {
+			if !yield(leaf, ii) {
+				return
+			}
+		}
+	}
+}
+
+type mapView map[string]int
+
+func (m mapView) Len() int {
+	return len(m)
+}
+
+func (m mapView) Label(leaf string) (int, bool) {
+	ii, ok := m[leaf]
+	return ii, ok
+}
+
+func (m mapView) Leaves() iter.Seq2[string, int] {
+	return func(yield func(string, int) bool) {
+		for leaf, ii := range m {
+			if !yield(leaf, ii) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package prefcode
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestView(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking Code is a copy.", func(t *testing.T) {
+		pc, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking Code copies.")
+		}
+		pc.ExpandAt("1")
+		code := pc.Code()
+		code["1"] = 7
+		delete(code, "0")
+		assertCorrectMessage(t, pc.String(), "[0 0], [10 1], [11 2]")
+	})
+
+	t.Run("Checking View.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		for _, code := range []PrefCode{pc, compact} {
+			code.ExpandAt("1")
+			code.SwapPermAtKeys("0", "11")
+			view := code.View()
+			assertCorrectMessage(t, strconv.Itoa(view.Len()), "3")
+			ii, ok := view.Label("11")
+			assertCorrectMessage(t, strconv.Itoa(ii)+" "+strconv.FormatBool(ok), "0 true")
+			_, ok = view.Label("1")
+			assertCorrectMessage(t, strconv.FormatBool(ok), "false")
+
+			var entries []string
+			for leaf, ii := range view.Leaves() {
+				entries = append(entries, leaf+":"+strconv.Itoa(ii))
+			}
+			sort.Strings(entries)
+			assertCorrectMessage(t, strings.Join(entries, " "), "0:2 10:1 11:0")
+		}
+
+		view := pc.View()
+		pc.ExpandAt("0")
+		assertCorrectMessage(t, strconv.Itoa(view.Len()), "4")
+	})
+}
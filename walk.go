@@ -0,0 +1,156 @@
+package prefcode
+
+import "errors"
+
+// ErrStopWalk may be returned by the visitor of WalkDFS or WalkBFS to stop the
+// walk early, the walk itself then returning nil.
+var ErrStopWalk = errors.New("walk stopped")
+
+// ErrSkipSubtree may be returned by the visitor of WalkDFS or WalkBFS at a
+// caret to skip the nodes below it.  At a leaf it is the same as nil.
+var ErrSkipSubtree = errors.New("subtree skipped")
+
+// WalkDFS visits the nodes of the tree of p depth first, each caret before
+// the nodes below it and children in letter order, so the leaves come in
+// dictionary order.  Carets are passed with isLeaf false and label FAILURE,
+// the root caret as ""; the leaf of the trivial code is EmptyString.  The
+// walk stops at the first error fn returns, which WalkDFS returns unless it
+// is ErrStopWalk.  fn must not change p.
+func (p *prefixCode) WalkDFS(fn func(node string, isLeaf bool, label int) error) error {
+	p.syncLabels()
+	letters := p.letters()
+	stack := []trieEntry{{p.trie, ""}}
+	for 0 < len(stack) {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if e.t.leaf {
+			if "" == e.word {
+				e.word = EmptyString
+			}
+			if err := skipped(fn(e.word, true, p.code[e.word])); nil != err {
+				return walkDone(err)
+			}
+			continue
+		}
+		if err := fn(e.word, false, FAILURE); ErrSkipSubtree == err {
+			continue
+		} else if nil != err {
+			return walkDone(err)
+		}
+		// push the children last first, so they come off in letter order.
+		for ii := len(letters) - 1; ii >= 0; ii-- {
+			stack = append(stack, trieEntry{e.t.children[letters[ii]], e.word + string(letters[ii])})
+		}
+	}
+	return nil
+}
+
+// WalkBFS visits the nodes of the tree of p breadth first, level by level and
+// each level in dictionary order, passing nodes as WalkDFS does.
+func (p *prefixCode) WalkBFS(fn func(node string, isLeaf bool, label int) error) error {
+	p.syncLabels()
+	letters := p.letters()
+	queue := []trieEntry{{p.trie, ""}}
+	for 0 < len(queue) {
+		e := queue[0]
+		queue = queue[1:]
+		if e.t.leaf {
+			if "" == e.word {
+				e.word = EmptyString
+			}
+			if err := skipped(fn(e.word, true, p.code[e.word])); nil != err {
+				return walkDone(err)
+			}
+			continue
+		}
+		if err := fn(e.word, false, FAILURE); ErrSkipSubtree == err {
+			continue
+		} else if nil != err {
+			return walkDone(err)
+		}
+		for _, r := range letters {
+			queue = append(queue, trieEntry{e.t.children[r], e.word + string(r)})
+		}
+	}
+	return nil
+}
+
+// WalkDFS walks the DFS sequence of c itself, as WalkDFS of PrefCode.  It
+// steps along the sequence as walk does, jumping over skipped subtrees.
+func (c *CompactPrefCode) WalkDFS(fn func(node string, isLeaf bool, label int) error) error {
+	var word []rune
+	var next []int // next[d] is the index of the next child at depth d+1
+	for pos := 0; pos < c.dfs.n; {
+		var err error
+		end := pos + 1
+		if !c.dfs.get(pos) {
+			err = skipped(fn(wordString(word), true, int(c.perm[c.dfs.rank0(pos)])))
+		} else if err = fn(string(word), false, FAILURE); ErrSkipSubtree == err {
+			err, end = nil, c.subtreeEnd(pos)
+		} else if nil == err {
+			word = append(word, c.letters[0])
+			next = append(next, 1)
+			pos++
+			continue
+		}
+		if nil != err {
+			return walkDone(err)
+		}
+		pos = end
+		// climb until some caret still has children to visit.
+		for 0 < len(next) && next[len(next)-1] == len(c.letters) {
+			word = word[:len(word)-1]
+			next = next[:len(next)-1]
+		}
+		if 0 < len(next) {
+			word[len(word)-1] = c.letters[next[len(next)-1]]
+			next[len(next)-1]++
+		}
+	}
+	return nil
+}
+
+func (c *CompactPrefCode) WalkBFS(fn func(node string, isLeaf bool, label int) error) error {
+	return c.materialize().WalkBFS(fn)
+}
+
+// WalkPruned calls visit on each leaf of p in dictionary order, except that
+// a node, caret or leaf, for which prune returns true is skipped with all
+// below it, so a search need only touch the parts of a huge code it cares
+// about.  Nodes are named as by WalkDFS.
+func (p *prefixCode) WalkPruned(prune func(prefix string) bool, visit func(leaf string, label int)) {
+	walkPruned(p.WalkDFS, prune, visit)
+}
+
+func (c *CompactPrefCode) WalkPruned(prune func(prefix string) bool, visit func(leaf string, label int)) {
+	walkPruned(c.WalkDFS, prune, visit)
+}
+
+func walkPruned(walk func(fn func(node string, isLeaf bool, label int) error) error, prune func(prefix string) bool, visit func(leaf string, label int)) {
+	walk(func(node string, isLeaf bool, label int) error {
+		if prune(node) {
+			return ErrSkipSubtree
+		}
+		if isLeaf {
+			visit(node, label)
+		}
+		return nil
+	})
+}
+
+// skipped reads ErrSkipSubtree at a leaf, where there is nothing to skip, as
+// nil.
+func skipped(err error) error {
+	if ErrSkipSubtree == err {
+		return nil
+	}
+	return err
+}
+
+// walkDone turns the error ending a walk into what the walk returns.
+func walkDone(err error) error {
+	if errors.Is(err, ErrStopWalk) {
+		return nil
+	}
+	return err
+}
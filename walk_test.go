@@ -0,0 +1,87 @@
+package prefcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	// visits records the nodes visited, stopping with err at the node stop.
+	visits := func(walk func(func(string, bool, int) error) error, stop string, err error) (string, error) {
+		var seen []string
+		werr := walk(func(node string, isLeaf bool, label int) error {
+			seen = append(seen, fmt.Sprintf("%q %t %d", node, isLeaf, label))
+			if node == stop {
+				return err
+			}
+			return nil
+		})
+		return strings.Join(seen, ", "), werr
+	}
+
+	t.Run("Checking WalkDFS and WalkBFS.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		got, _ := visits(pc.WalkDFS, "", nil)
+		assertCorrectMessage(t, got, `"𝛆" true 0`)
+
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		wrapped, _ := NewPrefCode()
+		for _, code := range []PrefCode{pc, compact, NewSafePrefCode(wrapped)} {
+			code.ExpandAt("0")
+			got, _ = visits(code.WalkDFS, "", nil)
+			assertCorrectMessage(t, got, `"" false -1, "0" false -1, "00" true 0, "01" true 1, "1" true 2`)
+			got, _ = visits(code.WalkBFS, "", nil)
+			assertCorrectMessage(t, got, `"" false -1, "0" false -1, "1" true 2, "00" true 0, "01" true 1`)
+		}
+
+		ordered, _ := NewPrefCodeOrdered([]rune("10"))
+		ordered.ExpandAt("0")
+		got, _ = visits(ordered.WalkDFS, "", nil)
+		assertCorrectMessage(t, got, `"" false -1, "1" true 0, "0" false -1, "01" true 1, "00" true 2`)
+	})
+
+	t.Run("Checking walks stop early and pass errors on.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		for _, code := range []PrefCode{pc, compact} {
+			code.ExpandAt("0")
+			got, err := visits(code.WalkDFS, "00", ErrStopWalk)
+			assertCorrectMessage(t, got, `"" false -1, "0" false -1, "00" true 0`)
+			assertCorrectMessage(t, fmt.Sprint(err), "<nil>")
+			got, err = visits(code.WalkBFS, "1", errors.New("Visit failed"))
+			assertCorrectMessage(t, got, `"" false -1, "0" false -1, "1" true 2`)
+			assertCorrectMessage(t, fmt.Sprint(err), "Visit failed")
+		}
+	})
+
+	t.Run("Checking WalkPruned skips pruned subtrees.", func(t *testing.T) {
+		pc, _ := NewPrefCode()
+		compact, _ := NewCompactPrefCode([]rune("01"))
+		for _, code := range []PrefCode{pc, compact} {
+			code.ExpandAt("00")
+			code.ExpandAt("11")
+			var asked, seen []string
+			code.WalkPruned(func(prefix string) bool {
+				asked = append(asked, fmt.Sprintf("%q", prefix))
+				return "0" == prefix || "111" == prefix
+			}, func(leaf string, label int) {
+				seen = append(seen, fmt.Sprint(leaf, " ", label))
+			})
+			assertCorrectMessage(t, strings.Join(asked, ", "), `"", "0", "1", "10", "11", "110", "111"`)
+			assertCorrectMessage(t, strings.Join(seen, ", "), "10 3, 110 4")
+
+			got, _ := visits(code.WalkBFS, "1", ErrSkipSubtree)
+			assertCorrectMessage(t, got, `"" false -1, "0" false -1, "1" false -1, "00" false -1, "01" true 2, "000" true 0, "001" true 1`)
+		}
+	})
+}
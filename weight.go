@@ -0,0 +1,129 @@
+package prefcode
+
+import (
+	"math/big"
+)
+
+// Leaves may carry weights, exact rationals.  Until a weight is set every
+// leaf w carries the uniform n-adic measure n^-|w| (so the weights of a
+// complete code add up to one) and nothing is stored.  ReduceAt gives the
+// new leaf the sum of the weights it replaces; ExpandAt splits the weight of
+// the expanded leaf per a policy, by default in the n-adic way.
+
+// WeightSplit is a policy giving the weights of the leaves replacing old, in
+// dictionary order, from the weight w of old.
+type WeightSplit func(w *big.Rat, old string, leaves []string) []*big.Rat
+
+// SplitEvenly is a WeightSplit sharing the weight equally among the new leaves.
+func SplitEvenly(w *big.Rat, old string, leaves []string) []*big.Rat {
+	share := new(big.Rat).Quo(w, big.NewRat(int64(len(leaves)), 1))
+	weights := make([]*big.Rat, len(leaves))
+	for ii := range weights {
+		weights[ii] = share
+	}
+	return weights
+}
+
+// SetLeafWeight gives leaf the weight w, reporting whether leaf is a leaf.
+func (p *prefixCode) SetLeafWeight(leaf string, w *big.Rat) bool {
+	if _, ok := p.code[leaf]; !ok {
+		return false
+	}
+	if nil == p.weights {
+		p.weights = make(map[string]*big.Rat, len(p.code))
+		for k := range p.code {
+			p.weights[k] = p.uniformWeight(k)
+		}
+	}
+	p.weights[leaf] = new(big.Rat).Set(w)
+	return true
+}
+
+// LeafWeight returns the weight of leaf, or nil if leaf is not a leaf.
+func (p *prefixCode) LeafWeight(leaf string) *big.Rat {
+	if _, ok := p.code[leaf]; !ok {
+		return nil
+	}
+	if nil == p.weights {
+		return p.uniformWeight(leaf)
+	}
+	return new(big.Rat).Set(p.weights[leaf])
+}
+
+// TotalWeight returns the sum of the weights of the leaves.
+func (p *prefixCode) TotalWeight() *big.Rat {
+	total := new(big.Rat)
+	if nil == p.weights {
+		return total.SetInt64(1)
+	}
+	for _, w := range p.weights {
+		total.Add(total, w)
+	}
+	return total
+}
+
+// SetWeightSplit sets how ExpandAt splits weights; nil restores the n-adic
+// split.
+func (p *prefixCode) SetWeightSplit(split WeightSplit) {
+	p.weightSplit = split
+}
+
+// uniformWeight returns n^-|word|.
+func (p *prefixCode) uniformWeight(word string) *big.Rat {
+	den := new(big.Int).Exp(big.NewInt(int64(len(p.alphabet))), big.NewInt(int64(wordLen(word))), nil)
+	return new(big.Rat).SetFrac(big.NewInt(1), den)
+}
+
+// weightExpanded splits the weight of old over the leaves replacing it.
+func (p *prefixCode) weightExpanded(old string, leaves []string) {
+	if nil == p.weights {
+		return
+	}
+	w := p.weights[old]
+	delete(p.weights, old)
+	var weights []*big.Rat
+	if nil != p.weightSplit {
+		weights = p.weightSplit(new(big.Rat).Set(w), old, leaves)
+	}
+	if len(weights) != len(leaves) {
+		// n-adic: each leaf gets the share of w its depth below old gives.
+		base := p.uniformWeight(old)
+		weights = make([]*big.Rat, len(leaves))
+		for ii, leaf := range leaves {
+			weights[ii] = new(big.Rat).Quo(p.uniformWeight(leaf), base)
+			weights[ii].Mul(weights[ii], w)
+		}
+	}
+	for ii, leaf := range leaves {
+		p.weights[leaf] = weights[ii]
+	}
+}
+
+// weightReduced gives word the sum of the weights of the leaves below.
+func (p *prefixCode) weightReduced(word string, below []string) {
+	if nil == p.weights {
+		return
+	}
+	sum := new(big.Rat)
+	for _, leaf := range below {
+		sum.Add(sum, p.weights[leaf])
+		delete(p.weights, leaf)
+	}
+	p.weights[word] = sum
+}
+
+// leafExpanded carries the tags and weights of old over to the leaves
+// replacing it, and calls the expansion hooks.  It comes last in each
+// expansion.
+func (p *prefixCode) leafExpanded(old string, leaves []string) {
+	p.metaExpanded(old, leaves)
+	p.weightExpanded(old, leaves)
+	p.expanded(old, leaves)
+}
+
+// leavesReduced carries the tags and weights of the leaves below over to the
+// leaf word replacing them.
+func (p *prefixCode) leavesReduced(word string, below []string) {
+	p.metaReduced(word, below)
+	p.weightReduced(word, below)
+}
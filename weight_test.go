@@ -0,0 +1,81 @@
+package prefcode
+
+import (
+	"math/big"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestWeight(t *testing.T) {
+
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	t.Run("Checking the uniform measure.", func(t *testing.T) {
+		baseCode, err := NewPrefCodeAlphaString("abc")
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCodeAlphaString() in test checking uniform weights.")
+		}
+		assertCorrectMessage(t, baseCode.LeafWeight(EmptyString).RatString(), "1")
+		baseCode.ExpandAt("ab")
+		assertCorrectMessage(t, baseCode.LeafWeight("c").RatString(), "1/3")
+		assertCorrectMessage(t, baseCode.LeafWeight("aba").RatString(), "1/27")
+		assertCorrectMessage(t, baseCode.TotalWeight().RatString(), "1")
+		assertCorrectMessage(t, strconv.FormatBool(nil == baseCode.LeafWeight("a")), "true")
+	})
+
+	t.Run("Checking weights through expansion and reduction.", func(t *testing.T) {
+		baseCode, err := NewPrefCode()
+		if nil != err {
+			assertCorrectMessage(t, "Faied to ", "NewPrefCode() in test checking weights.")
+		}
+		baseCode.ExpandAt(EmptyString)
+		baseCode.SetLeafWeight("1", big.NewRat(3, 4))
+		baseCode.SetLeafWeight("0", big.NewRat(1, 2))
+		assertCorrectMessage(t, baseCode.TotalWeight().RatString(), "5/4")
+
+		baseCode.ExpandAt("10")
+		assertCorrectMessage(t, baseCode.LeafWeight("11").RatString(), "3/8")
+		assertCorrectMessage(t, baseCode.LeafWeight("101").RatString(), "3/16")
+
+		baseCode.SetWeightSplit(SplitEvenly)
+		baseCode.ExpandAt("0")
+		assertCorrectMessage(t, baseCode.LeafWeight("01").RatString(), "1/4")
+		baseCode.ExpandAt("11")
+		assertCorrectMessage(t, baseCode.LeafWeight("110").RatString(), "3/16")
+
+		baseCode.ReduceAt("1")
+		assertCorrectMessage(t, baseCode.LeafWeight("1").RatString(), "3/4")
+		assertCorrectMessage(t, baseCode.TotalWeight().RatString(), "5/4")
+		baseCode.ReduceAt(EmptyString)
+		assertCorrectMessage(t, baseCode.LeafWeight(EmptyString).RatString(), "5/4")
+	})
+
+	// any mix of operations keeps the total, with either split.
+	t.Run("Checking the total weight is kept.", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(23))
+		for _, split := range []WeightSplit{nil, SplitEvenly} {
+			baseCode, _ := NewPrefCodeAlphaString("abc")
+			baseCode.SetWeightSplit(split)
+			baseCode.SetLeafWeight(EmptyString, big.NewRat(7, 5))
+			for step := 0; step < 200; step++ {
+				leaves := baseCode.sortedKeys()
+				leaf := leaves[rng.Intn(len(leaves))]
+				if EmptyString == leaf {
+					leaf = ""
+				}
+				if 0 < rng.Intn(3) {
+					baseCode.ExpandAt(leaf + "abc"[rng.Intn(3):][:1])
+				} else {
+					baseCode.ReduceAt(trimLastChar(leaf))
+				}
+				assertCorrectMessage(t, baseCode.TotalWeight().RatString(), "7/5")
+			}
+		}
+	})
+}